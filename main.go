@@ -2,17 +2,24 @@ package main
 
 import (
 	"GiftWize/src/app/module"
+	"GiftWize/src/infreaestructure/repository"
 	"GiftWize/src/shared"
+	"log"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func main() {
-	app := fiber.New()
+	// Streamed so that large CSV imports are not held in memory
+	app := fiber.New(fiber.Config{StreamRequestBody: true})
 	envs := shared.GetEnvs()
-	shared.Init()
+	db := shared.Init()
+	if err := repository.MigrateSearch(db); err != nil {
+		log.Fatalf("failed to migrate search indexes: %v", err)
+	}
 
 	module.CampaignModule(app)
+	module.GiftCardModule(app)
 
 	err := app.Listen(":" + envs["PORT"])
 	if err != nil {
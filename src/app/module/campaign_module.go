@@ -17,7 +17,9 @@ func CampaignModule(app *fiber.App) {
 
 	app.Post("/campaign", handler.CreateCampaign)
 	app.Get("/campaign/:id", handler.GetCampaign)
+	app.Get("/campaign/:id/stats", handler.GetCampaignStats)
 	app.Put("/campaign/:id", handler.UpdateCampaign)
 	app.Delete("/campaign/:id", handler.DeleteCampaign)
 	app.Get("/campaigns", handler.ListCampaigns)
+	app.Get("/campaigns/search", handler.SearchCampaign)
 }
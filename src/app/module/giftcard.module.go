@@ -3,22 +3,69 @@ package module
 import (
 	"GiftWize/src/app/usecase"
 	handler2 "GiftWize/src/infreaestructure/handler"
+	"GiftWize/src/infreaestructure/middleware"
 	"GiftWize/src/infreaestructure/repository"
 	"GiftWize/src/shared"
+	"context"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	// idempotencyKeyTTL is how long a stored response can be replayed.
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyKeyPurgeInterval is how often expired idempotency keys are
+	// deleted.
+	idempotencyKeyPurgeInterval = time.Hour
+	// balanceInquiryRateLimit is how many balance inquiries a client can make
+	// per balanceInquiryRateWindow, over both balance routes.
+	balanceInquiryRateLimit  = 30
+	balanceInquiryRateWindow = time.Minute
+)
+
 func GiftCardModule(app *fiber.App) {
 	db := shared.Init()
 	giftCardRepo := repository.NewGiftCardRepository(db)    // Returns IGiftCardRepository
-	giftCardUseCase := usecase.NewGiftCardUseCase(giftCardRepo) // Expects IGiftCardRepository, returns IGiftCardUseCase
+	campaignRepo := repository.NewCampaignRepository(db)
+	settingRepo := repository.NewSettingRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	transactor := repository.NewTransactor(db)
+	giftCardUseCase := usecase.NewGiftCardUseCase(giftCardRepo, campaignRepo, settingRepo, auditRepo, transactor) // Expects IGiftCardRepository, returns IGiftCardUseCase
 	handler := handler2.NewGiftCardHandler(giftCardUseCase)  // Expects IGiftCardUseCase
+	giftCardUseCase.StartExpirySweeper(context.Background())
+	giftCardUseCase.StartCampaignScheduler(context.Background())
+	giftCardUseCase.StartIssuanceJobRecovery(context.Background())
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	idempotent := middleware.Idempotency(idempotencyRepo, idempotencyKeyTTL)
+	// Shared by both balance routes, so that neither can be used to get around the other
+	balanceRateLimit := middleware.RateLimit(balanceInquiryRateLimit, balanceInquiryRateWindow)
+	middleware.StartIdempotencyPurge(context.Background(), idempotencyRepo, idempotencyKeyPurgeInterval)
 
-	app.Post("/giftcard", handler.CreateGiftCard)
+	app.Post("/giftcard", idempotent, handler.CreateGiftCard)
 	app.Get("/giftcard/:id", handler.GetGiftCardByID)
 	app.Put("/giftcard/:id", handler.UpdateGiftCard)
+	app.Put("/giftcard/:id/status", handler.ChangeGiftCardStatus)
+	app.Put("/giftcard/:id/expiration", handler.ExtendGiftCardExpiration)
+	app.Post("/giftcard/:id/pin/reset", handler.ResetGiftCardPin)
 	app.Delete("/giftcard/:id", handler.DeleteGiftCard)
 	app.Get("/giftcards", handler.GetAllGiftCards)
 	app.Get("/giftcards/search", handler.FullTextSearchGiftCard)
+	app.Post("/giftcards/import", handler.ImportGiftCards)
+	app.Get("/giftcards/export", handler.ExportGiftCards)
+	app.Get("/giftcards/expiry-sweep", handler.GetExpirySweepStatus)
+	app.Post("/giftcards/issuance", idempotent, handler.IssueGiftCards)
+	app.Get("/giftcards/issuance/:code", handler.GetIssuanceJob)
+	app.Get("/giftcards/issuance/:code/csv", handler.ExportIssuanceJobCSV)
+	app.Post("/giftcard/:number/redeem", idempotent, handler.RedeemGiftCard)
+	app.Post("/giftcards/redeem", idempotent, handler.RedeemSplitTender)
+	app.Get("/giftcard/:code/transactions", handler.GetGiftCardTransactions)
+	app.Post("/giftcard/:number/authorize", idempotent, handler.AuthorizeGiftCard)
+	app.Post("/authorization/:code/capture", idempotent, handler.CaptureAuthorization)
+	app.Post("/authorization/:code/void", handler.VoidAuthorization)
+	app.Post("/transaction/:id/refund", idempotent, handler.RefundTransaction)
+	app.Post("/giftcard/:number/reload", idempotent, handler.ReloadGiftCard)
+	app.Post("/giftcard/:number/activate", idempotent, handler.ActivateGiftCard)
+	app.Post("/giftcard/:number/balance", balanceRateLimit, handler.GetGiftCardBalance)
+	app.Post("/giftcard/:number/inquiry", balanceRateLimit, handler.InquireGiftCardBalance)
 }
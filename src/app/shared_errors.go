@@ -3,9 +3,48 @@ package app
 import "errors"
 
 var (
-	ErrCampaignNotFound  = errors.New("campaign not found")
-	ErrGiftCardNotFound  = errors.New("gift card not found")
-	ErrGiftCardNotActive = errors.New("gift card is not active")
-	ErrGiftCardExpired   = errors.New("gift card has expired")
+	ErrCampaignNotFound    = errors.New("campaign not found")
+	ErrCampaignExhausted   = errors.New("campaign budget or card limit is exhausted")
+	ErrCampaignCustomerCap = errors.New("customer has reached the card limit of the campaign")
+	ErrCampaignClosed      = errors.New("campaign is paused or has ended")
+	ErrGiftCardNotFound    = errors.New("gift card not found")
+	ErrGiftCardNotActive   = errors.New("gift card is not active")
+	ErrGiftCardExpired     = errors.New("gift card has expired")
+	ErrMaxUsesReached      = errors.New("gift card has reached its maximum number of uses")
 	ErrInsufficientBalance = errors.New("insufficient gift card balance")
+	ErrCurrencyMismatch    = errors.New("no exchange rate configured for the requested currency")
+
+	ErrInvalidGiftCardNumber = errors.New("malformed gift card number")
+
+	ErrInvalidStatusTransition = errors.New("gift card status transition is not allowed")
+	ErrGiftCardNotDistributed  = errors.New("gift card was not distributed from inventory")
+	ErrInventoryNotFound       = errors.New("inventory not found")
+
+	ErrAuthorizationNotFound       = errors.New("authorization not found")
+	ErrAuthorizationNotPending     = errors.New("authorization is no longer pending")
+	ErrAuthorizationExpired        = errors.New("authorization has expired")
+	ErrCaptureExceedsAuthorization = errors.New("capture amount exceeds the authorized amount")
+
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrTransactionNotRefundable = errors.New("only redemptions can be refunded")
+	ErrRefundExceedsRedemption  = errors.New("refund amount exceeds the refundable amount")
+
+	ErrGiftCardNotReloadable = errors.New("gift card is not reloadable")
+	ErrMaxBalanceExceeded    = errors.New("reload would exceed the maximum gift card balance")
+	ErrReloadLimitExceeded   = errors.New("reload would exceed the daily reload limit")
+
+	ErrIssuanceJobNotFound     = errors.New("issuance job not found")
+	ErrIssuanceJobNotCompleted = errors.New("issuance job has not completed")
+	ErrIssuanceJobExported     = errors.New("issuance job was already exported")
+	ErrIssuanceJobPinsExpired  = errors.New("issuance job PINs have expired")
+
+	ErrInvalidImportFile = errors.New("import file is not a valid gift card CSV")
+
+	ErrExpirationNotExtended  = errors.New("new expiration date must be later than the current one")
+	ErrExtensionLimitExceeded = errors.New("extension would exceed the expiration extension limit")
+
+	ErrInvalidPin = errors.New("invalid gift card PIN")
+	ErrPinLocked  = errors.New("gift card PIN is locked after too many failed attempts")
+	// ErrPinResetRequired is returned for imported cards that came without a PIN
+	ErrPinResetRequired = errors.New("gift card PIN must be reissued before the card can be used")
 )
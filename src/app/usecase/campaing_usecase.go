@@ -2,12 +2,14 @@ package usecase
 
 import (
 	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
 	"GiftWize/src/entity/request"
 	"GiftWize/src/entity/response"
 	"GiftWize/src/infreaestructure/repository" // Will use repository.ICampaignRepository
 	"context"
 	"errors" // Added for errors.Is
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -22,6 +24,7 @@ type ICampaignUseCase interface {
 	DeleteCampaign(ctx context.Context, id int) error
 	SearchCampaign(ctx context.Context, param string) ([]response.CampaignResponse, error)
 	ListCampaigns(ctx context.Context) ([]response.CampaignResponse, error)
+	GetCampaignStats(ctx context.Context, id int, interval string) (*response.CampaignStatsResponse, error)
 }
 
 type CampaignUseCase struct {
@@ -74,15 +77,8 @@ func (c *CampaignUseCase) GetCampaign(ctx context.Context, id int) (*response.Ca
 		return nil, nil
 	}
 
-	return &response.CampaignResponse{
-		ID:                 campaign.ID,
-		Uuid:               campaign.CampaignUUID,
-		Name:               campaign.Name,
-		Description:        campaign.Description,
-		StartDate:          campaign.StartDate.Format("2006-01-02"), // Formato de fecha
-		EndDate:            campaign.EndDate.Format("2006-01-02"),   // Formato de fecha
-		DiscountPercentage: fmt.Sprintf("%.2f%%", campaign.DiscountPercentage),
-	}, nil
+	result := toCampaignResponse(campaign)
+	return &result, nil
 }
 
 func (c *CampaignUseCase) UpdateCampaign(ctx context.Context, id int, data *request.UpdateCampaignRequest) error {
@@ -126,17 +122,10 @@ func (c *CampaignUseCase) SearchCampaign(ctx context.Context, param string) ([]r
 		return nil, err
 	}
 
-	var campaignResponses []response.CampaignResponse
+	// Best matches first, as ranked by the repository
+	campaignResponses := []response.CampaignResponse{}
 	for _, campaign := range campaigns {
-		campaignResponses = append(campaignResponses, response.CampaignResponse{
-			ID:                 campaign.ID,
-			Name:               campaign.Name,
-			Description:        campaign.Description,
-			StartDate:          campaign.StartDate.Format("2006-01-02"), // Formato de fecha
-			EndDate:            campaign.EndDate.Format("2006-01-02"),   // Formato de fecha
-			IsEnabled:          campaign.IsEnabled,
-			DiscountPercentage: fmt.Sprintf("%.2f%%", campaign.DiscountPercentage),
-		})
+		campaignResponses = append(campaignResponses, toCampaignResponse(campaign))
 	}
 
 	log.Info("Campaigns retrieved successfully")
@@ -184,17 +173,104 @@ func (c *CampaignUseCase) ListCampaigns(ctx context.Context) ([]response.Campaig
 
 	var campaignResponses []response.CampaignResponse
 	for _, campaign := range campaigns {
-		campaignResponses = append(campaignResponses, response.CampaignResponse{
-			ID:                 campaign.ID,
-			Name:               campaign.Name,
-			Description:        campaign.Description,
-			StartDate:          campaign.StartDate.Format("2006-01-02"), // Formato de fecha
-			EndDate:            campaign.EndDate.Format("2006-01-02"),   // Formato de fecha
-			IsEnabled:          campaign.IsEnabled,
-			DiscountPercentage: fmt.Sprintf("%.2f%%", campaign.DiscountPercentage),
-		})
+		campaignResponses = append(campaignResponses, toCampaignResponse(campaign))
 	}
 
 	log.Info("Campaigns retrieved successfully")
 	return campaignResponses, nil
 }
+
+// GetCampaignStats reports how the cards of a campaign performed, with the
+// redemptions grouped by interval (day, week or month; day when empty).
+func (c *CampaignUseCase) GetCampaignStats(ctx context.Context, id int, interval string) (*response.CampaignStatsResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("GetCampaignStats usecase")
+
+	campaign, err := c.campaignRepo.GetCampaign(ctx, id)
+	if err != nil {
+		log.Errorf("Error finding campaign with id %d for stats: %v", id, err)
+		return nil, err
+	}
+	if campaign == nil {
+		log.Warnf("Campaign not found with id %d for stats", id)
+		return nil, app.ErrCampaignNotFound
+	}
+	if interval == "" {
+		interval = "day"
+	}
+
+	stats, err := c.campaignRepo.GetCampaignCurrencyStats(ctx, id)
+	if err != nil {
+		log.Errorf("Error getting stats of campaign %d: %v", id, err)
+		return nil, err
+	}
+	periods, err := c.campaignRepo.GetCampaignRedemptions(ctx, id, interval)
+	if err != nil {
+		log.Errorf("Error getting redemptions of campaign %d: %v", id, err)
+		return nil, err
+	}
+
+	result := &response.CampaignStatsResponse{
+		CampaignID:  campaign.ID,
+		Totals:      []response.CampaignCurrencyTotals{},
+		Interval:    interval,
+		Redemptions: []response.CampaignRedemptionPeriod{},
+	}
+	// Card counts do not depend on the currency, so they add up across totals
+	for _, currencyStats := range stats {
+		result.CardsIssued += currencyStats.Cards
+		result.CardsActivated += currencyStats.ActivatedCards
+		result.CardsFullyRedeemed += currencyStats.FullyRedeemedCards
+		result.CardsExpired += currencyStats.ExpiredCards
+		result.Totals = append(result.Totals, response.CampaignCurrencyTotals{
+			Currency:    currencyStats.Currency,
+			Cards:       currencyStats.Cards,
+			FaceValue:   currencyStats.FaceValue,
+			Redeemed:    currencyStats.Redeemed,
+			Breakage:    currencyStats.Breakage,
+			Outstanding: currencyStats.Outstanding,
+		})
+	}
+	for _, period := range periods {
+		result.Redemptions = append(result.Redemptions, response.CampaignRedemptionPeriod{
+			Period:      period.Period.Format("2006-01-02"),
+			Currency:    period.Currency,
+			Redemptions: period.Redemptions,
+			Amount:      period.Amount,
+		})
+	}
+
+	log.Infof("Campaign %d stats: %d cards over %d currencies", id, result.CardsIssued, len(result.Totals))
+	return result, nil
+}
+
+// toCampaignResponse maps a campaign with what is left of its budget and
+// card limit.
+func toCampaignResponse(campaign *models.Campaign) response.CampaignResponse {
+	result := response.CampaignResponse{
+		ID:                  campaign.ID,
+		Uuid:                campaign.CampaignUUID,
+		Name:                campaign.Name,
+		Description:         campaign.Description,
+		StartDate:           campaign.StartDate.Format("2006-01-02"), // Formato de fecha
+		EndDate:             campaign.EndDate.Format("2006-01-02"),   // Formato de fecha
+		IsEnabled:           campaign.IsEnabled,
+		Status:              campaignStatus(campaign, time.Now()),
+		DiscountPercentage:  fmt.Sprintf("%.2f%%", campaign.DiscountPercentage),
+		CreatedAt:           campaign.CreatedAt.Format("2006-01-02 15:04:05"),
+		Budget:              campaign.Budget,
+		BudgetConsumed:      campaign.IssuedValue,
+		MaxCards:            campaign.MaxCards,
+		CardsIssued:         campaign.IssuedCards,
+		MaxCardsPerCustomer: campaign.MaxCardsPerCustomer,
+	}
+	if campaign.Budget > 0 {
+		remaining := max(campaign.Budget-campaign.IssuedValue, 0)
+		result.BudgetRemaining = &remaining
+	}
+	if campaign.MaxCards > 0 {
+		remaining := max(campaign.MaxCards-campaign.IssuedCards, 0)
+		result.CardsRemaining = &remaining
+	}
+	return result
+}
@@ -6,9 +6,11 @@ import (
 	"GiftWize/src/entity/request"
 	"GiftWize/src/entity/response"
 	"GiftWize/src/infreaestructure/repository" // Used for ICampaignRepository
+	"GiftWize/src/shared/money"
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -62,6 +64,22 @@ func (m *MockCampaignRepository) SearchCampaign(ctx context.Context, query strin
 	return args.Get(0).([]*models.Campaign), args.Error(1)
 }
 
+func (m *MockCampaignRepository) GetCampaignCurrencyStats(ctx context.Context, id int) ([]models.CampaignCurrencyStats, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CampaignCurrencyStats), args.Error(1)
+}
+
+func (m *MockCampaignRepository) GetCampaignRedemptions(ctx context.Context, id int, interval string) ([]models.CampaignRedemptionPeriod, error) {
+	args := m.Called(ctx, id, interval)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CampaignRedemptionPeriod), args.Error(1)
+}
+
 func (m *MockCampaignRepository) ListCampaigns(ctx context.Context) ([]*models.Campaign, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -70,6 +88,39 @@ func (m *MockCampaignRepository) ListCampaigns(ctx context.Context) ([]*models.C
 	return args.Get(0).([]*models.Campaign), args.Error(1)
 }
 
+func (m *MockCampaignRepository) GetCampaignByIDForUpdate(ctx context.Context, id uint) (*models.Campaign, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignRepository) UpdateCampaignStatus(ctx context.Context, id uint, status string, endedAt *time.Time) error {
+	args := m.Called(ctx, id, status, endedAt)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) SetCampaignCardsExpiryPending(ctx context.Context, id uint, pending bool) error {
+	args := m.Called(ctx, id, pending)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) ReserveCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) (bool, error) {
+	args := m.Called(ctx, campaignID, cards, value)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCampaignRepository) ReleaseCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) error {
+	args := m.Called(ctx, campaignID, cards, value)
+	return args.Error(0)
+}
+
+func (m *MockCampaignRepository) CountCustomerCampaignCards(ctx context.Context, campaignID uint, customerID uint) (int64, error) {
+	args := m.Called(ctx, campaignID, customerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 
 func TestCampaignUseCase_UpdateCampaign(t *testing.T) {
 	ctx := context.Background()
@@ -212,3 +263,124 @@ func TestCampaignUseCase_DeleteCampaign(t *testing.T) {
 		mockRepoCampaign.AssertExpectations(t)
 	})
 }
+
+func TestCampaignUseCase_GetCampaign_Budget(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reports what is left of the budget and card limit", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("GetCampaign", ctx, 12).Return(&models.Campaign{
+			ID: 12, Budget: money.MustParse("1000.00"), IssuedValue: money.MustParse("250.00"), MaxCards: 10, IssuedCards: 10, MaxCardsPerCustomer: 2,
+		}, nil).Once()
+
+		resp, err := useCase.GetCampaign(ctx, 12)
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("250.00"), resp.BudgetConsumed)
+		if assert.NotNil(t, resp.BudgetRemaining) {
+			assert.Equal(t, money.MustParse("750.00"), *resp.BudgetRemaining)
+		}
+		if assert.NotNil(t, resp.CardsRemaining) {
+			assert.Equal(t, 0, *resp.CardsRemaining)
+		}
+		assert.Equal(t, 2, resp.MaxCardsPerCustomer)
+	})
+
+	t.Run("unlimited campaign has nothing remaining to report", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("GetCampaign", ctx, 12).Return(&models.Campaign{ID: 12, IssuedValue: money.MustParse("250.00"), IssuedCards: 10}, nil).Once()
+
+		resp, err := useCase.GetCampaign(ctx, 12)
+		assert.NoError(t, err)
+		assert.Nil(t, resp.BudgetRemaining)
+		assert.Nil(t, resp.CardsRemaining)
+	})
+}
+
+func TestCampaignUseCase_GetCampaignStats(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds up card counts across currencies", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("GetCampaign", ctx, 12).Return(&models.Campaign{ID: 12}, nil).Once()
+		mockRepo.On("GetCampaignCurrencyStats", ctx, 12).Return([]models.CampaignCurrencyStats{
+			{Currency: "EUR", Cards: 2, ActivatedCards: 2, FullyRedeemedCards: 1, FaceValue: money.MustParse("50.00"), Redeemed: money.MustParse("30.00")},
+			{Currency: "USD", Cards: 3, ActivatedCards: 1, ExpiredCards: 1, FaceValue: money.MustParse("75.00"), Breakage: money.MustParse("25.00"), Outstanding: money.MustParse("50.00")},
+		}, nil).Once()
+		mockRepo.On("GetCampaignRedemptions", ctx, 12, "day").Return([]models.CampaignRedemptionPeriod{
+			{Period: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), Currency: "EUR", Redemptions: 2, Amount: money.MustParse("30.00")},
+		}, nil).Once()
+
+		resp, err := useCase.GetCampaignStats(ctx, 12, "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), resp.CardsIssued)
+		assert.Equal(t, int64(3), resp.CardsActivated)
+		assert.Equal(t, int64(1), resp.CardsFullyRedeemed)
+		assert.Equal(t, int64(1), resp.CardsExpired)
+		assert.Equal(t, "day", resp.Interval)
+		if assert.Len(t, resp.Totals, 2) {
+			assert.Equal(t, money.MustParse("25.00"), resp.Totals[1].Breakage)
+		}
+		if assert.Len(t, resp.Redemptions, 1) {
+			assert.Equal(t, "2026-06-01", resp.Redemptions[0].Period)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("campaign without cards", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("GetCampaign", ctx, 12).Return(&models.Campaign{ID: 12}, nil).Once()
+		mockRepo.On("GetCampaignCurrencyStats", ctx, 12).Return(nil, nil).Once()
+		mockRepo.On("GetCampaignRedemptions", ctx, 12, "month").Return(nil, nil).Once()
+
+		resp, err := useCase.GetCampaignStats(ctx, 12, "month")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), resp.CardsIssued)
+		assert.NotNil(t, resp.Totals)
+		assert.NotNil(t, resp.Redemptions)
+	})
+
+	t.Run("unknown campaign", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("GetCampaign", ctx, 12).Return(nil, nil).Once()
+
+		_, err := useCase.GetCampaignStats(ctx, 12, "")
+		assert.ErrorIs(t, err, app.ErrCampaignNotFound)
+		mockRepo.AssertNotCalled(t, "GetCampaignCurrencyStats", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCampaignUseCase_SearchCampaign(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps the ranking of the repository", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("SearchCampaign", ctx, "summer").Return([]*models.Campaign{
+			{ID: 7, Name: "Summer"},
+			{ID: 3, Name: "Holidays", Description: "Summer and winter"},
+		}, nil).Once()
+
+		resp, err := useCase.SearchCampaign(ctx, "summer")
+		assert.NoError(t, err)
+		if assert.Len(t, resp, 2) {
+			assert.Equal(t, uint(7), resp[0].ID)
+			assert.Equal(t, uint(3), resp[1].ID)
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		mockRepo := new(MockCampaignRepository)
+		useCase := NewCampaignUseCase(mockRepo)
+		mockRepo.On("SearchCampaign", ctx, "autumn").Return(nil, nil).Once()
+
+		resp, err := useCase.SearchCampaign(ctx, "autumn")
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.Empty(t, resp)
+	})
+}
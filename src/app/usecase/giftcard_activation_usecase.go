@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ActivateGiftCard makes an issued card spendable. It optionally loads an
+// amount onto the card, within the maximum balance of reloads, records the
+// activation date and operator, and is rejected for cards that were never
+// distributed from inventory.
+func (g *GiftCardUseCase) ActivateGiftCard(ctx context.Context, giftCardNumber string, data request.ActivateGiftCardRequest) (response.ActivateGiftCardResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ActivateGiftCard use case")
+
+	var limits reloadLimits
+	if data.Amount > 0 {
+		var err error
+		if limits, err = g.reloadLimits(ctx); err != nil {
+			log.Errorf("Error reading reload limits: %v", err)
+			return response.ActivateGiftCardResponse{}, err
+		}
+	}
+
+	var result response.ActivateGiftCardResponse
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		if giftCard.Status != models.GiftCardStatusIssued {
+			log.Warnf("Gift card %s cannot be activated. Current status: %s", giftCardNumber, giftCard.Status)
+			return customerrors.ErrInvalidStatusTransition
+		}
+		if err := g.checkDistributed(ctx, giftCard); err != nil {
+			return err
+		}
+		expired, err := g.pastExpiry(ctx, giftCard, time.Now())
+		if err != nil {
+			return err
+		}
+		if expired {
+			log.Warnf("Gift card %s has expired on %s", giftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"))
+			return customerrors.ErrGiftCardExpired
+		}
+
+		newBalance := giftCard.Balance + data.Amount
+		if data.Amount > 0 && limits.hasMaxBalance && newBalance > limits.maxBalance {
+			log.Warnf("Activation load of %s on gift card %s exceeds the maximum balance %s", data.Amount, giftCardNumber, limits.maxBalance)
+			return customerrors.ErrMaxBalanceExceeded
+		}
+		reason := fmt.Sprintf("activated by %s", data.ActivatedBy)
+		if err := g.setBalanceAndStatus(ctx, giftCard, newBalance, models.GiftCardStatusActive, reason); err != nil {
+			log.Errorf("Error activating gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		activatedAt := time.Now()
+		if err := g.giftCardRepo.UpdateGiftCardActivation(ctx, giftCard.Code, activatedAt, data.ActivatedBy, newBalance); err != nil {
+			log.Errorf("Error recording activation of gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		result = response.ActivateGiftCardResponse{
+			GiftCardNumber: giftCard.GiftCardNumber,
+			Balance:        newBalance,
+			Currency:       giftCard.Currency,
+			Status:         string(giftCard.Status),
+			ActivationDate: activatedAt.Format("2006-01-02 15:04:05"),
+			ActivatedBy:    data.ActivatedBy,
+		}
+
+		if data.Amount > 0 {
+			transaction := &models.Transaction{
+				GiftCardID:      giftCard.ID,
+				Amount:          data.Amount,
+				BalanceAfter:    newBalance,
+				Currency:        giftCard.Currency,
+				TransactionType: models.TransactionTypeActivation,
+				Reference:       uuid.NewString(),
+			}
+			if err := g.giftCardRepo.CreateTransaction(ctx, transaction); err != nil {
+				log.Errorf("Error recording activation load for gift card %s: %v", giftCardNumber, err)
+				return err
+			}
+			result.TransactionID = transaction.ID
+		}
+
+		return g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{
+			Action: fmt.Sprintf("activate gift card %s with %s %s by %s", giftCard.GiftCardNumber, newBalance, giftCard.Currency, data.ActivatedBy),
+		})
+	})
+	if err != nil {
+		return response.ActivateGiftCardResponse{}, err
+	}
+
+	log.Infof("Gift card %s activated by %s", giftCardNumber, data.ActivatedBy)
+	return result, nil
+}
+
+// checkDistributed verifies that the card belongs to an inventory batch that
+// has left the stock room.
+func (g *GiftCardUseCase) checkDistributed(ctx context.Context, giftCard *models.GiftCard) error {
+	log := logrus.WithContext(ctx)
+
+	if giftCard.InventoryID == nil {
+		log.Warnf("Gift card %s is not linked to any inventory", giftCard.GiftCardNumber)
+		return customerrors.ErrGiftCardNotDistributed
+	}
+
+	inventory, err := g.giftCardRepo.GetInventoryByID(ctx, *giftCard.InventoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Inventory %d of gift card %s not found", *giftCard.InventoryID, giftCard.GiftCardNumber)
+			return customerrors.ErrGiftCardNotDistributed
+		}
+		log.Errorf("Error retrieving inventory %d: %v", *giftCard.InventoryID, err)
+		return err
+	}
+	if inventory.Status != models.InventoryStatusDistributed {
+		log.Warnf("Gift card %s is still in inventory %d (status: %s)", giftCard.GiftCardNumber, inventory.ID, inventory.Status)
+		return customerrors.ErrGiftCardNotDistributed
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftCardUseCase_ActivateGiftCard(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+	inventoryID := uint(4)
+
+	card := func(status models.GiftCardStatus, balance string, inventory *uint) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             7,
+			Code:           "test-code-activate",
+			GiftCardNumber: cardNumber,
+			Type:           "physical",
+			Balance:        money.MustParse(balance),
+			Currency:       "USD",
+			Status:         status,
+			InventoryID:    inventory,
+			ExpirationDate: time.Now().AddDate(1, 0, 0),
+		}
+	}
+
+	t.Run("loads the balance and activates a distributed card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), mockAudit, fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusIssued, "0.00", &inventoryID), nil).Once()
+		mockRepo.On("GetInventoryByID", ctx, inventoryID).Return(&models.Inventory{ID: inventoryID, Status: models.InventoryStatusDistributed}, nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-activate", money.MustParse("50.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(func(change *models.GiftCardStatusChange) bool {
+			return isStatusChange(models.GiftCardStatusIssued, models.GiftCardStatusActive)(change) && change.Reason == "activated by register-12"
+		})).Return(nil).Once()
+		mockRepo.On("UpdateGiftCardActivation", ctx, "test-code-activate", mock.AnythingOfType("time.Time"), "register-12", money.MustParse("50.00")).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeActivation, money.MustParse("50.00"), money.MustParse("50.00")))).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasSuffix(auditLog.Action, "by register-12")
+		})).Return(nil).Once()
+
+		resp, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{Amount: money.MustParse("50.00"), ActivatedBy: "register-12"})
+		assert.NoError(t, err)
+		assert.Equal(t, "active", resp.Status)
+		assert.Equal(t, money.MustParse("50.00"), resp.Balance)
+		assert.Equal(t, "register-12", resp.ActivatedBy)
+		assert.NotEmpty(t, resp.ActivationDate)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("preloaded cards are activated without a ledger entry", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusIssued, "25.00", &inventoryID), nil).Once()
+		mockRepo.On("GetInventoryByID", ctx, inventoryID).Return(&models.Inventory{ID: inventoryID, Status: models.InventoryStatusDistributed}, nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-activate", money.MustParse("25.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.AnythingOfType("*models.GiftCardStatusChange")).Return(nil).Once()
+		mockRepo.On("UpdateGiftCardActivation", ctx, "test-code-activate", mock.AnythingOfType("time.Time"), "register-12", money.MustParse("25.00")).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.AnythingOfType("*models.AuditLog")).Return(nil).Once()
+
+		resp, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{ActivatedBy: "register-12"})
+		assert.NoError(t, err)
+		assert.Zero(t, resp.TransactionID)
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("loads past the maximum balance are rejected", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{reloadMaxBalanceSetting: "40.00"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusIssued, "0.00", &inventoryID), nil).Once()
+		mockRepo.On("GetInventoryByID", ctx, inventoryID).Return(&models.Inventory{ID: inventoryID, Status: models.InventoryStatusDistributed}, nil).Once()
+
+		_, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{Amount: money.MustParse("50.00"), ActivatedBy: "register-12"})
+		assert.ErrorIs(t, err, app.ErrMaxBalanceExceeded)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cards outside inventory cannot be activated", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusIssued, "0.00", nil), nil).Once()
+
+		_, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{ActivatedBy: "register-12"})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotDistributed)
+	})
+
+	t.Run("cards still in stock cannot be activated", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusIssued, "0.00", &inventoryID), nil).Once()
+		mockRepo.On("GetInventoryByID", ctx, inventoryID).Return(&models.Inventory{ID: inventoryID, Status: models.InventoryStatusInStock}, nil).Once()
+
+		_, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{ActivatedBy: "register-12"})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotDistributed)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("active cards cannot be activated again", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(models.GiftCardStatusActive, "10.00", &inventoryID), nil).Once()
+
+		_, err := useCase.ActivateGiftCard(ctx, cardNumber, request.ActivateGiftCardRequest{ActivatedBy: "register-12"})
+		assert.ErrorIs(t, err, app.ErrInvalidStatusTransition)
+	})
+}
@@ -0,0 +1,434 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// authorizationExpirySetting is the models.Setting holding how many minutes
+	// an authorization holds funds before it lapses.
+	authorizationExpirySetting = "authorization.expiry_minutes"
+
+	defaultAuthorizationExpiry = 30 * time.Minute
+)
+
+// AuthorizeGiftCardAmount places a hold on the gift card. The held amount is
+// no longer available to other authorizations or redemptions, but the ledger
+// balance is only reduced when the authorization is captured. Uncaptured holds
+// lapse after the configured window.
+func (g *GiftCardUseCase) AuthorizeGiftCardAmount(ctx context.Context, giftCardNumber string, data request.AuthorizeGiftCardRequest) (response.AuthorizationResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("AuthorizeGiftCardAmount use case")
+
+	if err := g.checkGiftCardNumber(ctx, giftCardNumber); err != nil {
+		return response.AuthorizationResponse{}, err
+	}
+
+	window, err := g.authorizationWindow(ctx)
+	if err != nil {
+		log.Errorf("Error reading authorization window: %v", err)
+		return response.AuthorizationResponse{}, err
+	}
+
+	var result response.AuthorizationResponse
+	// authorizeErr carries a rejection whose side effects must still be committed.
+	var authorizeErr error
+	err = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		if commit, err := g.verifyPin(ctx, giftCard, data.Pin); err != nil {
+			if commit {
+				authorizeErr = err
+				return nil
+			}
+			return err
+		}
+
+		if commit, err := g.checkRedeemable(ctx, giftCard); err != nil {
+			if commit {
+				authorizeErr = err
+				return nil
+			}
+			return err
+		}
+		if err := checkUsesLeft(ctx, giftCard); err != nil {
+			return err
+		}
+
+		amount := data.Amount
+		if data.Currency != "" && data.Currency != giftCard.Currency {
+			amount, err = g.convertAmount(ctx, data.Amount, data.Currency, giftCard.Currency)
+			if err != nil {
+				log.Warnf("Cannot authorize %s %s on gift card %s in %s: %v", data.Amount, data.Currency, giftCardNumber, giftCard.Currency, err)
+				return err
+			}
+		}
+
+		available, err := g.availableBalance(ctx, giftCard)
+		if err != nil {
+			log.Errorf("Error computing available balance for gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+		if available < amount {
+			log.Warnf("Insufficient balance in gift card %s. Available: %s, Tried: %s", giftCardNumber, available, amount)
+			return customerrors.ErrInsufficientBalance
+		}
+
+		authorization, err := g.authorize(ctx, giftCard, amount, time.Now().Add(window))
+		if err != nil {
+			log.Errorf("Error creating authorization for gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		result = toAuthorizationResponse(authorization, giftCard.GiftCardNumber, available-amount)
+		return nil
+	})
+	if err != nil {
+		return response.AuthorizationResponse{}, err
+	}
+	if authorizeErr != nil {
+		return response.AuthorizationResponse{}, authorizeErr
+	}
+
+	log.Infof("Authorized %s on gift card %s with code %s", result.Amount, giftCardNumber, result.Code)
+	return result, nil
+}
+
+// CaptureAuthorization deducts a pending authorization from the gift card
+// balance. Capturing less than the authorized amount releases the remainder.
+func (g *GiftCardUseCase) CaptureAuthorization(ctx context.Context, code string, data request.CaptureAuthorizationRequest) (response.AuthorizationResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("CaptureAuthorization use case")
+
+	var result response.AuthorizationResponse
+	// captureErr carries a rejection whose side effects must still be committed.
+	var captureErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, authorization, err := g.lockAuthorization(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if commit, err := g.checkPending(ctx, authorization); err != nil {
+			if commit {
+				captureErr = err
+				return nil
+			}
+			return err
+		}
+
+		amount := data.Amount
+		if amount == 0 {
+			amount = authorization.Amount
+		}
+		if amount > authorization.Amount {
+			log.Warnf("Capture of %s exceeds authorization %s of %s", amount, code, authorization.Amount)
+			return customerrors.ErrCaptureExceedsAuthorization
+		}
+		if giftCard.Status != models.GiftCardStatusActive {
+			log.Warnf("Gift card %s is not active. Current status: %s", giftCard.GiftCardNumber, giftCard.Status)
+			return customerrors.ErrGiftCardNotActive
+		}
+		if err := checkUsesLeft(ctx, giftCard); err != nil {
+			return err
+		}
+		if giftCard.Balance < amount {
+			log.Warnf("Insufficient balance in gift card %s. Has: %s, Tried: %s", giftCard.GiftCardNumber, giftCard.Balance, amount)
+			return customerrors.ErrInsufficientBalance
+		}
+
+		if _, err := g.capture(ctx, giftCard, authorization, amount); err != nil {
+			log.Errorf("Error capturing authorization %s: %v", code, err)
+			return err
+		}
+
+		available, err := g.availableBalance(ctx, giftCard)
+		if err != nil {
+			log.Errorf("Error computing available balance for gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+		result = toAuthorizationResponse(authorization, giftCard.GiftCardNumber, available)
+		return nil
+	})
+	if err != nil {
+		return response.AuthorizationResponse{}, err
+	}
+	if captureErr != nil {
+		return response.AuthorizationResponse{}, captureErr
+	}
+
+	log.Infof("Captured %s of authorization %s", result.CapturedAmount, code)
+	return result, nil
+}
+
+// VoidAuthorization releases a pending authorization without touching the balance.
+func (g *GiftCardUseCase) VoidAuthorization(ctx context.Context, code string) (response.AuthorizationResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("VoidAuthorization use case")
+
+	var result response.AuthorizationResponse
+	// voidErr carries a rejection whose side effects must still be committed.
+	var voidErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, authorization, err := g.lockAuthorization(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if commit, err := g.checkPending(ctx, authorization); err != nil {
+			if commit {
+				voidErr = err
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now()
+		authorization.Status = models.AuthorizationStatusVoided
+		authorization.VoidedAt = &now
+		if err := g.giftCardRepo.UpdateAuthorization(ctx, authorization); err != nil {
+			log.Errorf("Error voiding authorization %s: %v", code, err)
+			return err
+		}
+
+		available, err := g.availableBalance(ctx, giftCard)
+		if err != nil {
+			log.Errorf("Error computing available balance for gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+		result = toAuthorizationResponse(authorization, giftCard.GiftCardNumber, available)
+		return nil
+	})
+	if err != nil {
+		return response.AuthorizationResponse{}, err
+	}
+	if voidErr != nil {
+		return response.AuthorizationResponse{}, voidErr
+	}
+
+	log.Infof("Voided authorization %s", code)
+	return result, nil
+}
+
+// checkRedeemable verifies that a locked gift card accepts new holds and
+// redemptions. A card past its expiration date, including the grace period
+// of its expiry policy, is expired in place; commit
+// reports that the returned ErrGiftCardExpired must be returned only after
+// committing the transaction, so the expiry is persisted.
+func (g *GiftCardUseCase) checkRedeemable(ctx context.Context, giftCard *models.GiftCard) (commit bool, err error) {
+	log := logrus.WithContext(ctx)
+
+	if giftCard.Status != models.GiftCardStatusActive {
+		log.Warnf("Gift card %s is not active. Current status: %s", giftCard.GiftCardNumber, giftCard.Status)
+		return false, customerrors.ErrGiftCardNotActive
+	}
+
+	expired, err := g.pastExpiry(ctx, giftCard, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if expired {
+		log.Warnf("Gift card %s has expired on %s", giftCard.GiftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"))
+		// Persist this status change, forfeiting the remaining balance
+		if err := g.expireGiftCard(ctx, giftCard, "expiration date passed"); err != nil {
+			log.Errorf("Failed to update status to 'expired' for gift card %s (code: %s): %v", giftCard.GiftCardNumber, giftCard.Code, err)
+			return false, customerrors.ErrGiftCardExpired
+		}
+		return true, customerrors.ErrGiftCardExpired
+	}
+
+	return false, nil
+}
+
+// checkUsesLeft verifies that a card limited to MaxUses redemptions has
+// uses left. A MaxUses of 0 means unlimited.
+func checkUsesLeft(ctx context.Context, giftCard *models.GiftCard) error {
+	if giftCard.MaxUses > 0 && giftCard.CurrentUses >= giftCard.MaxUses {
+		logrus.WithContext(ctx).Warnf("Gift card %s has been used %d of %d times", giftCard.GiftCardNumber, giftCard.CurrentUses, giftCard.MaxUses)
+		return customerrors.ErrMaxUsesReached
+	}
+	return nil
+}
+
+// checkPending verifies that a locked authorization can still be captured or
+// voided. A pending authorization past its window is marked expired; commit
+// reports that the returned ErrAuthorizationExpired must be returned only
+// after committing the transaction.
+func (g *GiftCardUseCase) checkPending(ctx context.Context, authorization *models.Authorization) (commit bool, err error) {
+	log := logrus.WithContext(ctx)
+
+	if authorization.Status != models.AuthorizationStatusPending {
+		log.Warnf("Authorization %s is not pending. Current status: %s", authorization.Code, authorization.Status)
+		return false, customerrors.ErrAuthorizationNotPending
+	}
+
+	if !time.Now().Before(authorization.ExpiresAt) {
+		log.Warnf("Authorization %s expired at %s", authorization.Code, authorization.ExpiresAt.Format("2006-01-02 15:04:05"))
+		authorization.Status = models.AuthorizationStatusExpired
+		if err := g.giftCardRepo.UpdateAuthorization(ctx, authorization); err != nil {
+			log.Errorf("Failed to mark authorization %s as expired: %v", authorization.Code, err)
+			return false, err
+		}
+		return true, customerrors.ErrAuthorizationExpired
+	}
+
+	return false, nil
+}
+
+// lockAuthorization locks the gift card of an authorization and then the
+// authorization itself. Cards are always locked before authorizations so
+// concurrent captures, voids and redemptions cannot deadlock.
+func (g *GiftCardUseCase) lockAuthorization(ctx context.Context, code string) (*models.GiftCard, *models.Authorization, error) {
+	log := logrus.WithContext(ctx)
+
+	authorization, err := g.giftCardRepo.GetAuthorizationByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Authorization %s not found: %v", code, err)
+			return nil, nil, customerrors.ErrAuthorizationNotFound
+		}
+		log.Errorf("Error retrieving authorization %s: %v", code, err)
+		return nil, nil, err
+	}
+
+	giftCard, err := g.giftCardRepo.GetGiftCardByIDForUpdate(ctx, authorization.GiftCardID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Gift card %d of authorization %s not found: %v", authorization.GiftCardID, code, err)
+			return nil, nil, customerrors.ErrGiftCardNotFound
+		}
+		log.Errorf("Error retrieving gift card %d: %v", authorization.GiftCardID, err)
+		return nil, nil, err
+	}
+
+	authorization, err = g.giftCardRepo.GetAuthorizationByCodeForUpdate(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, customerrors.ErrAuthorizationNotFound
+		}
+		log.Errorf("Error locking authorization %s: %v", code, err)
+		return nil, nil, err
+	}
+
+	return giftCard, authorization, nil
+}
+
+// availableBalance is the ledger balance of the card minus its live holds.
+func (g *GiftCardUseCase) availableBalance(ctx context.Context, giftCard *models.GiftCard) (money.Amount, error) {
+	held, err := g.giftCardRepo.GetHeldAmount(ctx, giftCard.ID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return giftCard.Balance - held, nil
+}
+
+// authorize records a pending hold of amount, in the card currency, on a locked card.
+func (g *GiftCardUseCase) authorize(ctx context.Context, giftCard *models.GiftCard, amount money.Amount, expiresAt time.Time) (*models.Authorization, error) {
+	authorization := &models.Authorization{
+		Code:       uuid.NewString(),
+		GiftCardID: giftCard.ID,
+		Amount:     amount,
+		Currency:   giftCard.Currency,
+		Status:     models.AuthorizationStatusPending,
+		ExpiresAt:  expiresAt,
+	}
+	if err := g.giftCardRepo.CreateAuthorization(ctx, authorization); err != nil {
+		return nil, err
+	}
+	return authorization, nil
+}
+
+// capture deducts amount from a locked card, counts the use, records the
+// redemption in the ledger under the authorization code and closes the
+// authorization. giftCard and authorization are updated in place.
+func (g *GiftCardUseCase) capture(ctx context.Context, giftCard *models.GiftCard, authorization *models.Authorization, amount money.Amount) (*models.Transaction, error) {
+	log := logrus.WithContext(ctx)
+
+	newBalance := giftCard.Balance - amount
+	newStatus := giftCard.Status
+	if newBalance == 0 {
+		newStatus = models.GiftCardStatusUsed
+		log.Infof("Gift card %s balance is now 0. Setting status to '%s'", giftCard.GiftCardNumber, newStatus)
+	}
+
+	if err := g.setBalanceAndStatus(ctx, giftCard, newBalance, newStatus, "balance redeemed"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := g.giftCardRepo.RecordGiftCardUse(ctx, giftCard.Code, now); err != nil {
+		return nil, err
+	}
+	giftCard.CurrentUses++
+	giftCard.LastUsedDate = now
+
+	transaction := &models.Transaction{
+		GiftCardID:      giftCard.ID,
+		Amount:          -amount,
+		BalanceAfter:    newBalance,
+		Currency:        giftCard.Currency,
+		TransactionType: models.TransactionTypeRedemption,
+		Reference:       authorization.Code,
+	}
+	if err := g.giftCardRepo.CreateTransaction(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	authorization.CapturedAmount = amount
+	authorization.CapturedAt = &now
+	authorization.Status = models.AuthorizationStatusCaptured
+	if amount < authorization.Amount {
+		authorization.Status = models.AuthorizationStatusPartiallyCaptured
+	}
+	if err := g.giftCardRepo.UpdateAuthorization(ctx, authorization); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Deducted %s from gift card %s. New balance: %s", amount, giftCard.GiftCardNumber, newBalance)
+	return transaction, nil
+}
+
+// authorizationWindow reads how long authorizations hold funds,
+// falling back to defaultAuthorizationExpiry when it is not configured.
+func (g *GiftCardUseCase) authorizationWindow(ctx context.Context) (time.Duration, error) {
+	minutes, ok, err := intSetting(ctx, g.settingRepo, authorizationExpirySetting)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return defaultAuthorizationExpiry, nil
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+func toAuthorizationResponse(authorization *models.Authorization, giftCardNumber string, available money.Amount) response.AuthorizationResponse {
+	return response.AuthorizationResponse{
+		Code:             authorization.Code,
+		GiftCardNumber:   giftCardNumber,
+		Amount:           authorization.Amount,
+		CapturedAmount:   authorization.CapturedAmount,
+		Currency:         authorization.Currency,
+		Status:           authorization.Status,
+		ExpiresAt:        authorization.ExpiresAt.Format("2006-01-02 15:04:05"),
+		AvailableBalance: available,
+	}
+}
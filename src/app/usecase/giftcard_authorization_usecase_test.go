@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newAuthorizableGiftCard() *models.GiftCard {
+	return &models.GiftCard{
+		ID:             7,
+		Code:           "test-code-auth",
+		GiftCardNumber: "GC12345678901237",
+		Balance:        money.MustParse("100.00"),
+		Currency:       "USD",
+		Status:         "active",
+		ExpirationDate: time.Now().AddDate(0, 0, 1),
+	}
+}
+
+func newPendingAuthorization(amount string, expiresAt time.Time) *models.Authorization {
+	return &models.Authorization{
+		ID:         3,
+		Code:       "auth-code-1",
+		GiftCardID: 7,
+		Amount:     money.MustParse(amount),
+		Currency:   "USD",
+		Status:     models.AuthorizationStatusPending,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+func TestGiftCardUseCase_AuthorizeGiftCardAmount(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+
+	t.Run("holds funds for the configured window", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "authorization.expiry_minutes").Return(&models.Setting{Name: "authorization.expiry_minutes", Value: "60"}, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(newAuthorizableGiftCard(), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.MustParse("30.00"), nil).Once()
+		mockRepo.On("CreateAuthorization", ctx, mock.MatchedBy(func(authorization *models.Authorization) bool {
+			return authorization.Status == models.AuthorizationStatusPending &&
+				authorization.Amount == money.MustParse("40.00") &&
+				authorization.ExpiresAt.After(time.Now().Add(59*time.Minute))
+		})).Return(nil).Once()
+
+		resp, err := useCase.AuthorizeGiftCardAmount(ctx, cardNumber, request.AuthorizeGiftCardRequest{Amount: money.MustParse("40.00")})
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuthorizationStatusPending, resp.Status)
+		assert.Equal(t, money.MustParse("30.00"), resp.AvailableBalance)
+		assert.NotEmpty(t, resp.Code)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("existing holds reduce the available balance", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "authorization.expiry_minutes").Return(nil, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(newAuthorizableGiftCard(), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.MustParse("80.00"), nil).Once()
+
+		_, err := useCase.AuthorizeGiftCardAmount(ctx, cardNumber, request.AuthorizeGiftCardRequest{Amount: money.MustParse("20.01")})
+		assert.ErrorIs(t, err, app.ErrInsufficientBalance)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+
+	t.Run("gift card not found", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "authorization.expiry_minutes").Return(nil, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, "GC99999999999902").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.AuthorizeGiftCardAmount(ctx, "GC99999999999902", request.AuthorizeGiftCardRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+	})
+}
+
+func TestGiftCardUseCase_UseGiftCardAmount_RespectsHolds(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+	giftCard := newAuthorizableGiftCard()
+	mockRepo.On("GetByGiftCardNumberForUpdate", ctx, giftCard.GiftCardNumber).Return(giftCard, nil).Once()
+	mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.MustParse("60.00"), nil).Once()
+
+	resp, err := useCase.UseGiftCardAmount(ctx, giftCard.GiftCardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("50.00")})
+	assert.ErrorIs(t, err, app.ErrInsufficientBalance)
+	assert.Equal(t, money.MustParse("100.00"), resp.Balance)
+	mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGiftCardUseCase_CaptureAuthorization(t *testing.T) {
+	ctx := context.Background()
+	code := "auth-code-1"
+	later := time.Now().Add(time.Hour)
+
+	setup := func(authorization *models.Authorization) *MockGiftCardRepository {
+		mockRepo := new(MockGiftCardRepository)
+		mockRepo.On("GetAuthorizationByCode", ctx, code).Return(authorization, nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(newAuthorizableGiftCard(), nil).Once()
+		mockRepo.On("GetAuthorizationByCodeForUpdate", ctx, code).Return(authorization, nil).Once()
+		return mockRepo
+	}
+
+	t.Run("captures the full authorized amount by default", func(t *testing.T) {
+		mockRepo := setup(newPendingAuthorization("40.00", later))
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-auth", money.MustParse("60.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.Reference == code && isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-40.00"), money.MustParse("60.00"))(transaction)
+		})).Return(nil).Once()
+		mockRepo.On("RecordGiftCardUse", ctx, "test-code-auth", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusCaptured, money.MustParse("40.00")))).Return(nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.Amount(0), nil).Once()
+
+		resp, err := useCase.CaptureAuthorization(ctx, code, request.CaptureAuthorizationRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuthorizationStatusCaptured, resp.Status)
+		assert.Equal(t, money.MustParse("40.00"), resp.CapturedAmount)
+		assert.Equal(t, money.MustParse("60.00"), resp.AvailableBalance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("partial capture releases the remainder", func(t *testing.T) {
+		mockRepo := setup(newPendingAuthorization("40.00", later))
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-auth", money.MustParse("75.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-25.00"), money.MustParse("75.00")))).Return(nil).Once()
+		mockRepo.On("RecordGiftCardUse", ctx, "test-code-auth", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusPartiallyCaptured, money.MustParse("25.00")))).Return(nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.Amount(0), nil).Once()
+
+		resp, err := useCase.CaptureAuthorization(ctx, code, request.CaptureAuthorizationRequest{Amount: money.MustParse("25.00")})
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuthorizationStatusPartiallyCaptured, resp.Status)
+		assert.Equal(t, money.MustParse("75.00"), resp.AvailableBalance)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cannot capture more than authorized", func(t *testing.T) {
+		mockRepo := setup(newPendingAuthorization("40.00", later))
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+
+		_, err := useCase.CaptureAuthorization(ctx, code, request.CaptureAuthorizationRequest{Amount: money.MustParse("40.01")})
+		assert.ErrorIs(t, err, app.ErrCaptureExceedsAuthorization)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lapsed authorization is marked expired", func(t *testing.T) {
+		mockRepo := setup(newPendingAuthorization("40.00", time.Now().Add(-time.Minute)))
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusExpired, 0))).Return(nil).Once()
+
+		_, err := useCase.CaptureAuthorization(ctx, code, request.CaptureAuthorizationRequest{})
+		assert.ErrorIs(t, err, app.ErrAuthorizationExpired)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("authorization already captured", func(t *testing.T) {
+		authorization := newPendingAuthorization("40.00", later)
+		authorization.Status = models.AuthorizationStatusCaptured
+		mockRepo := setup(authorization)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+
+		_, err := useCase.CaptureAuthorization(ctx, code, request.CaptureAuthorizationRequest{})
+		assert.ErrorIs(t, err, app.ErrAuthorizationNotPending)
+	})
+
+	t.Run("authorization not found", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetAuthorizationByCode", ctx, "missing").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.CaptureAuthorization(ctx, "missing", request.CaptureAuthorizationRequest{})
+		assert.ErrorIs(t, err, app.ErrAuthorizationNotFound)
+	})
+}
+
+func TestGiftCardUseCase_VoidAuthorization(t *testing.T) {
+	ctx := context.Background()
+	code := "auth-code-1"
+
+	t.Run("releases a pending authorization", func(t *testing.T) {
+		authorization := newPendingAuthorization("40.00", time.Now().Add(time.Hour))
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetAuthorizationByCode", ctx, code).Return(authorization, nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(newAuthorizableGiftCard(), nil).Once()
+		mockRepo.On("GetAuthorizationByCodeForUpdate", ctx, code).Return(authorization, nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(func(authorization *models.Authorization) bool {
+			return authorization.Status == models.AuthorizationStatusVoided && authorization.VoidedAt != nil
+		})).Return(nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.Amount(0), nil).Once()
+
+		resp, err := useCase.VoidAuthorization(ctx, code)
+		assert.NoError(t, err)
+		assert.Equal(t, models.AuthorizationStatusVoided, resp.Status)
+		assert.Equal(t, money.MustParse("100.00"), resp.AvailableBalance)
+		mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cannot void a voided authorization", func(t *testing.T) {
+		authorization := newPendingAuthorization("40.00", time.Now().Add(time.Hour))
+		authorization.Status = models.AuthorizationStatusVoided
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetAuthorizationByCode", ctx, code).Return(authorization, nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(newAuthorizableGiftCard(), nil).Once()
+		mockRepo.On("GetAuthorizationByCodeForUpdate", ctx, code).Return(authorization, nil).Once()
+
+		_, err := useCase.VoidAuthorization(ctx, code)
+		assert.ErrorIs(t, err, app.ErrAuthorizationNotPending)
+	})
+}
+
+func TestGiftCardUseCase_MaxUses(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("redemption counts a use and stamps the last use", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		giftCard := newAuthorizableGiftCard()
+		giftCard.MaxUses = 3
+		giftCard.CurrentUses = 2
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, giftCard.GiftCardNumber).Return(giftCard, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-auth", money.MustParse("90.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("RecordGiftCardUse", ctx, "test-code-auth", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-10.00"), money.MustParse("90.00")))).Return(nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusCaptured, money.MustParse("10.00")))).Return(nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, giftCard.GiftCardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00")})
+		assert.NoError(t, err)
+		assert.True(t, resp.IsUsed)
+		assert.Equal(t, 3, giftCard.CurrentUses)
+		assert.False(t, giftCard.LastUsedDate.IsZero())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("redemption is rejected once the limit is reached", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		giftCard := newAuthorizableGiftCard()
+		giftCard.MaxUses = 1
+		giftCard.CurrentUses = 1
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, giftCard.GiftCardNumber).Return(giftCard, nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, giftCard.GiftCardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrMaxUsesReached)
+		assert.Equal(t, "Gift card has no uses left.", resp.Message)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+
+	t.Run("authorization is rejected once the limit is reached", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		giftCard := newAuthorizableGiftCard()
+		giftCard.MaxUses = 2
+		giftCard.CurrentUses = 2
+		mockSettings.On("GetSetting", ctx, authorizationExpirySetting).Return(nil, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, giftCard.GiftCardNumber).Return(giftCard, nil).Once()
+
+		_, err := useCase.AuthorizeGiftCardAmount(ctx, giftCard.GiftCardNumber, request.AuthorizeGiftCardRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrMaxUsesReached)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/shared/money"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// campaignSchedulerIntervalSetting is the models.Setting holding how many
+	// minutes pass between two runs of the campaign scheduler.
+	campaignSchedulerIntervalSetting = "campaign_scheduler.interval_minutes"
+	// campaignExpireCardsSetting is the models.Setting that, when true, expires
+	// the promotional cards of a campaign when it ends.
+	campaignExpireCardsSetting = "campaign.expire_cards_on_end"
+
+	defaultCampaignSchedulerInterval = 5 * time.Minute
+
+	// campaignExpiryBatchSize is how many promotional cards of an ended
+	// campaign are expired in one transaction.
+	campaignExpiryBatchSize = 200
+	// campaignSchedulerLockKey identifies the Postgres advisory lock held by
+	// the replica currently applying campaign transitions.
+	campaignSchedulerLockKey int64 = 0x67636361 // "gcca"
+)
+
+// campaignStatus derives the lifecycle state of campaign at now. A campaign
+// runs from the start of its start date through the whole of its end date
+// while enabled; disabling it pauses it, except that a campaign past its end
+// date has ended either way.
+func campaignStatus(campaign *models.Campaign, now time.Time) string {
+	switch {
+	case !now.Before(campaign.EndDate.AddDate(0, 0, 1)):
+		return models.CampaignStatusEnded
+	case !campaign.IsEnabled:
+		return models.CampaignStatusPaused
+	case now.Before(campaign.StartDate):
+		return models.CampaignStatusScheduled
+	default:
+		return models.CampaignStatusRunning
+	}
+}
+
+// StartCampaignScheduler applies campaign transitions in the background, once
+// right away and then every configured interval, until ctx is cancelled.
+func (g *GiftCardUseCase) StartCampaignScheduler(ctx context.Context) {
+	g.background(func() {
+		log := logrus.WithContext(ctx)
+		for {
+			if _, err := g.runCampaignScheduler(ctx); err != nil {
+				log.Errorf("Campaign scheduler failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(g.campaignSchedulerInterval(ctx)):
+			}
+		}
+	})
+}
+
+// runCampaignScheduler stores the derived status of every campaign whose
+// stored status is out of date, and returns how many campaigns moved. Each
+// campaign moves in its own transaction holding the scheduler advisory lock,
+// so while several replicas run the scheduler only one of them applies a
+// transition. Campaigns with promotional cards still to expire then have them
+// expired. A failure stops the run, keeping what was done before it.
+func (g *GiftCardUseCase) runCampaignScheduler(ctx context.Context) (int, error) {
+	log := logrus.WithContext(ctx)
+
+	campaigns, err := g.campaignRepo.ListCampaigns(ctx)
+	if err != nil {
+		log.Errorf("Error listing campaigns: %v", err)
+		return 0, err
+	}
+
+	moved := 0
+	for i := range campaigns {
+		pending := campaigns[i].CardsExpiryPending
+		if campaignStatus(campaigns[i], time.Now()) == campaigns[i].Status {
+			if pending {
+				skipped, err := g.expireCampaignGiftCards(ctx, campaigns[i].ID)
+				if err != nil {
+					log.Errorf("Error expiring gift cards of campaign %d: %v", campaigns[i].ID, err)
+					return moved, err
+				}
+				if skipped {
+					log.Info("Campaign scheduler skipped, another replica holds the lock")
+					return moved, nil
+				}
+			}
+			continue
+		}
+
+		skipped := false
+		err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			ok, err := g.transactor.TryAdvisoryLock(ctx, campaignSchedulerLockKey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				skipped = true
+				return nil
+			}
+
+			// The campaign is read again under lock, as it may have been
+			// updated or moved since it was listed
+			campaign, err := g.campaignRepo.GetCampaignByIDForUpdate(ctx, campaigns[i].ID)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			status := campaignStatus(campaign, now)
+			if status != campaign.Status {
+				if err := g.moveCampaign(ctx, campaign, status, now); err != nil {
+					return err
+				}
+				moved++
+			}
+			pending = campaign.CardsExpiryPending
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Error moving campaign %d: %v", campaigns[i].ID, err)
+			return moved, err
+		}
+		if !skipped && pending {
+			skipped, err = g.expireCampaignGiftCards(ctx, campaigns[i].ID)
+			if err != nil {
+				log.Errorf("Error expiring gift cards of campaign %d: %v", campaigns[i].ID, err)
+				return moved, err
+			}
+		}
+		if skipped {
+			log.Info("Campaign scheduler skipped, another replica holds the lock")
+			return moved, nil
+		}
+	}
+
+	if moved > 0 {
+		log.Infof("Campaign scheduler moved %d campaigns", moved)
+	}
+	return moved, nil
+}
+
+// moveCampaign stores status on a locked campaign. A campaign that ends has
+// its open issuance jobs cancelled in the surrounding transaction and, when
+// configured, its promotional cards marked to be expired afterwards. A
+// campaign whose end date is moved later runs again, but what was closed
+// when it ended is not reopened.
+func (g *GiftCardUseCase) moveCampaign(ctx context.Context, campaign *models.Campaign, status string, now time.Time) error {
+	log := logrus.WithContext(ctx).WithField("campaign", campaign.ID)
+
+	var endedAt *time.Time
+	if status == models.CampaignStatusEnded {
+		endedAt = &now
+	}
+	if err := g.campaignRepo.UpdateCampaignStatus(ctx, campaign.ID, status, endedAt); err != nil {
+		return err
+	}
+	log.Infof("Campaign moved from %s to %s", campaign.Status, status)
+	if status != models.CampaignStatusEnded {
+		return nil
+	}
+
+	if err := g.cancelCampaignIssuanceJobs(ctx, campaign.ID, now); err != nil {
+		return err
+	}
+	expireCards, err := boolSetting(ctx, g.settingRepo, campaignExpireCardsSetting, false)
+	if err != nil {
+		log.Errorf("Error reading %s: %v", campaignExpireCardsSetting, err)
+		return err
+	}
+	if !expireCards {
+		return nil
+	}
+	if err := g.campaignRepo.SetCampaignCardsExpiryPending(ctx, campaign.ID, true); err != nil {
+		return err
+	}
+	campaign.CardsExpiryPending = true
+	return nil
+}
+
+// cancelCampaignIssuanceJobs closes the issuance jobs of an ended campaign
+// that still have cards to issue, and gives the unissued cards back to the
+// campaign budget. A job running elsewhere stops before its next batch.
+func (g *GiftCardUseCase) cancelCampaignIssuanceJobs(ctx context.Context, campaignID uint, now time.Time) error {
+	log := logrus.WithContext(ctx).WithField("campaign", campaignID)
+
+	jobs, err := g.giftCardRepo.ListOpenIssuanceJobsForUpdate(ctx, campaignID)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		unissued := job.Count - job.IssuedCount
+		if unissued <= 0 {
+			continue
+		}
+
+		completedAt := now
+		job.Status = models.IssuanceJobStatusCancelled
+		job.Error = "campaign ended"
+		job.CompletedAt = &completedAt
+		if err := g.giftCardRepo.UpdateIssuanceJob(ctx, job); err != nil {
+			return err
+		}
+		if err := g.campaignRepo.ReleaseCampaignBudget(ctx, campaignID, unissued, job.Denomination*money.Amount(unissued)); err != nil {
+			return err
+		}
+		log.Infof("Issuance job %s cancelled with %d of %d gift cards unissued", job.Code, unissued, job.Count)
+	}
+	return nil
+}
+
+// expireCampaignGiftCards expires the promotional cards of an ended campaign,
+// forfeiting their balance, and then clears its pending card expiry. Each
+// batch is expired in its own transaction holding the scheduler advisory
+// lock, like the expiry sweep, so a large campaign does not keep all of its
+// cards locked at once. It reports whether it stopped because another
+// replica holds the lock; what a stopped or failed run leaves is expired by
+// the next one.
+func (g *GiftCardUseCase) expireCampaignGiftCards(ctx context.Context, campaignID uint) (bool, error) {
+	log := logrus.WithContext(ctx).WithField("campaign", campaignID)
+
+	expired := 0
+	var afterID uint
+	for done := false; !done; {
+		skipped := false
+		err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			ok, err := g.transactor.TryAdvisoryLock(ctx, campaignSchedulerLockKey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				skipped = true
+				return nil
+			}
+
+			giftCards, err := g.giftCardRepo.ListPromotionalGiftCardsForUpdate(ctx, campaignID, expirableStatuses, afterID, campaignExpiryBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range giftCards {
+				if err := g.expireGiftCard(ctx, &giftCards[i], "campaign ended"); err != nil {
+					log.Errorf("Error expiring gift card %s: %v", giftCards[i].GiftCardNumber, err)
+					return err
+				}
+				afterID = giftCards[i].ID
+			}
+			if len(giftCards) < campaignExpiryBatchSize {
+				done = true
+				if err := g.campaignRepo.SetCampaignCardsExpiryPending(ctx, campaignID, false); err != nil {
+					return err
+				}
+			}
+			expired += len(giftCards)
+			return nil
+		})
+		if err != nil {
+			return false, err
+		}
+		if skipped {
+			return true, nil
+		}
+	}
+
+	log.Infof("Expired %d promotional gift cards of the ended campaign", expired)
+	return false, nil
+}
+
+// campaignSchedulerInterval reads the time between scheduler runs, falling
+// back to defaultCampaignSchedulerInterval when it is not configured or
+// cannot be read.
+func (g *GiftCardUseCase) campaignSchedulerInterval(ctx context.Context) time.Duration {
+	minutes, ok, err := intSetting(ctx, g.settingRepo, campaignSchedulerIntervalSetting)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Error reading campaign scheduler interval: %v", err)
+		return defaultCampaignSchedulerInterval
+	}
+	if !ok || minutes <= 0 {
+		return defaultCampaignSchedulerInterval
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/shared/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCampaignStatus(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	campaign := func(enabled bool, start, end string) *models.Campaign {
+		startDate, _ := time.Parse("2006-01-02", start)
+		endDate, _ := time.Parse("2006-01-02", end)
+		return &models.Campaign{IsEnabled: enabled, StartDate: startDate, EndDate: endDate}
+	}
+
+	tests := []struct {
+		name     string
+		campaign *models.Campaign
+		expected string
+	}{
+		{name: "before the start date", campaign: campaign(true, "2026-06-16", "2026-06-30"), expected: models.CampaignStatusScheduled},
+		{name: "between the dates", campaign: campaign(true, "2026-06-01", "2026-06-30"), expected: models.CampaignStatusRunning},
+		{name: "on the end date", campaign: campaign(true, "2026-06-01", "2026-06-15"), expected: models.CampaignStatusRunning},
+		{name: "disabled between the dates", campaign: campaign(false, "2026-06-01", "2026-06-30"), expected: models.CampaignStatusPaused},
+		{name: "disabled before the start date", campaign: campaign(false, "2026-06-16", "2026-06-30"), expected: models.CampaignStatusPaused},
+		{name: "after the end date", campaign: campaign(true, "2026-05-01", "2026-06-14"), expected: models.CampaignStatusEnded},
+		{name: "disabled after the end date", campaign: campaign(false, "2026-05-01", "2026-06-14"), expected: models.CampaignStatusEnded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, campaignStatus(tt.campaign, now))
+		})
+	}
+}
+
+func TestGiftCardUseCase_RunCampaignScheduler(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().Truncate(24 * time.Hour)
+	ended := func() models.Campaign {
+		return models.Campaign{ID: 12, IsEnabled: true, Status: models.CampaignStatusRunning, StartDate: today.AddDate(0, -1, 0), EndDate: today.AddDate(0, 0, -2)}
+	}
+	running := models.Campaign{ID: 13, IsEnabled: true, Status: models.CampaignStatusRunning, StartDate: today.AddDate(0, -1, 0), EndDate: today.AddDate(0, 1, 0)}
+
+	t.Run("starts scheduled campaigns and leaves current ones alone", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		started := running
+		started.ID = 14
+		started.Status = models.CampaignStatusScheduled
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&running, &started}, nil).Once()
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(14)).Return(&started, nil).Once()
+		campaignRepo.On("UpdateCampaignStatus", ctx, uint(14), models.CampaignStatusRunning, (*time.Time)(nil)).Return(nil).Once()
+
+		moved, err := useCase.runCampaignScheduler(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, moved)
+		mockRepo.AssertExpectations(t)
+		campaignRepo.AssertExpectations(t)
+		campaignRepo.AssertNotCalled(t, "GetCampaignByIDForUpdate", ctx, uint(13))
+	})
+
+	t.Run("ending a campaign cancels its open issuance jobs", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaign := ended()
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&campaign}, nil).Once()
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(&campaign, nil).Once()
+		campaignRepo.On("UpdateCampaignStatus", ctx, uint(12), models.CampaignStatusEnded, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+		mockRepo.On("ListOpenIssuanceJobsForUpdate", ctx, uint(12)).Return([]models.IssuanceJob{
+			{Code: "job-1", Count: 10, IssuedCount: 4, Denomination: money.MustParse("25.00"), Status: models.IssuanceJobStatusRunning},
+			{Code: "job-2", Count: 5, IssuedCount: 5, Denomination: money.MustParse("25.00"), Status: models.IssuanceJobStatusRunning},
+		}, nil).Once()
+		mockRepo.On("UpdateIssuanceJob", ctx, mock.MatchedBy(func(job *models.IssuanceJob) bool {
+			return job.Code == "job-1" && job.Status == models.IssuanceJobStatusCancelled && job.CompletedAt != nil
+		})).Return(nil).Once()
+		campaignRepo.On("ReleaseCampaignBudget", ctx, uint(12), 6, money.MustParse("150.00")).Return(nil).Once()
+
+		moved, err := useCase.runCampaignScheduler(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, moved)
+		mockRepo.AssertExpectations(t)
+		campaignRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "ListPromotionalGiftCardsForUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("ending a campaign expires its promotional cards when configured", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, policySettings(map[string]string{campaignExpireCardsSetting: "true"}), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaign := ended()
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&campaign}, nil).Once()
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(&campaign, nil).Once()
+		campaignRepo.On("UpdateCampaignStatus", ctx, uint(12), models.CampaignStatusEnded, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+		mockRepo.On("ListOpenIssuanceJobsForUpdate", ctx, uint(12)).Return([]models.IssuanceJob{}, nil).Once()
+		campaignRepo.On("SetCampaignCardsExpiryPending", ctx, uint(12), true).Return(nil).Once()
+		campaignRepo.On("SetCampaignCardsExpiryPending", ctx, uint(12), false).Return(nil).Once()
+		mockRepo.On("ListPromotionalGiftCardsForUpdate", ctx, uint(12), expirableStatuses, uint(0), campaignExpiryBatchSize).Return([]models.GiftCard{
+			{ID: 1, Code: "code-1", Balance: money.MustParse("10.00"), Currency: "USD", Status: models.GiftCardStatusActive, IsPromotional: true},
+		}, nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "code-1", money.Amount(0), models.GiftCardStatusExpired).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(func(change *models.GiftCardStatusChange) bool {
+			return isStatusChange(models.GiftCardStatusActive, models.GiftCardStatusExpired)(change) && change.Reason == "campaign ended"
+		})).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeExpiryForfeit, money.MustParse("-10.00"), 0))).Return(nil).Once()
+
+		_, err := useCase.runCampaignScheduler(ctx)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		campaignRepo.AssertExpectations(t)
+	})
+
+	t.Run("pending card expiry of an ended campaign runs batch by batch", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaign := ended()
+		campaign.Status = models.CampaignStatusEnded
+		campaign.CardsExpiryPending = true
+		fullBatch := make([]models.GiftCard, campaignExpiryBatchSize)
+		for i := range fullBatch {
+			fullBatch[i] = models.GiftCard{ID: uint(i + 1), Code: "code", Status: models.GiftCardStatusSuspended, IsPromotional: true}
+		}
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&campaign}, nil).Once()
+		mockRepo.On("ListPromotionalGiftCardsForUpdate", ctx, uint(12), expirableStatuses, uint(0), campaignExpiryBatchSize).Return(fullBatch, nil).Once()
+		mockRepo.On("ListPromotionalGiftCardsForUpdate", ctx, uint(12), expirableStatuses, uint(campaignExpiryBatchSize), campaignExpiryBatchSize).Return([]models.GiftCard{}, nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "code", money.Amount(0), models.GiftCardStatusExpired).Return(nil).Times(campaignExpiryBatchSize)
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.Anything).Return(nil).Times(campaignExpiryBatchSize)
+		campaignRepo.On("SetCampaignCardsExpiryPending", ctx, uint(12), false).Return(nil).Once()
+
+		moved, err := useCase.runCampaignScheduler(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, moved)
+		mockRepo.AssertExpectations(t)
+		campaignRepo.AssertExpectations(t)
+		campaignRepo.AssertNotCalled(t, "GetCampaignByIDForUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a failing batch leaves the card expiry pending", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaign := ended()
+		campaign.Status = models.CampaignStatusEnded
+		campaign.CardsExpiryPending = true
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&campaign}, nil).Once()
+		mockRepo.On("ListPromotionalGiftCardsForUpdate", ctx, uint(12), expirableStatuses, uint(0), campaignExpiryBatchSize).Return(nil, assert.AnError).Once()
+
+		_, err := useCase.runCampaignScheduler(ctx)
+		assert.ErrorIs(t, err, assert.AnError)
+		campaignRepo.AssertNotCalled(t, "SetCampaignCardsExpiryPending", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("another replica holds the lock", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), busyTransactor{}).(*GiftCardUseCase)
+		campaign := ended()
+		campaignRepo.On("ListCampaigns", ctx).Return([]*models.Campaign{&campaign}, nil).Once()
+
+		moved, err := useCase.runCampaignScheduler(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, moved)
+		campaignRepo.AssertNotCalled(t, "UpdateCampaignStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/infreaestructure/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Expiry policy settings, each resolved like the number prefix: per campaign,
+// e.g. "expiry.grace_days.campaign.12" = "30", then per card type, e.g.
+// "expiry.grace_days.physical" = "14".
+const (
+	// expiryGraceDaysSetting is how many days a card stays redeemable after
+	// its expiration date.
+	expiryGraceDaysSetting = "expiry.grace_days"
+	// expiryMinValidityDaysSetting is how many days after issue or activation
+	// a card cannot expire, whatever its expiration date.
+	expiryMinValidityDaysSetting = "expiry.min_validity_days"
+	// expiryMaxExtensionDaysSetting is how many days past the original
+	// expiration date a card may be extended to. Unlimited when not configured.
+	expiryMaxExtensionDaysSetting = "expiry.max_extension_days"
+)
+
+// expiryPolicy holds the expiry rules of a card type or campaign.
+type expiryPolicy struct {
+	graceDays        int
+	minValidityDays  int
+	maxExtensionDays int // 0 when extensions are not limited
+}
+
+// expiresAt is when giftCard really expires under the policy: its expiration
+// date, pushed back to the end of the minimum validity, plus the grace period.
+func (p expiryPolicy) expiresAt(giftCard *models.GiftCard) time.Time {
+	expiresAt := giftCard.ExpirationDate
+	if p.minValidityDays > 0 {
+		issuedAt := giftCard.CreatedAt
+		if giftCard.ActivationDate != nil {
+			issuedAt = *giftCard.ActivationDate
+		}
+		if minValidity := issuedAt.AddDate(0, 0, p.minValidityDays); minValidity.After(expiresAt) {
+			expiresAt = minValidity
+		}
+	}
+	return expiresAt.AddDate(0, 0, p.graceDays)
+}
+
+// ExtendGiftCardExpiration moves the expiration date of a card later. The
+// date the card was issued with is kept, and the extension limit of the
+// policy counts from it. Expired cards, whose balance was forfeited, cannot
+// be extended.
+func (g *GiftCardUseCase) ExtendGiftCardExpiration(ctx context.Context, id string, data request.ExtendGiftCardExpirationRequest) (response.GiftCardExpirationResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ExtendGiftCardExpiration use case") // id is the gift card code
+
+	expirationDate, err := time.Parse("2006-01-02", data.ExpirationDate)
+	if err != nil {
+		log.Errorf("Error parsing ExpirationDate: %v", err)
+		return response.GiftCardExpirationResponse{}, err
+	}
+
+	var result response.GiftCardExpirationResponse
+	err = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetGiftCardByCodeForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card with code %s not found: %v", id, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card with code %s: %v", id, err)
+			return err
+		}
+
+		switch giftCard.Status {
+		case models.GiftCardStatusExpired:
+			log.Warnf("Gift card %s has already expired", giftCard.GiftCardNumber)
+			return customerrors.ErrGiftCardExpired
+		case models.GiftCardStatusCancelled:
+			log.Warnf("Gift card %s is cancelled", giftCard.GiftCardNumber)
+			return customerrors.ErrGiftCardNotActive
+		}
+		policy, err := g.expiryPolicy(ctx, giftCard.Type, giftCard.CampaignID)
+		if err != nil {
+			log.Errorf("Error reading expiry policy: %v", err)
+			return err
+		}
+		if time.Now().After(policy.expiresAt(giftCard)) {
+			log.Warnf("Gift card %s has expired on %s", giftCard.GiftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"))
+			return customerrors.ErrGiftCardExpired
+		}
+		if !expirationDate.After(giftCard.ExpirationDate) {
+			log.Warnf("Gift card %s expires on %s, not before %s", giftCard.GiftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"), data.ExpirationDate)
+			return customerrors.ErrExpirationNotExtended
+		}
+
+		original := giftCard.ExpirationDate
+		if giftCard.OriginalExpirationDate != nil {
+			original = *giftCard.OriginalExpirationDate
+		}
+		if policy.maxExtensionDays > 0 && expirationDate.After(original.AddDate(0, 0, policy.maxExtensionDays)) {
+			log.Warnf("Gift card %s cannot be extended more than %d days past %s", giftCard.GiftCardNumber, policy.maxExtensionDays, original.Format("2006-01-02"))
+			return customerrors.ErrExtensionLimitExceeded
+		}
+
+		if err := g.giftCardRepo.UpdateGiftCardExpiration(ctx, giftCard.Code, expirationDate, original); err != nil {
+			log.Errorf("Error extending gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+
+		action := fmt.Sprintf("extend expiration of gift card %s from %s to %s by %s", giftCard.GiftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"), data.ExpirationDate, data.ExtendedBy)
+		if data.Reason != "" {
+			action += ": " + data.Reason
+		}
+		if err := g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{Action: action}); err != nil {
+			return err
+		}
+
+		result = response.GiftCardExpirationResponse{
+			GiftCardNumber:         giftCard.GiftCardNumber,
+			ExpirationDate:         data.ExpirationDate,
+			OriginalExpirationDate: original.Format("2006-01-02"),
+		}
+		return nil
+	})
+	if err != nil {
+		return response.GiftCardExpirationResponse{}, err
+	}
+
+	log.Infof("Gift card %s now expires on %s", result.GiftCardNumber, result.ExpirationDate)
+	return result, nil
+}
+
+// pastExpiry reports whether giftCard has expired at now under the expiry
+// policy of its type or campaign. The policy is only read for cards past
+// their expiration date, as it can only extend it.
+func (g *GiftCardUseCase) pastExpiry(ctx context.Context, giftCard *models.GiftCard, now time.Time) (bool, error) {
+	if !now.After(giftCard.ExpirationDate) {
+		return false, nil
+	}
+	policy, err := g.expiryPolicy(ctx, giftCard.Type, giftCard.CampaignID)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Error reading expiry policy: %v", err)
+		return false, err
+	}
+	return now.After(policy.expiresAt(giftCard)), nil
+}
+
+// expiryPolicy resolves each rule of the expiry policy for a card type and
+// campaign: the campaign setting, then the card type setting. The grace
+// period and minimum validity can be set to 0 to turn them off, e.g. for a
+// campaign of a card type that has them; the extension limit cannot, as 0
+// stands for no limit.
+func (g *GiftCardUseCase) expiryPolicy(ctx context.Context, cardType string, campaignID *uint) (expiryPolicy, error) {
+	var policy expiryPolicy
+	rules := []struct {
+		setting string
+		value   *int
+		read    func(context.Context, repository.ISettingRepository, string) (int, bool, error)
+	}{
+		{expiryGraceDaysSetting, &policy.graceDays, nonNegativeIntSetting},
+		{expiryMinValidityDaysSetting, &policy.minValidityDays, nonNegativeIntSetting},
+		{expiryMaxExtensionDaysSetting, &policy.maxExtensionDays, intSetting},
+	}
+
+	for _, rule := range rules {
+		names := []string{fmt.Sprintf("%s.%s", rule.setting, cardType)}
+		if campaignID != nil {
+			names = append([]string{fmt.Sprintf("%s.campaign.%d", rule.setting, *campaignID)}, names...)
+		}
+		for _, name := range names {
+			value, ok, err := rule.read(ctx, g.settingRepo, name)
+			if err != nil {
+				return expiryPolicy{}, err
+			}
+			if ok {
+				*rule.value = value
+				break
+			}
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// policySettings configures the named settings and answers the others as not configured.
+func policySettings(values map[string]string) *MockSettingRepository {
+	mockSettings := new(MockSettingRepository)
+	for name, value := range values {
+		mockSettings.On("GetSetting", mock.Anything, name).Return(&models.Setting{Name: name, Value: value}, nil)
+	}
+	mockSettings.On("GetSetting", mock.Anything, mock.Anything).Return(nil, nil)
+	return mockSettings
+}
+
+func TestGiftCardUseCase_PastExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	campaignID := uint(12)
+
+	tests := []struct {
+		name     string
+		settings map[string]string
+		giftCard models.GiftCard
+		expected bool
+	}{
+		{
+			name:     "before the expiration date",
+			giftCard: models.GiftCard{Type: "virtual", ExpirationDate: now.AddDate(0, 0, 1)},
+			expected: false,
+		},
+		{
+			name:     "past the expiration date without a policy",
+			giftCard: models.GiftCard{Type: "virtual", ExpirationDate: now.AddDate(0, 0, -1)},
+			expected: true,
+		},
+		{
+			name:     "within the grace period of the card type",
+			settings: map[string]string{"expiry.grace_days.virtual": "7"},
+			giftCard: models.GiftCard{Type: "virtual", ExpirationDate: now.AddDate(0, 0, -3)},
+			expected: false,
+		},
+		{
+			name:     "past the grace period",
+			settings: map[string]string{"expiry.grace_days.virtual": "7"},
+			giftCard: models.GiftCard{Type: "virtual", ExpirationDate: now.AddDate(0, 0, -8)},
+			expected: true,
+		},
+		{
+			name:     "campaign policy takes precedence over the card type",
+			settings: map[string]string{"expiry.grace_days.virtual": "7", "expiry.grace_days.campaign.12": "1"},
+			giftCard: models.GiftCard{Type: "virtual", CampaignID: &campaignID, ExpirationDate: now.AddDate(0, 0, -3)},
+			expected: true,
+		},
+		{
+			name:     "campaign turns the grace period of the card type off",
+			settings: map[string]string{"expiry.grace_days.virtual": "7", "expiry.grace_days.campaign.12": "0"},
+			giftCard: models.GiftCard{Type: "virtual", CampaignID: &campaignID, ExpirationDate: now.AddDate(0, 0, -1)},
+			expected: true,
+		},
+		{
+			name:     "card type turns the minimum validity off",
+			settings: map[string]string{"expiry.min_validity_days.physical": "0"},
+			giftCard: models.GiftCard{Type: "physical", CreatedAt: now.AddDate(0, 0, -10), ExpirationDate: now.AddDate(0, 0, -1)},
+			expected: true,
+		},
+		{
+			name:     "within the minimum validity after activation",
+			settings: map[string]string{"expiry.min_validity_days.physical": "30"},
+			giftCard: models.GiftCard{Type: "physical", CreatedAt: now.AddDate(0, -6, 0), ActivationDate: timePtr(now.AddDate(0, 0, -10)), ExpirationDate: now.AddDate(0, 0, -1)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := NewGiftCardUseCase(new(MockGiftCardRepository), new(MockCampaignRepository), policySettings(tt.settings), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+			giftCard := tt.giftCard
+
+			expired, err := useCase.pastExpiry(ctx, &giftCard, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, expired)
+		})
+	}
+}
+
+func TestGiftCardUseCase_ExtendGiftCardExpiration(t *testing.T) {
+	ctx := context.Background()
+	nextMonth := time.Now().AddDate(0, 1, 0)
+	expirationDate := time.Date(nextMonth.Year(), nextMonth.Month(), nextMonth.Day(), 0, 0, 0, 0, time.UTC)
+	card := func(original *time.Time) *models.GiftCard {
+		return &models.GiftCard{ID: 7, Code: "test-code-exp", GiftCardNumber: "GC12345678901237", Type: "virtual", Status: models.GiftCardStatusActive, ExpirationDate: expirationDate, OriginalExpirationDate: original}
+	}
+	extendTo := func(days int) request.ExtendGiftCardExpirationRequest {
+		return request.ExtendGiftCardExpirationRequest{ExpirationDate: expirationDate.AddDate(0, 0, days).Format("2006-01-02"), ExtendedBy: "agent-7", Reason: "customer request"}
+	}
+
+	t.Run("first extension keeps the original expiration date", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{"expiry.max_extension_days.virtual": "90"}), mockAudit, fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-exp").Return(card(nil), nil).Once()
+		mockRepo.On("UpdateGiftCardExpiration", ctx, "test-code-exp", expirationDate.AddDate(0, 0, 30), expirationDate).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasPrefix(auditLog.Action, "extend expiration of gift card GC12345678901237 from "+expirationDate.Format("2006-01-02")) &&
+				strings.HasSuffix(auditLog.Action, "by agent-7: customer request")
+		})).Return(nil).Once()
+
+		resp, err := useCase.ExtendGiftCardExpiration(ctx, "test-code-exp", extendTo(30))
+		assert.NoError(t, err)
+		assert.Equal(t, expirationDate.Format("2006-01-02"), resp.OriginalExpirationDate)
+		assert.Equal(t, expirationDate.AddDate(0, 0, 30).Format("2006-01-02"), resp.ExpirationDate)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("limit counts from the original expiration date", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{"expiry.max_extension_days.virtual": "90"}), new(MockAuditLogRepository), fakeTransactor{})
+		original := expirationDate.AddDate(0, 0, -60)
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-exp").Return(card(&original), nil).Once()
+
+		_, err := useCase.ExtendGiftCardExpiration(ctx, "test-code-exp", extendTo(31))
+		assert.ErrorIs(t, err, app.ErrExtensionLimitExceeded)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardExpiration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("new date must be later", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-exp").Return(card(nil), nil).Once()
+
+		_, err := useCase.ExtendGiftCardExpiration(ctx, "test-code-exp", extendTo(0))
+		assert.ErrorIs(t, err, app.ErrExpirationNotExtended)
+	})
+
+	t.Run("expired cards cannot be extended", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		expired := card(nil)
+		expired.Status = models.GiftCardStatusExpired
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-exp").Return(expired, nil).Once()
+
+		_, err := useCase.ExtendGiftCardExpiration(ctx, "test-code-exp", extendTo(30))
+		assert.ErrorIs(t, err, app.ErrGiftCardExpired)
+	})
+}
+
+func TestGiftCardUseCase_RunExpirySweep_GracePeriod(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{"expiry.grace_days.physical": "14"}), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+	mockRepo.On("ListExpiredGiftCardsForUpdate", ctx, mock.AnythingOfType("time.Time"), expirableStatuses, uint(0), expirySweepBatchSize).Return([]models.GiftCard{
+		{ID: 3, Code: "in-grace", Type: "physical", Status: models.GiftCardStatusActive, ExpirationDate: time.Now().AddDate(0, 0, -2)},
+		{ID: 4, Code: "lapsed", Type: "virtual", Status: models.GiftCardStatusActive, ExpirationDate: time.Now().AddDate(0, 0, -2)},
+	}, nil).Once()
+	mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "lapsed", mock.Anything, models.GiftCardStatusExpired).Return(nil).Once()
+	mockRepo.On("CreateGiftCardStatusChange", ctx, mock.Anything).Return(nil).Once()
+	mockRepo.On("CreateExpirySweepRun", ctx, mock.MatchedBy(func(run *models.ExpirySweepRun) bool {
+		return run.ExpiredCount == 1
+	})).Return(nil).Once()
+
+	_, err := useCase.runExpirySweep(ctx)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", ctx, "in-grace", mock.Anything, mock.Anything)
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/response"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// expirySweepIntervalSetting is the models.Setting holding how many
+	// minutes pass between two runs of the expiry sweeper.
+	expirySweepIntervalSetting = "expiry_sweep.interval_minutes"
+
+	defaultExpirySweepInterval = 60 * time.Minute
+
+	// expirySweepBatchSize is how many cards are checked per transaction.
+	expirySweepBatchSize = 200
+	// expirySweepLockKey identifies the Postgres advisory lock held by the
+	// replica currently sweeping.
+	expirySweepLockKey int64 = 0x67636578 // "gcex"
+)
+
+// expirableStatuses are the statuses giftCardTransitions allows to move to
+// expired. Issued cards never activated are left as they are.
+var expirableStatuses = []models.GiftCardStatus{
+	models.GiftCardStatusActive,
+	models.GiftCardStatusSuspended,
+	models.GiftCardStatusUsed,
+}
+
+// StartExpirySweeper runs the expiry sweep in the background, once right away
+// and then every configured interval, until ctx is cancelled.
+func (g *GiftCardUseCase) StartExpirySweeper(ctx context.Context) {
+	g.background(func() {
+		log := logrus.WithContext(ctx)
+		for {
+			if _, err := g.runExpirySweep(ctx); err != nil {
+				log.Errorf("Expiry sweep failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(g.expirySweepInterval(ctx)):
+			}
+		}
+	})
+}
+
+// GetExpirySweepStatus reports the sweeper interval and its last recorded run.
+func (g *GiftCardUseCase) GetExpirySweepStatus(ctx context.Context) (response.ExpirySweepStatusResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("GetExpirySweepStatus use case")
+
+	result := response.ExpirySweepStatusResponse{IntervalMinutes: int(g.expirySweepInterval(ctx) / time.Minute)}
+	run, err := g.giftCardRepo.GetLastExpirySweepRun(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, nil
+		}
+		log.Errorf("Error retrieving last expiry sweep run: %v", err)
+		return response.ExpirySweepStatusResponse{}, err
+	}
+
+	result.LastRun = &response.ExpirySweepRunResponse{
+		StartedAt:    run.StartedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt:   run.FinishedAt.Format("2006-01-02 15:04:05"),
+		ExpiredCount: run.ExpiredCount,
+		Status:       run.Status,
+		Error:        run.Error,
+	}
+	return result, nil
+}
+
+// runExpirySweep expires every card past its expiration date, including the
+// grace period of its expiry policy, forfeiting its balance as redemption
+// would. Cards are checked in batches of expirySweepBatchSize. Each batch
+// takes the sweep advisory lock, so while several replicas run the sweeper
+// only one of them works at a time; a replica that does not get the lock
+// stops and records nothing, returning a nil run. A failed batch stops the
+// run, keeping the batches committed before it.
+func (g *GiftCardUseCase) runExpirySweep(ctx context.Context) (*models.ExpirySweepRun, error) {
+	log := logrus.WithContext(ctx)
+
+	run := &models.ExpirySweepRun{StartedAt: time.Now()}
+	locked := false
+	// Policies are read once per card type and campaign
+	policies := make(map[string]expiryPolicy)
+	var afterID uint
+	var sweepErr error
+	for {
+		var expired int
+		var lastID uint
+		done := false
+		sweepErr = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+			ok, err := g.transactor.TryAdvisoryLock(ctx, expirySweepLockKey)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				done = true
+				return nil
+			}
+			locked = true
+
+			now := time.Now()
+			giftCards, err := g.giftCardRepo.ListExpiredGiftCardsForUpdate(ctx, now, expirableStatuses, afterID, expirySweepBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range giftCards {
+				giftCard := &giftCards[i]
+				lastID = giftCard.ID
+
+				key := giftCard.Type
+				if giftCard.CampaignID != nil {
+					key = fmt.Sprintf("%s/%d", giftCard.Type, *giftCard.CampaignID)
+				}
+				policy, ok := policies[key]
+				if !ok {
+					policy, err = g.expiryPolicy(ctx, giftCard.Type, giftCard.CampaignID)
+					if err != nil {
+						log.Errorf("Error reading expiry policy: %v", err)
+						return err
+					}
+					policies[key] = policy
+				}
+				if !now.After(policy.expiresAt(giftCard)) {
+					continue
+				}
+
+				if err := g.expireGiftCard(ctx, giftCard, "expiration date passed"); err != nil {
+					log.Errorf("Error expiring gift card %s: %v", giftCard.GiftCardNumber, err)
+					return err
+				}
+				expired++
+			}
+			done = len(giftCards) < expirySweepBatchSize
+			return nil
+		})
+		if sweepErr != nil {
+			break
+		}
+		afterID = lastID
+		run.ExpiredCount += expired
+		if done {
+			break
+		}
+	}
+
+	if !locked {
+		if sweepErr != nil {
+			return nil, sweepErr
+		}
+		log.Info("Expiry sweep skipped, another replica holds the lock")
+		return nil, nil
+	}
+
+	run.FinishedAt = time.Now()
+	run.Status = models.ExpirySweepStatusCompleted
+	if sweepErr != nil {
+		run.Status = models.ExpirySweepStatusFailed
+		run.Error = sweepErr.Error()
+	}
+	if err := g.giftCardRepo.CreateExpirySweepRun(ctx, run); err != nil {
+		log.Errorf("Error recording expiry sweep run: %v", err)
+		return run, err
+	}
+	if sweepErr != nil {
+		return run, sweepErr
+	}
+
+	log.Infof("Expiry sweep expired %d gift cards", run.ExpiredCount)
+	return run, nil
+}
+
+// expirySweepInterval reads the time between sweeps, falling back to
+// defaultExpirySweepInterval when it is not configured or cannot be read.
+func (g *GiftCardUseCase) expirySweepInterval(ctx context.Context) time.Duration {
+	minutes, ok, err := intSetting(ctx, g.settingRepo, expirySweepIntervalSetting)
+	if err != nil {
+		logrus.WithContext(ctx).Errorf("Error reading expiry sweep interval: %v", err)
+		return defaultExpirySweepInterval
+	}
+	if !ok || minutes <= 0 {
+		return defaultExpirySweepInterval
+	}
+	return time.Duration(minutes) * time.Minute
+}
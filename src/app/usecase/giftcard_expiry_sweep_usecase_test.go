@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// busyTransactor never gets an advisory lock, as if another replica held it.
+type busyTransactor struct{ fakeTransactor }
+
+func (busyTransactor) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	return false, nil
+}
+
+func TestGiftCardUseCase_RunExpirySweep(t *testing.T) {
+	ctx := context.Background()
+	expiredCards := func() []models.GiftCard {
+		return []models.GiftCard{
+			{ID: 1, Code: "code-1", GiftCardNumber: "GC12345678901237", Balance: money.MustParse("30.00"), Currency: "USD", Status: models.GiftCardStatusActive, ExpirationDate: time.Now().AddDate(0, 0, -1)},
+			{ID: 2, Code: "code-2", GiftCardNumber: "GC99999999999902", Balance: 0, Currency: "USD", Status: models.GiftCardStatusUsed, ExpirationDate: time.Now().AddDate(0, -1, 0)},
+		}
+	}
+
+	t.Run("expires cards and forfeits their balance", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		mockRepo.On("ListExpiredGiftCardsForUpdate", ctx, mock.AnythingOfType("time.Time"), expirableStatuses, uint(0), expirySweepBatchSize).Return(expiredCards(), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "code-1", money.Amount(0), models.GiftCardStatusExpired).Return(nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "code-2", money.Amount(0), models.GiftCardStatusExpired).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(models.GiftCardStatusActive, models.GiftCardStatusExpired))).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(models.GiftCardStatusUsed, models.GiftCardStatusExpired))).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeExpiryForfeit, money.MustParse("-30.00"), 0))).Return(nil).Once()
+		mockRepo.On("CreateExpirySweepRun", ctx, mock.MatchedBy(func(run *models.ExpirySweepRun) bool {
+			return run.Status == models.ExpirySweepStatusCompleted && run.ExpiredCount == 2 && !run.FinishedAt.Before(run.StartedAt)
+		})).Return(nil).Once()
+
+		run, err := useCase.runExpirySweep(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, run.ExpiredCount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("full batches are followed by another one", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		fullBatch := make([]models.GiftCard, expirySweepBatchSize)
+		for i := range fullBatch {
+			fullBatch[i] = models.GiftCard{ID: uint(i + 1), Code: "code", Status: models.GiftCardStatusSuspended}
+		}
+		mockRepo.On("ListExpiredGiftCardsForUpdate", ctx, mock.AnythingOfType("time.Time"), expirableStatuses, uint(0), expirySweepBatchSize).Return(fullBatch, nil).Once()
+		mockRepo.On("ListExpiredGiftCardsForUpdate", ctx, mock.AnythingOfType("time.Time"), expirableStatuses, uint(expirySweepBatchSize), expirySweepBatchSize).Return([]models.GiftCard{}, nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "code", money.Amount(0), models.GiftCardStatusExpired).Return(nil).Times(expirySweepBatchSize)
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.Anything).Return(nil).Times(expirySweepBatchSize)
+		mockRepo.On("CreateExpirySweepRun", ctx, mock.Anything).Return(nil).Once()
+
+		run, err := useCase.runExpirySweep(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, expirySweepBatchSize, run.ExpiredCount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("another replica holds the lock", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), busyTransactor{}).(*GiftCardUseCase)
+
+		run, err := useCase.runExpirySweep(ctx)
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+		mockRepo.AssertNotCalled(t, "ListExpiredGiftCardsForUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateExpirySweepRun", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a failing batch is recorded", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		mockRepo.On("ListExpiredGiftCardsForUpdate", ctx, mock.AnythingOfType("time.Time"), expirableStatuses, uint(0), expirySweepBatchSize).Return(nil, errors.New("db error")).Once()
+		mockRepo.On("CreateExpirySweepRun", ctx, mock.MatchedBy(func(run *models.ExpirySweepRun) bool {
+			return run.Status == models.ExpirySweepStatusFailed && run.Error == "db error"
+		})).Return(nil).Once()
+
+		_, err := useCase.runExpirySweep(ctx)
+		assert.EqualError(t, err, "db error")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGiftCardUseCase_GetExpirySweepStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("before the first run", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, expirySweepIntervalSetting).Return(nil, nil).Once()
+		mockRepo.On("GetLastExpirySweepRun", ctx).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		resp, err := useCase.GetExpirySweepStatus(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, resp.IntervalMinutes)
+		assert.Nil(t, resp.LastRun)
+	})
+
+	t.Run("reports the last run", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		startedAt := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+		mockSettings.On("GetSetting", ctx, expirySweepIntervalSetting).Return(&models.Setting{Name: expirySweepIntervalSetting, Value: "15"}, nil).Once()
+		mockRepo.On("GetLastExpirySweepRun", ctx).Return(&models.ExpirySweepRun{StartedAt: startedAt, FinishedAt: startedAt.Add(time.Minute), ExpiredCount: 12, Status: models.ExpirySweepStatusCompleted}, nil).Once()
+
+		resp, err := useCase.GetExpirySweepStatus(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 15, resp.IntervalMinutes)
+		if assert.NotNil(t, resp.LastRun) {
+			assert.Equal(t, "2026-03-01 04:00:00", resp.LastRun.StartedAt)
+			assert.Equal(t, 12, resp.LastRun.ExpiredCount)
+		}
+	})
+}
@@ -0,0 +1,396 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared"
+	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// importBatchSize is how many valid rows are checked and committed together.
+	importBatchSize = 500
+	// importTransactionReference marks the opening ledger entries of imported cards.
+	importTransactionReference = "csv import"
+)
+
+// importColumns are the CSV columns an import file must have.
+var importColumns = []string{"gift_card_number", "type", "balance", "currency", "expiration_date", "status"}
+
+// exportColumns are the CSV columns of the card export; they include the
+// import columns so an export can be imported elsewhere.
+var exportColumns = []string{"code", "gift_card_number", "type", "balance", "currency", "expiration_date", "status", "is_promotional"}
+
+// importedRow is a valid import row waiting for its batch to be flushed.
+type importedRow struct {
+	line     int
+	giftCard models.GiftCard
+	// pin is the PIN the card had at its issuer, hashed when the batch is
+	// created; empty when the file has none for it
+	pin string
+}
+
+// ImportGiftCardsCSV reads gift cards from a CSV file with a header row and
+// reports the rows that cannot be imported. Rows are validated as they are
+// read and checked against existing cards in batches; unless dryRun, the
+// valid rows of each batch are created in one transaction with an opening
+// balance ledger entry. Cards keep the number they were issued with, which
+// only has to be well formed, and are marked as imported so the number is
+// accepted without a check digit of ours. The PIN of a card is taken from an
+// optional pin column; a card imported without one has to get a PIN through
+// ResetGiftCardPin before it can be used.
+//
+// A database error aborts the import, keeping the batches already committed;
+// importing the same file again only reports their cards as existing.
+func (g *GiftCardUseCase) ImportGiftCardsCSV(ctx context.Context, r io.Reader, dryRun bool) (response.GiftCardImportResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ImportGiftCardsCSV use case")
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		log.Errorf("Error reading import header: %v", err)
+		return response.GiftCardImportResponse{}, fmt.Errorf("%w: %v", customerrors.ErrInvalidImportFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			log.Errorf("Import file is missing column %s", name)
+			return response.GiftCardImportResponse{}, fmt.Errorf("%w: missing column %s", customerrors.ErrInvalidImportFile, name)
+		}
+	}
+
+	result := response.GiftCardImportResponse{DryRun: dryRun, Errors: []response.GiftCardImportRowError{}}
+	// seen maps each number to the line it first appeared on
+	seen := make(map[string]int)
+	batch := make([]importedRow, 0, importBatchSize)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Rows++
+			result.Errors = append(result.Errors, response.GiftCardImportRowError{
+				Line:   parseErr.Line,
+				Errors: map[string]string{"row": parseErr.Err.Error()},
+			})
+			continue
+		}
+		if err != nil {
+			log.Errorf("Error reading import file: %v", err)
+			return result, err
+		}
+		result.Rows++
+		line, _ := reader.FieldPos(0)
+
+		giftCard, givenPin, rowErrors := parseImportRow(record, columns)
+		if rowErrors == nil {
+			if firstLine, ok := seen[giftCard.GiftCardNumber]; ok {
+				rowErrors = map[string]string{"giftcardnumber": fmt.Sprintf("Duplicate of line %d", firstLine)}
+			} else {
+				seen[giftCard.GiftCardNumber] = line
+			}
+		}
+		if rowErrors != nil {
+			result.Errors = append(result.Errors, response.GiftCardImportRowError{Line: line, GiftCardNumber: giftCard.GiftCardNumber, Errors: rowErrors})
+			continue
+		}
+
+		batch = append(batch, importedRow{line: line, giftCard: giftCard, pin: givenPin})
+		if len(batch) == importBatchSize {
+			if err := g.flushImportBatch(ctx, batch, dryRun, &result); err != nil {
+				return result, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := g.flushImportBatch(ctx, batch, dryRun, &result); err != nil {
+		return result, err
+	}
+	result.Failed = len(result.Errors)
+
+	if !dryRun && result.Imported > 0 {
+		if err := g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{
+			Action: fmt.Sprintf("import %d gift cards from CSV", result.Imported),
+		}); err != nil {
+			log.Errorf("Error auditing gift card import: %v", err)
+			return result, err
+		}
+	}
+
+	log.Infof("Gift card import read %d rows: %d valid, %d imported, %d failed", result.Rows, result.Valid, result.Imported, result.Failed)
+	return result, nil
+}
+
+// parseImportRow maps a CSV record to a new card and its PIN, returning the
+// problems of the row keyed by field when it is not valid.
+func parseImportRow(record []string, columns map[string]int) (models.GiftCard, string, map[string]string) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rowErrors := make(map[string]string)
+	row := request.ImportGiftCardRow{
+		GiftCardNumber: field("gift_card_number"),
+		Type:           field("type"),
+		Currency:       strings.ToUpper(field("currency")),
+		ExpirationDate: field("expiration_date"),
+		Status:         field("status"),
+		Pin:            field("pin"),
+	}
+	balance, err := money.Parse(field("balance"))
+	if err != nil {
+		rowErrors["balance"] = "This field must be a decimal amount"
+	}
+	row.Balance = balance
+	if value := field("is_promotional"); value != "" {
+		row.IsPromotional, err = strconv.ParseBool(value)
+		if err != nil {
+			rowErrors["ispromotional"] = "This field must be true or false"
+		}
+	}
+
+	for _, validationError := range shared.ValidateStruct(row) {
+		key := strings.ToLower(validationError.Field)
+		if _, ok := rowErrors[key]; !ok {
+			rowErrors[key] = validationError.Error
+		}
+	}
+	if _, ok := rowErrors["giftcardnumber"]; !ok && !generators.WellFormedGiftcardNumber(row.GiftCardNumber) {
+		rowErrors["giftcardnumber"] = "This field must be 8 to 50 uppercase letters and digits"
+	}
+	giftCard := models.GiftCard{GiftCardNumber: row.GiftCardNumber}
+	if len(rowErrors) > 0 {
+		return giftCard, "", rowErrors
+	}
+
+	expirationDate, _ := time.Parse("2006-01-02", row.ExpirationDate)
+	giftCard = models.GiftCard{
+		Code:           uuid.NewString(),
+		GiftCardNumber: row.GiftCardNumber,
+		Type:           row.Type,
+		Balance:        row.Balance,
+		InitialBalance: row.Balance,
+		Currency:       row.Currency,
+		ExpirationDate: expirationDate,
+		Status:         models.GiftCardStatus(row.Status),
+		IsPromotional:  row.IsPromotional,
+		Imported:       true,
+	}
+	return giftCard, row.Pin, nil
+}
+
+// checkGiftCardNumber rejects a number given for a card before the card is
+// looked up. Numbers we issue carry a valid check digit, which catches
+// mistyped ones; other well formed numbers are only accepted when they
+// belong to an imported card.
+func (g *GiftCardUseCase) checkGiftCardNumber(ctx context.Context, giftCardNumber string) error {
+	log := logrus.WithContext(ctx)
+
+	if generators.ValidGiftcardNumber(giftCardNumber) {
+		return nil
+	}
+	if generators.WellFormedGiftcardNumber(giftCardNumber) {
+		imported, err := g.giftCardRepo.ImportedGiftCardNumberExists(ctx, giftCardNumber)
+		if err != nil {
+			log.Errorf("Error checking imported gift card number %s: %v", giftCardNumber, err)
+			return err
+		}
+		if imported {
+			return nil
+		}
+	}
+	log.Warnf("Malformed gift card number %s", giftCardNumber)
+	return customerrors.ErrInvalidGiftCardNumber
+}
+
+// flushImportBatch rejects the rows of batch whose number is already taken
+// and, unless dryRun, creates the others with their opening ledger entries.
+func (g *GiftCardUseCase) flushImportBatch(ctx context.Context, batch []importedRow, dryRun bool, result *response.GiftCardImportResponse) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	log := logrus.WithContext(ctx)
+
+	numbers := make([]string, 0, len(batch))
+	for _, row := range batch {
+		numbers = append(numbers, row.giftCard.GiftCardNumber)
+	}
+	existing, err := g.giftCardRepo.GetGiftCardsByNumbers(ctx, numbers)
+	if err != nil {
+		log.Errorf("Error checking imported gift card numbers: %v", err)
+		return err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, giftCard := range existing {
+		taken[giftCard.GiftCardNumber] = true
+	}
+
+	rows := make([]importedRow, 0, len(batch))
+	for _, row := range batch {
+		if taken[row.giftCard.GiftCardNumber] {
+			result.Errors = append(result.Errors, importExistsError(row))
+			continue
+		}
+		rows = append(rows, row)
+	}
+	result.Valid += len(rows)
+	if dryRun || len(rows) == 0 {
+		return nil
+	}
+	if err := hashImportedPins(rows); err != nil {
+		log.Errorf("Error hashing PINs of imported gift cards: %v", err)
+		return err
+	}
+
+	return g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCards := make([]models.GiftCard, 0, len(rows))
+		numbers := make([]string, 0, len(rows))
+		for _, row := range rows {
+			giftCards = append(giftCards, row.giftCard)
+			numbers = append(numbers, row.giftCard.GiftCardNumber)
+		}
+		if _, err := g.giftCardRepo.CreateGiftCards(ctx, giftCards); err != nil {
+			log.Errorf("Error creating imported gift cards: %v", err)
+			return err
+		}
+
+		// A card created since the check above keeps its number; only the
+		// cards carrying the codes of this batch were inserted by it
+		inserted, err := g.giftCardRepo.GetGiftCardsByNumbers(ctx, numbers)
+		if err != nil {
+			log.Errorf("Error reading imported gift cards: %v", err)
+			return err
+		}
+		byCode := make(map[string]models.GiftCard, len(inserted))
+		for _, giftCard := range inserted {
+			byCode[giftCard.Code] = giftCard
+		}
+
+		transactions := make([]models.Transaction, 0, len(rows))
+		for _, row := range rows {
+			giftCard, ok := byCode[row.giftCard.Code]
+			if !ok {
+				result.Valid--
+				result.Errors = append(result.Errors, importExistsError(row))
+				continue
+			}
+			transactions = append(transactions, models.Transaction{
+				GiftCardID:      giftCard.ID,
+				Amount:          giftCard.Balance,
+				BalanceAfter:    giftCard.Balance,
+				Currency:        giftCard.Currency,
+				TransactionType: models.TransactionTypeCreate,
+				Reference:       importTransactionReference,
+			})
+		}
+		if len(transactions) == 0 {
+			return nil
+		}
+		if err := g.giftCardRepo.CreateTransactions(ctx, transactions); err != nil {
+			log.Errorf("Error creating ledger entries of imported gift cards: %v", err)
+			return err
+		}
+		result.Imported += len(transactions)
+		return nil
+	})
+}
+
+// hashImportedPins sets the PIN hash of the cards of rows that have a PIN.
+func hashImportedPins(rows []importedRow) error {
+	var indexes []int
+	var pins []string
+	for i := range rows {
+		if rows[i].pin != "" {
+			indexes = append(indexes, i)
+			pins = append(pins, rows[i].pin)
+		}
+	}
+	hashes, err := hashPins(pins)
+	if err != nil {
+		return err
+	}
+	for j, i := range indexes {
+		rows[i].giftCard.PinHash = hashes[j]
+	}
+	return nil
+}
+
+func importExistsError(row importedRow) response.GiftCardImportRowError {
+	return response.GiftCardImportRowError{
+		Line:           row.line,
+		GiftCardNumber: row.giftCard.GiftCardNumber,
+		Errors:         map[string]string{"giftcardnumber": "Gift card number already exists"},
+	}
+}
+
+// ExportGiftCardsCSV writes the cards matching filter as CSV, flushing each
+// page so large exports are streamed.
+func (g *GiftCardUseCase) ExportGiftCardsCSV(ctx context.Context, filter request.GiftCardExportFilter, w io.Writer) error {
+	log := logrus.WithContext(ctx)
+	log.Info("ExportGiftCardsCSV use case")
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+	var afterID uint
+	exported := 0
+	for {
+		giftCards, err := g.giftCardRepo.ListGiftCards(ctx, filter, afterID, issuanceExportPageSize)
+		if err != nil {
+			log.Errorf("Error listing gift cards for export: %v", err)
+			return err
+		}
+		for _, giftCard := range giftCards {
+			if err := writer.Write([]string{
+				giftCard.Code,
+				giftCard.GiftCardNumber,
+				giftCard.Type,
+				giftCard.Balance.String(),
+				giftCard.Currency,
+				giftCard.ExpirationDate.Format("2006-01-02"),
+				string(giftCard.Status),
+				strconv.FormatBool(giftCard.IsPromotional),
+			}); err != nil {
+				return err
+			}
+			afterID = giftCard.ID
+		}
+		exported += len(giftCards)
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		if len(giftCards) < issuanceExportPageSize {
+			break
+		}
+	}
+
+	log.Infof("Exported %d gift cards", exported)
+	return nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftCardUseCase_ImportGiftCardsCSV(t *testing.T) {
+	ctx := context.Background()
+	file := strings.Join([]string{
+		"gift_card_number,type,balance,currency,expiration_date,status,is_promotional",
+		"GC12345678901237,virtual,40.00,USD,2030-06-30,active,false",
+		"AB12CD34EF56,physical,15.50,usd,2030-06-30,issued,",
+		"GC-1234-5678-9012,virtual,40.00,USD,2030-06-30,active,false",
+		"GC88888888888800,virtual,abc,USD,30/06/2030,unknown,false",
+		"GC12345678901237,virtual,10.00,USD,2030-06-30,active,false",
+	}, "\n")
+
+	t.Run("dry run reports every invalid row", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardsByNumbers", ctx, []string{"GC12345678901237", "AB12CD34EF56"}).Return([]models.GiftCard{
+			{ID: 1, GiftCardNumber: "AB12CD34EF56"},
+		}, nil).Once()
+
+		resp, err := useCase.ImportGiftCardsCSV(ctx, strings.NewReader(file), true)
+		assert.NoError(t, err)
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, 5, resp.Rows)
+		assert.Equal(t, 1, resp.Valid)
+		assert.Equal(t, 0, resp.Imported)
+		assert.Equal(t, 4, resp.Failed)
+
+		byLine := make(map[int]map[string]string)
+		for _, rowError := range resp.Errors {
+			byLine[rowError.Line] = rowError.Errors
+		}
+		assert.Contains(t, byLine[3]["giftcardnumber"], "already exists")
+		assert.Contains(t, byLine[4]["giftcardnumber"], "uppercase letters and digits")
+		assert.Contains(t, byLine[5], "balance")
+		assert.Contains(t, byLine[5], "expirationdate")
+		assert.Contains(t, byLine[5], "status")
+		assert.Equal(t, "Duplicate of line 2", byLine[6]["giftcardnumber"])
+		mockRepo.AssertNotCalled(t, "CreateGiftCards", mock.Anything, mock.Anything)
+	})
+
+	t.Run("commit creates the valid cards with an opening balance", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		var created []models.GiftCard
+		// Filled in once the cards are created, as they are read back with their codes
+		inserted := make([]models.GiftCard, 2)
+		mockRepo.On("GetGiftCardsByNumbers", ctx, []string{"GC12345678901237", "AB12CD34EF56"}).Return([]models.GiftCard{}, nil).Once()
+		mockRepo.On("CreateGiftCards", ctx, mock.AnythingOfType("[]models.GiftCard")).Run(func(args mock.Arguments) {
+			created = args.Get(1).([]models.GiftCard)
+			for i, giftCard := range created {
+				giftCard.ID = uint(i + 1)
+				inserted[i] = giftCard
+			}
+		}).Return(int64(2), nil).Once()
+		mockRepo.On("GetGiftCardsByNumbers", ctx, []string{"GC12345678901237", "AB12CD34EF56"}).Return(inserted, nil).Once()
+		mockRepo.On("CreateTransactions", ctx, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 2 &&
+				transactions[0].GiftCardID == 1 &&
+				isLedgerEntry(models.TransactionTypeCreate, money.MustParse("40.00"), money.MustParse("40.00"))(&transactions[0]) &&
+				transactions[1].Currency == "USD" &&
+				transactions[1].Amount == money.MustParse("15.50")
+		})).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return auditLog.Action == "import 2 gift cards from CSV"
+		})).Return(nil).Once()
+
+		resp, err := useCase.ImportGiftCardsCSV(ctx, strings.NewReader(file), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Imported)
+		assert.Equal(t, 3, resp.Failed)
+		if assert.Len(t, created, 2) {
+			assert.Equal(t, "GC12345678901237", created[0].GiftCardNumber)
+			assert.True(t, created[0].Imported)
+			// Numbers of other issuers carry no check digit of ours
+			assert.Equal(t, "AB12CD34EF56", created[1].GiftCardNumber)
+			assert.Equal(t, models.GiftCardStatusIssued, created[1].Status)
+			assert.Equal(t, time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC), created[1].ExpirationDate)
+			assert.NotEmpty(t, created[1].Code)
+		}
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("PINs of the file are hashed", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		withPins := strings.Join([]string{
+			"gift_card_number,type,balance,currency,expiration_date,status,pin",
+			"GC12345678901237,virtual,40.00,USD,2030-06-30,active,4821",
+			"AB12CD34EF56,virtual,15.50,USD,2030-06-30,active,",
+			"AB12CD34EF57,virtual,15.50,USD,2030-06-30,active,12ab",
+		}, "\n")
+		var created []models.GiftCard
+		mockRepo.On("GetGiftCardsByNumbers", ctx, []string{"GC12345678901237", "AB12CD34EF56"}).Return([]models.GiftCard{}, nil).Once()
+		mockRepo.On("CreateGiftCards", ctx, mock.AnythingOfType("[]models.GiftCard")).Run(func(args mock.Arguments) {
+			created = args.Get(1).([]models.GiftCard)
+		}).Return(int64(2), nil).Once()
+		mockRepo.On("GetGiftCardsByNumbers", ctx, []string{"GC12345678901237", "AB12CD34EF56"}).Return([]models.GiftCard{}, nil).Once()
+
+		resp, err := useCase.ImportGiftCardsCSV(ctx, strings.NewReader(withPins), false)
+		assert.NoError(t, err)
+		if assert.Len(t, resp.Errors, 3) {
+			assert.Equal(t, 4, resp.Errors[0].Line)
+			assert.Contains(t, resp.Errors[0].Errors, "pin")
+		}
+		if assert.Len(t, created, 2) {
+			ok, err := pin.Verify(created[0].PinHash, "4821")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			// Without a PIN the card waits for one to be reissued
+			assert.Empty(t, created[1].PinHash)
+			assert.True(t, created[1].Imported)
+		}
+	})
+
+	t.Run("file without the required columns", func(t *testing.T) {
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+
+		_, err := useCase.ImportGiftCardsCSV(ctx, strings.NewReader("gift_card_number,balance\nGC12345678901237,10.00\n"), true)
+		assert.ErrorIs(t, err, app.ErrInvalidImportFile)
+	})
+}
+
+func TestGiftCardUseCase_ExportGiftCardsCSV(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+	filter := request.GiftCardExportFilter{Status: "active", Currency: "USD"}
+	mockRepo.On("ListGiftCards", ctx, filter, uint(0), issuanceExportPageSize).Return([]models.GiftCard{
+		{ID: 4, Code: "c4", GiftCardNumber: "GC12345678901237", Type: "virtual", Balance: money.MustParse("40.00"), Currency: "USD", ExpirationDate: time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC), Status: models.GiftCardStatusActive, IsPromotional: true},
+	}, nil).Once()
+
+	var buf bytes.Buffer
+	err := useCase.ExportGiftCardsCSV(ctx, filter, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "code,gift_card_number,type,balance,currency,expiration_date,status,is_promotional\nc4,GC12345678901237,virtual,40.00,USD,2030-06-30,active,true\n", buf.String())
+	mockRepo.AssertExpectations(t)
+}
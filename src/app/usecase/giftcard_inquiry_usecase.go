@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// balanceInquiryRequirePinSetting is the models.Setting telling whether point
+// of sale balance inquiries must give the PIN of cards issued with one.
+// Defaults to true.
+const balanceInquiryRequirePinSetting = "balance_inquiry.require_pin"
+
+// InquireGiftCardBalance answers a point of sale balance inquiry by card
+// number. Unlike GetGiftCardBalance it reads the card without locking it,
+// and locks it only to check a PIN. Every inquiry is recorded as a
+// models.BalanceInquiry, whatever its result.
+func (g *GiftCardUseCase) InquireGiftCardBalance(ctx context.Context, giftCardNumber string, data request.BalanceInquiryRequest) (response.BalanceInquiryResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("InquireGiftCardBalance use case")
+
+	inquiry := &models.BalanceInquiry{GiftCardNumber: giftCardNumber, TerminalID: data.TerminalID}
+	result, err := g.inquireGiftCardBalance(ctx, giftCardNumber, data.Pin, inquiry)
+	inquiry.Result = balanceInquiryResult(err)
+	// The inquiry moves no funds, so failing to record it does not fail it
+	if recordErr := g.giftCardRepo.CreateBalanceInquiry(ctx, inquiry); recordErr != nil {
+		log.Errorf("Error recording balance inquiry on gift card %s: %v", giftCardNumber, recordErr)
+	}
+	if err != nil {
+		return response.BalanceInquiryResponse{}, err
+	}
+
+	log.Infof("Balance inquiry on gift card %s answered", giftCardNumber)
+	return result, nil
+}
+
+// inquireGiftCardBalance looks the card up, checks its PIN when required and
+// reports its available balance. Cards past their expiry are reported as
+// expired with nothing available, even before the sweeper expires them.
+func (g *GiftCardUseCase) inquireGiftCardBalance(ctx context.Context, giftCardNumber string, givenPin string, inquiry *models.BalanceInquiry) (response.BalanceInquiryResponse, error) {
+	log := logrus.WithContext(ctx)
+
+	if err := g.checkGiftCardNumber(ctx, giftCardNumber); err != nil {
+		return response.BalanceInquiryResponse{}, err
+	}
+
+	giftCard, err := g.giftCardRepo.GetByGiftCardNumber(ctx, giftCardNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+			return response.BalanceInquiryResponse{}, customerrors.ErrGiftCardNotFound
+		}
+		log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+		return response.BalanceInquiryResponse{}, err
+	}
+	inquiry.GiftCardID = &giftCard.ID
+
+	// Imported cards without a PIN are turned away by verifyPin until one is reissued
+	if giftCard.PinHash != "" || giftCard.Imported {
+		requirePin, err := boolSetting(ctx, g.settingRepo, balanceInquiryRequirePinSetting, true)
+		if err != nil {
+			log.Errorf("Error reading balance inquiry PIN setting: %v", err)
+			return response.BalanceInquiryResponse{}, err
+		}
+		if requirePin {
+			if err := g.checkInquiryPin(ctx, giftCardNumber, givenPin); err != nil {
+				return response.BalanceInquiryResponse{}, err
+			}
+		}
+	}
+
+	now := time.Now()
+	held, err := g.giftCardRepo.GetHeldAmount(ctx, giftCard.ID, now)
+	if err != nil {
+		log.Errorf("Error retrieving held amount of gift card %s: %v", giftCardNumber, err)
+		return response.BalanceInquiryResponse{}, err
+	}
+	available := giftCard.Balance - held
+	if available < 0 {
+		available = 0
+	}
+
+	status := giftCard.Status
+	expired, err := g.pastExpiry(ctx, giftCard, now)
+	if err != nil {
+		return response.BalanceInquiryResponse{}, err
+	}
+	if expired {
+		status = models.GiftCardStatusExpired
+		available = 0
+	}
+
+	return response.BalanceInquiryResponse{
+		GiftCardNumber: giftCard.GiftCardNumber,
+		Available:      available,
+		Held:           held,
+		Currency:       giftCard.Currency,
+		Status:         string(status),
+		ExpirationDate: giftCard.ExpirationDate.Format("2006-01-02"),
+	}, nil
+}
+
+// checkInquiryPin verifies the PIN given for a balance inquiry on the locked
+// card, committing a wrong attempt before returning its error.
+func (g *GiftCardUseCase) checkInquiryPin(ctx context.Context, giftCardNumber string, givenPin string) error {
+	// pinErr carries a rejection whose side effects must still be committed.
+	var pinErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			logrus.WithContext(ctx).Errorf("Error locking gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		commit, err := g.verifyPin(ctx, giftCard, givenPin)
+		if err != nil && commit {
+			pinErr = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return pinErr
+}
+
+// balanceInquiryResult is the recorded result of an inquiry ending with err.
+func balanceInquiryResult(err error) string {
+	switch {
+	case err == nil:
+		return models.BalanceInquiryResultOK
+	case errors.Is(err, customerrors.ErrInvalidGiftCardNumber):
+		return models.BalanceInquiryResultInvalidNumber
+	case errors.Is(err, customerrors.ErrGiftCardNotFound):
+		return models.BalanceInquiryResultNotFound
+	case errors.Is(err, customerrors.ErrInvalidPin):
+		return models.BalanceInquiryResultInvalidPin
+	case errors.Is(err, customerrors.ErrPinLocked):
+		return models.BalanceInquiryResultPinLocked
+	default:
+		return models.BalanceInquiryResultError
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGiftCardUseCase_InquireGiftCardBalance(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+	pinHash, err := pin.Hash("123456")
+	require.NoError(t, err)
+
+	card := func(pinHash string) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             7,
+			Code:           "test-code-inquiry",
+			GiftCardNumber: cardNumber,
+			Type:           "physical",
+			Balance:        money.MustParse("80.00"),
+			Currency:       "USD",
+			Status:         models.GiftCardStatusActive,
+			ExpirationDate: time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC),
+			PinHash:        pinHash,
+		}
+	}
+	recorded := func(result string, giftCardID *uint) func(*models.BalanceInquiry) bool {
+		return func(inquiry *models.BalanceInquiry) bool {
+			return inquiry.Result == result && inquiry.GiftCardNumber == cardNumber && inquiry.TerminalID == "pos-12" && assert.ObjectsAreEqual(giftCardID, inquiry.GiftCardID)
+		}
+	}
+	cardID := uint(7)
+
+	t.Run("reports the available balance net of holds", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumber", ctx, cardNumber).Return(card(""), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.AnythingOfType("time.Time")).Return(money.MustParse("25.00"), nil).Once()
+		mockRepo.On("CreateBalanceInquiry", ctx, mock.MatchedBy(recorded(models.BalanceInquiryResultOK, &cardID))).Return(nil).Once()
+
+		resp, err := useCase.InquireGiftCardBalance(ctx, cardNumber, request.BalanceInquiryRequest{TerminalID: "pos-12"})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("55.00"), resp.Available)
+		assert.Equal(t, money.MustParse("25.00"), resp.Held)
+		assert.Equal(t, "USD", resp.Currency)
+		assert.Equal(t, "active", resp.Status)
+		assert.Equal(t, "2030-06-30", resp.ExpirationDate)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetByGiftCardNumberForUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("card past its expiry has nothing available", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		lapsed := card("")
+		lapsed.ExpirationDate = time.Now().AddDate(0, 0, -1)
+		mockRepo.On("GetByGiftCardNumber", ctx, cardNumber).Return(lapsed, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.AnythingOfType("time.Time")).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateBalanceInquiry", ctx, mock.Anything).Return(nil).Once()
+
+		resp, err := useCase.InquireGiftCardBalance(ctx, cardNumber, request.BalanceInquiryRequest{TerminalID: "pos-12"})
+		assert.NoError(t, err)
+		assert.Equal(t, "expired", resp.Status)
+		assert.Equal(t, money.Amount(0), resp.Available)
+	})
+
+	t.Run("wrong PIN is counted and recorded", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), mockAudit, fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumber", ctx, cardNumber).Return(card(pinHash), nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(pinHash), nil).Once()
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-inquiry", pinHash, 1, (*time.Time)(nil)).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateBalanceInquiry", ctx, mock.MatchedBy(recorded(models.BalanceInquiryResultInvalidPin, &cardID))).Return(nil).Once()
+
+		_, err := useCase.InquireGiftCardBalance(ctx, cardNumber, request.BalanceInquiryRequest{Pin: "000000", TerminalID: "pos-12"})
+		assert.ErrorIs(t, err, app.ErrInvalidPin)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "GetHeldAmount", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("PIN is not checked when not required", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{balanceInquiryRequirePinSetting: "false"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumber", ctx, cardNumber).Return(card(pinHash), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(7), mock.AnythingOfType("time.Time")).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateBalanceInquiry", ctx, mock.Anything).Return(nil).Once()
+
+		resp, err := useCase.InquireGiftCardBalance(ctx, cardNumber, request.BalanceInquiryRequest{TerminalID: "pos-12"})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("80.00"), resp.Available)
+		mockRepo.AssertNotCalled(t, "GetByGiftCardNumberForUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown cards are recorded without a card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumber", ctx, cardNumber).Return(nil, gorm.ErrRecordNotFound).Once()
+		mockRepo.On("CreateBalanceInquiry", ctx, mock.MatchedBy(recorded(models.BalanceInquiryResultNotFound, nil))).Return(nil).Once()
+
+		_, err := useCase.InquireGiftCardBalance(ctx, cardNumber, request.BalanceInquiryRequest{TerminalID: "pos-12"})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
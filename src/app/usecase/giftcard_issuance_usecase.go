@@ -0,0 +1,591 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// issuanceBatchSize is how many cards are numbered, inserted and committed together.
+	issuanceBatchSize = 500
+	// issuanceMaxAttempts bounds the retries of a batch whose numbers collide with existing cards.
+	issuanceMaxAttempts = 10
+	// issuanceExportPageSize is how many cards are read at a time for the CSV export.
+	issuanceExportPageSize = 1000
+	// issuanceJobStaleAfter is how long an unfinished job can go without
+	// progress before it is taken as abandoned by a replica that stopped.
+	// It is also how often abandoned jobs are looked for.
+	issuanceJobStaleAfter = 10 * time.Minute
+	// issuanceJobPinTTL is how long the PINs of a finished job are kept for
+	// its export. Unexported PINs are purged afterwards, with the abandoned
+	// jobs, so no plain PIN outlives it for long.
+	issuanceJobPinTTL = time.Hour
+)
+
+var (
+	// errIssuanceJobStopped stops a job that was cancelled, because its
+	// campaign ended, or failed by recoverIssuanceJobs while it ran.
+	errIssuanceJobStopped = errors.New("issuance job stopped")
+	// errIssuanceJobAbandoned is recorded on jobs failed by recoverIssuanceJobs.
+	errIssuanceJobAbandoned = errors.New("issuance job was interrupted")
+)
+
+// IssueGiftCards records a bulk issuance job and starts it in the background.
+// Every card gets its own PIN, given out once by ExportIssuanceJobCSV.
+func (g *GiftCardUseCase) IssueGiftCards(ctx context.Context, data request.IssueGiftCardsRequest) (response.IssuanceJobResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("IssueGiftCards use case")
+
+	expirationDate, err := time.Parse("2006-01-02", data.ExpirationDate)
+	if err != nil {
+		log.Errorf("Error parsing ExpirationDate: %v", err)
+		return response.IssuanceJobResponse{}, err
+	}
+
+	if data.InventoryID != 0 {
+		if err := g.checkInventoryExists(ctx, data.InventoryID); err != nil {
+			return response.IssuanceJobResponse{}, err
+		}
+	}
+
+	// Cards are priced when ordered, even if the campaign ends while they are issued
+	sale, err := g.campaignPrice(ctx, data.CampaignID, data.Denomination, time.Now())
+	if err != nil {
+		log.Errorf("Error pricing gift cards: %v", err)
+		return response.IssuanceJobResponse{}, err
+	}
+
+	job := &models.IssuanceJob{
+		Code:           uuid.NewString(),
+		Count:          data.Count,
+		Denomination:   data.Denomination,
+		UnitPrice:      sale.price,
+		Currency:       data.Currency,
+		Type:           data.Type,
+		ExpirationDate: expirationDate,
+		IsPromotional:  data.IsPromotional,
+		CampaignID:     optionalID(data.CampaignID),
+		InventoryID:    optionalID(data.InventoryID),
+		CustomerID:     optionalID(data.CustomerID),
+		Status:         models.IssuanceJobStatusPending,
+	}
+	err = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		// The whole job is reserved upfront; failIssuanceJob gives back what it did not issue
+		if err := g.reserveCampaignBudget(ctx, sale, data.Count, data.CustomerID); err != nil {
+			return err
+		}
+		if err := g.giftCardRepo.CreateIssuanceJob(ctx, job); err != nil {
+			log.Errorf("Error creating issuance job: %v", err)
+			return err
+		}
+
+		count := int64(data.Count)
+		return g.giftCardRepo.CreateOrder(ctx, &models.Order{
+			CustomerID:     job.CustomerID,
+			IssuanceJobID:  &job.ID,
+			CampaignID:     job.CampaignID,
+			Quantity:       data.Count,
+			FaceValue:      sale.faceValue * money.Amount(count),
+			DiscountAmount: sale.discount * money.Amount(count),
+			TotalAmount:    sale.price * money.Amount(count),
+			Currency:       data.Currency,
+			Status:         models.OrderStatusPaid,
+		})
+	})
+	if err != nil {
+		return response.IssuanceJobResponse{}, err
+	}
+
+	result := toIssuanceJobResponse(job)
+	// The job outlives the request, so it must not inherit its context
+	g.background(func() { g.runIssuanceJob(context.Background(), job) })
+
+	log.Infof("Issuance job %s of %d gift cards started", job.Code, job.Count)
+	return result, nil
+}
+
+// GetIssuanceJob reports the status and progress of a bulk issuance job.
+func (g *GiftCardUseCase) GetIssuanceJob(ctx context.Context, code string) (response.IssuanceJobResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("GetIssuanceJob use case")
+
+	job, err := g.getIssuanceJob(ctx, code)
+	if err != nil {
+		return response.IssuanceJobResponse{}, err
+	}
+
+	return toIssuanceJobResponse(job), nil
+}
+
+// ExportIssuanceJobCSV writes the numbers, codes and PINs of the cards issued
+// by a completed job as CSV. The PINs are deleted once exported, so a job can
+// only be exported once, and within issuanceJobPinTTL of its completion;
+// PINs lost afterwards are reissued per card with ResetGiftCardPin.
+func (g *GiftCardUseCase) ExportIssuanceJobCSV(ctx context.Context, code string, w io.Writer) error {
+	log := logrus.WithContext(ctx)
+	log.Info("ExportIssuanceJobCSV use case")
+
+	job, err := g.getIssuanceJob(ctx, code)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.IssuanceJobStatusCompleted {
+		log.Warnf("Issuance job %s cannot be exported. Current status: %s", code, job.Status)
+		return customerrors.ErrIssuanceJobNotCompleted
+	}
+
+	return g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		// Locked so that concurrent exports cannot both get the PINs
+		job, err := g.giftCardRepo.GetIssuanceJobForUpdate(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		if job.ExportedAt != nil {
+			log.Warnf("Issuance job %s was already exported at %s", code, job.ExportedAt.Format("2006-01-02 15:04:05"))
+			return customerrors.ErrIssuanceJobExported
+		}
+		if job.CompletedAt != nil && time.Since(*job.CompletedAt) > issuanceJobPinTTL {
+			log.Warnf("PINs of issuance job %s expired, it completed at %s", code, job.CompletedAt.Format("2006-01-02 15:04:05"))
+			return customerrors.ErrIssuanceJobPinsExpired
+		}
+
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"gift_card_number", "code", "pin", "balance", "currency", "expiration_date", "status"}); err != nil {
+			return err
+		}
+		for offset := 0; ; offset += issuanceExportPageSize {
+			giftCards, err := g.giftCardRepo.ListIssuedGiftCards(ctx, job.ID, issuanceExportPageSize, offset)
+			if err != nil {
+				log.Errorf("Error listing gift cards of issuance job %s: %v", code, err)
+				return err
+			}
+			pins, err := g.issuedPins(ctx, giftCards)
+			if err != nil {
+				log.Errorf("Error listing PINs of issuance job %s: %v", code, err)
+				return err
+			}
+			for _, giftCard := range giftCards {
+				if err := writer.Write([]string{
+					giftCard.GiftCardNumber,
+					giftCard.Code,
+					pins[giftCard.ID],
+					giftCard.Balance.String(),
+					giftCard.Currency,
+					giftCard.ExpirationDate.Format("2006-01-02"),
+					string(giftCard.Status),
+				}); err != nil {
+					return err
+				}
+			}
+			if len(giftCards) < issuanceExportPageSize {
+				break
+			}
+		}
+		// Written out before the PINs are deleted, so a failed write keeps them
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+
+		if err := g.giftCardRepo.DeleteIssuanceJobPins(ctx, job.ID); err != nil {
+			log.Errorf("Error deleting PINs of issuance job %s: %v", code, err)
+			return err
+		}
+		exportedAt := time.Now()
+		job.ExportedAt = &exportedAt
+		return g.giftCardRepo.UpdateIssuanceJob(ctx, job)
+	})
+}
+
+// issuedPins maps the ids of giftCards to their stored PINs.
+func (g *GiftCardUseCase) issuedPins(ctx context.Context, giftCards []models.GiftCard) (map[uint]string, error) {
+	if len(giftCards) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, len(giftCards))
+	for i := range giftCards {
+		ids[i] = giftCards[i].ID
+	}
+	pins, err := g.giftCardRepo.ListIssuanceJobPins(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]string, len(pins))
+	for _, jobPin := range pins {
+		result[jobPin.GiftCardID] = jobPin.Pin
+	}
+	return result, nil
+}
+
+// runIssuanceJob issues the cards of job batch by batch, recording the
+// progress after each committed batch. A failed batch stops the job; the
+// batches committed before it are kept.
+func (g *GiftCardUseCase) runIssuanceJob(ctx context.Context, job *models.IssuanceJob) {
+	log := logrus.WithContext(ctx).WithField("issuance_job", job.Code)
+
+	job.Status = models.IssuanceJobStatusRunning
+	if err := g.giftCardRepo.UpdateIssuanceJob(ctx, job); err != nil {
+		log.Errorf("Error starting issuance job: %v", err)
+		return
+	}
+
+	var campaignID uint
+	if job.CampaignID != nil {
+		campaignID = *job.CampaignID
+	}
+	prefix, err := g.giftCardNumberPrefix(ctx, job.Type, campaignID)
+	if err != nil {
+		g.failIssuanceJob(ctx, job, err)
+		return
+	}
+
+	for job.IssuedCount < job.Count {
+		size := min(issuanceBatchSize, job.Count-job.IssuedCount)
+		if err := g.issueBatch(ctx, job, prefix, size); err != nil {
+			if errors.Is(err, errIssuanceJobStopped) {
+				log.Infof("Issuance job stopped after %d of %d gift cards", job.IssuedCount, job.Count)
+				return
+			}
+			g.failIssuanceJob(ctx, job, err)
+			return
+		}
+		log.Infof("Issued %d of %d gift cards", job.IssuedCount, job.Count)
+	}
+
+	completedAt := time.Now()
+	job.Status = models.IssuanceJobStatusCompleted
+	job.CompletedAt = &completedAt
+	if err := g.giftCardRepo.UpdateIssuanceJob(ctx, job); err != nil {
+		log.Errorf("Error completing issuance job: %v", err)
+		return
+	}
+	log.Infof("Issuance job completed with %d gift cards", job.IssuedCount)
+}
+
+// issueBatch creates size cards of job with their PINs and opening ledger
+// entries in one transaction, and records them in the job progress. The PINs
+// are kept until the job is exported or purgeIssuanceJobPins deletes them.
+// Numbers already taken are skipped by the insert and replaced with fresh
+// ones, so no existence check is needed per number. The job row is locked
+// first, so a job cancelled by the campaign scheduler or failed by
+// recoverIssuanceJobs issues no further batch and the progress it sees is
+// the committed one.
+func (g *GiftCardUseCase) issueBatch(ctx context.Context, job *models.IssuanceJob, prefix string, size int) error {
+	status := models.GiftCardStatusActive
+	if job.Type == models.GiftCardTypePhysical {
+		status = models.GiftCardStatusIssued
+	}
+
+	// Hashed before the transaction, as bcrypt is slow enough to hold the
+	// job lock for long
+	pins, err := newIssuedPins(size)
+	if err != nil {
+		return err
+	}
+
+	return g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		current, err := g.giftCardRepo.GetIssuanceJobForUpdate(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != models.IssuanceJobStatusRunning {
+			return errIssuanceJobStopped
+		}
+
+		// Numbers tried in earlier attempts are never retried, as a taken
+		// number could then be mistaken for one inserted by this batch
+		seen := make(map[string]bool, size)
+		remaining := pins
+		for attempt := 0; len(remaining) > 0; attempt++ {
+			if attempt == issuanceMaxAttempts {
+				return fmt.Errorf("no unique gift card numbers for %d cards after %d attempts", len(remaining), issuanceMaxAttempts)
+			}
+
+			giftCards, err := newIssuedGiftCards(job, prefix, status, remaining, seen)
+			if err != nil {
+				return err
+			}
+			numbers := make([]string, len(giftCards))
+			pinsByNumber := make(map[string]issuedPin, len(giftCards))
+			for i := range giftCards {
+				numbers[i] = giftCards[i].GiftCardNumber
+				pinsByNumber[numbers[i]] = remaining[i]
+			}
+			if _, err := g.giftCardRepo.CreateGiftCards(ctx, giftCards); err != nil {
+				return err
+			}
+			inserted, err := g.giftCardRepo.GetIssuedGiftCards(ctx, job.ID, numbers)
+			if err != nil {
+				return err
+			}
+			if len(inserted) == 0 {
+				continue
+			}
+
+			transactions := make([]models.Transaction, 0, len(inserted))
+			jobPins := make([]models.IssuanceJobPin, 0, len(inserted))
+			for _, giftCard := range inserted {
+				jobPins = append(jobPins, models.IssuanceJobPin{GiftCardID: giftCard.ID, IssuanceJobID: job.ID, Pin: pinsByNumber[giftCard.GiftCardNumber].pin})
+				delete(pinsByNumber, giftCard.GiftCardNumber)
+				transactions = append(transactions, models.Transaction{
+					GiftCardID:      giftCard.ID,
+					Amount:          giftCard.Balance,
+					BalanceAfter:    giftCard.Balance,
+					Currency:        giftCard.Currency,
+					TransactionType: models.TransactionTypeCreate,
+					Reference:       giftCard.Code,
+					PricePaid:       &job.UnitPrice,
+				})
+			}
+			if err := g.giftCardRepo.CreateTransactions(ctx, transactions); err != nil {
+				return err
+			}
+			if err := g.giftCardRepo.CreateIssuanceJobPins(ctx, jobPins); err != nil {
+				return err
+			}
+			// The PINs of the cards whose numbers were taken go to fresh numbers
+			remaining = make([]issuedPin, 0, len(pinsByNumber))
+			for _, left := range pinsByNumber {
+				remaining = append(remaining, left)
+			}
+		}
+
+		job.IssuedCount += size
+		if err := g.giftCardRepo.UpdateIssuanceJob(ctx, job); err != nil {
+			job.IssuedCount -= size
+			return err
+		}
+		return nil
+	})
+}
+
+// issuedPin is a PIN generated for an issued card, with its hash.
+type issuedPin struct {
+	pin  string
+	hash string
+}
+
+// newIssuedPins generates count PINs with their hashes.
+func newIssuedPins(count int) ([]issuedPin, error) {
+	plain := make([]string, count)
+	for i := range plain {
+		var err error
+		plain[i], err = pin.Generate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	hashes, err := hashPins(plain)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := make([]issuedPin, count)
+	for i := range pins {
+		pins[i] = issuedPin{pin: plain[i], hash: hashes[i]}
+	}
+	return pins, nil
+}
+
+// newIssuedGiftCards builds a card of job for each of pins, with random
+// numbers not in seen, and adds the numbers to seen.
+func newIssuedGiftCards(job *models.IssuanceJob, prefix string, status models.GiftCardStatus, pins []issuedPin, seen map[string]bool) ([]models.GiftCard, error) {
+	giftCards := make([]models.GiftCard, 0, len(pins))
+	for len(giftCards) < len(pins) {
+		number, err := generators.GenerateGiftcardNumber(generators.NumberLength, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if seen[number] {
+			continue
+		}
+		seen[number] = true
+
+		jobID := job.ID
+		giftCards = append(giftCards, models.GiftCard{
+			Code:           uuid.NewString(),
+			GiftCardNumber: number,
+			Type:           job.Type,
+			Balance:        job.Denomination,
+			Currency:       job.Currency,
+			ExpirationDate: job.ExpirationDate,
+			Status:         status,
+			IsPromotional:  job.IsPromotional,
+			CampaignID:     job.CampaignID,
+			InventoryID:    job.InventoryID,
+			IssuanceJobID:  &jobID,
+			PinHash:        pins[len(giftCards)].hash,
+		})
+	}
+	return giftCards, nil
+}
+
+// failIssuanceJob records that job failed with cause and gives the cards it
+// did not issue back to its campaign budget. Errors are logged, and the
+// first one is returned for callers running it in a transaction.
+func (g *GiftCardUseCase) failIssuanceJob(ctx context.Context, job *models.IssuanceJob, cause error) error {
+	log := logrus.WithContext(ctx).WithField("issuance_job", job.Code)
+	log.Errorf("Issuance job failed after %d of %d gift cards: %v", job.IssuedCount, job.Count, cause)
+
+	completedAt := time.Now()
+	job.Status = models.IssuanceJobStatusFailed
+	job.Error = cause.Error()
+	job.CompletedAt = &completedAt
+	if err := g.giftCardRepo.UpdateIssuanceJob(ctx, job); err != nil {
+		log.Errorf("Error recording failure of issuance job: %v", err)
+		return err
+	}
+
+	if job.CampaignID != nil {
+		unissued := job.Count - job.IssuedCount
+		if err := g.campaignRepo.ReleaseCampaignBudget(ctx, *job.CampaignID, unissued, job.Denomination*money.Amount(unissued)); err != nil {
+			log.Errorf("Error releasing campaign budget of issuance job: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// StartIssuanceJobRecovery fails, in the background, the issuance jobs left
+// unfinished by a replica that stopped, and purges the PINs left unexported:
+// once right away, for the jobs of a previous run, and then every
+// issuanceJobStaleAfter until ctx is cancelled.
+func (g *GiftCardUseCase) StartIssuanceJobRecovery(ctx context.Context) {
+	g.background(func() {
+		log := logrus.WithContext(ctx)
+		for {
+			if _, err := g.recoverIssuanceJobs(ctx); err != nil {
+				log.Errorf("Issuance job recovery failed: %v", err)
+			}
+			if _, err := g.purgeIssuanceJobPins(ctx); err != nil {
+				log.Errorf("Issuance job PIN purge failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(issuanceJobStaleAfter):
+			}
+		}
+	})
+}
+
+// recoverIssuanceJobs fails the pending and running jobs that made no
+// progress for issuanceJobStaleAfter, releasing the campaign budget of the
+// cards they did not issue, and returns how many it failed. A running job
+// records its progress after every batch, so such a job is no longer run by
+// any replica; the cards it issued are kept. The jobs are failed in one
+// transaction, and a batch starting meanwhile sees the failure and stops.
+func (g *GiftCardUseCase) recoverIssuanceJobs(ctx context.Context) (int, error) {
+	log := logrus.WithContext(ctx)
+
+	failed := 0
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		jobs, err := g.giftCardRepo.ListStaleIssuanceJobsForUpdate(ctx, time.Now().Add(-issuanceJobStaleAfter))
+		if err != nil {
+			return err
+		}
+		for i := range jobs {
+			if err := g.failIssuanceJob(ctx, &jobs[i], errIssuanceJobAbandoned); err != nil {
+				return err
+			}
+		}
+		failed = len(jobs)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Error recovering issuance jobs: %v", err)
+		return 0, err
+	}
+
+	if failed > 0 {
+		log.Warnf("Failed %d interrupted issuance jobs", failed)
+	}
+	return failed, nil
+}
+
+// purgeIssuanceJobPins deletes the PINs of the jobs that finished more than
+// issuanceJobPinTTL ago without being exported, and returns how many it
+// deleted. Such jobs can no longer be exported.
+func (g *GiftCardUseCase) purgeIssuanceJobPins(ctx context.Context) (int64, error) {
+	log := logrus.WithContext(ctx)
+
+	purged, err := g.giftCardRepo.PurgeIssuanceJobPins(ctx, time.Now().Add(-issuanceJobPinTTL))
+	if err != nil {
+		log.Errorf("Error purging issuance job PINs: %v", err)
+		return 0, err
+	}
+
+	if purged > 0 {
+		log.Infof("Purged %d unexported issuance job PINs", purged)
+	}
+	return purged, nil
+}
+
+// checkInventoryExists verifies that the inventory batch the cards of a job
+// are stocked in exists, so the job does not fail on its first batch.
+func (g *GiftCardUseCase) checkInventoryExists(ctx context.Context, inventoryID uint) error {
+	log := logrus.WithContext(ctx)
+
+	if _, err := g.giftCardRepo.GetInventoryByID(ctx, inventoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Inventory %d not found: %v", inventoryID, err)
+			return customerrors.ErrInventoryNotFound
+		}
+		log.Errorf("Error retrieving inventory %d: %v", inventoryID, err)
+		return err
+	}
+	return nil
+}
+
+func (g *GiftCardUseCase) getIssuanceJob(ctx context.Context, code string) (*models.IssuanceJob, error) {
+	job, err := g.giftCardRepo.GetIssuanceJobByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logrus.WithContext(ctx).Warnf("Issuance job %s not found: %v", code, err)
+			return nil, customerrors.ErrIssuanceJobNotFound
+		}
+		logrus.WithContext(ctx).Errorf("Error retrieving issuance job %s: %v", code, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func toIssuanceJobResponse(job *models.IssuanceJob) response.IssuanceJobResponse {
+	result := response.IssuanceJobResponse{
+		Code:         job.Code,
+		Status:       job.Status,
+		Count:        job.Count,
+		IssuedCount:  job.IssuedCount,
+		Denomination: job.Denomination,
+		UnitPrice:    job.UnitPrice,
+		Currency:     job.Currency,
+		Error:        job.Error,
+		CreatedAt:    job.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if job.Count > 0 {
+		result.Progress = float64(job.IssuedCount) * 100 / float64(job.Count)
+	}
+	if job.CompletedAt != nil {
+		result.CompletedAt = job.CompletedAt.Format("2006-01-02 15:04:05")
+	}
+	if job.ExportedAt != nil {
+		result.ExportedAt = job.ExportedAt.Format("2006-01-02 15:04:05")
+	}
+	return result
+}
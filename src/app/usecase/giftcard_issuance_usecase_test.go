@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// newSyncGiftCardUseCase runs background jobs inline so their effects can be asserted.
+func newSyncGiftCardUseCase(giftCardRepo *MockGiftCardRepository, settingRepo *MockSettingRepository) *GiftCardUseCase {
+	useCase := NewGiftCardUseCase(giftCardRepo, new(MockCampaignRepository), settingRepo, new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+	useCase.background = func(fn func()) { fn() }
+	return useCase
+}
+
+func TestGiftCardUseCase_IssueGiftCards(t *testing.T) {
+	ctx := context.Background()
+	issueReq := request.IssueGiftCardsRequest{
+		Count:          3,
+		Denomination:   money.MustParse("25.00"),
+		Currency:       "USD",
+		Type:           "virtual",
+		ExpirationDate: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+	}
+	batchOf := func(size int) func([]models.GiftCard) bool {
+		return func(giftCards []models.GiftCard) bool {
+			if len(giftCards) != size {
+				return false
+			}
+			for _, giftCard := range giftCards {
+				if !generators.ValidGiftcardNumber(giftCard.GiftCardNumber) || giftCard.Balance != money.MustParse("25.00") || giftCard.IssuanceJobID == nil || giftCard.PinHash == "" {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	t.Run("replaces numbers already taken and completes the job", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, mockSettings)
+		var job *models.IssuanceJob
+		mockRepo.On("CreateIssuanceJob", ctx, mock.AnythingOfType("*models.IssuanceJob")).Run(func(args mock.Arguments) {
+			job = args.Get(1).(*models.IssuanceJob)
+			job.ID = 9
+		}).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", mock.Anything, mock.AnythingOfType("*models.IssuanceJob")).Return(nil)
+		mockRepo.On("GetIssuanceJobForUpdate", mock.Anything, mock.Anything).Return(&models.IssuanceJob{Status: models.IssuanceJobStatusRunning}, nil)
+		mockSettings.On("GetSetting", mock.Anything, "giftcard_number.prefix.virtual").Return(nil, nil).Once()
+		// One of the three numbers is taken, so a second attempt issues the last card
+		var created []models.GiftCard
+		mockRepo.On("CreateGiftCards", mock.Anything, mock.MatchedBy(batchOf(3))).Run(func(args mock.Arguments) {
+			created = args.Get(1).([]models.GiftCard)
+		}).Return(int64(2), nil).Once()
+		firstIssued := mockRepo.On("GetIssuedGiftCards", mock.Anything, uint(9), mock.Anything).Once()
+		firstIssued.Run(func(args mock.Arguments) {
+			firstIssued.Return([]models.GiftCard{
+				{ID: 1, Code: "c1", GiftCardNumber: created[0].GiftCardNumber, Balance: money.MustParse("25.00"), Currency: "USD"},
+				{ID: 2, Code: "c2", GiftCardNumber: created[1].GiftCardNumber, Balance: money.MustParse("25.00"), Currency: "USD"},
+			}, nil)
+		})
+		mockRepo.On("CreateGiftCards", mock.Anything, mock.MatchedBy(batchOf(1))).Run(func(args mock.Arguments) {
+			created = args.Get(1).([]models.GiftCard)
+		}).Return(int64(1), nil).Once()
+		secondIssued := mockRepo.On("GetIssuedGiftCards", mock.Anything, uint(9), mock.Anything).Once()
+		secondIssued.Run(func(args mock.Arguments) {
+			secondIssued.Return([]models.GiftCard{
+				{ID: 3, Code: "c3", GiftCardNumber: created[0].GiftCardNumber, Balance: money.MustParse("25.00"), Currency: "USD"},
+			}, nil)
+		})
+		var pins []models.IssuanceJobPin
+		mockRepo.On("CreateIssuanceJobPins", mock.Anything, mock.AnythingOfType("[]models.IssuanceJobPin")).Run(func(args mock.Arguments) {
+			pins = append(pins, args.Get(1).([]models.IssuanceJobPin)...)
+		}).Return(nil).Twice()
+		mockRepo.On("CreateTransactions", mock.Anything, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 2 && isLedgerEntry(models.TransactionTypeCreate, money.MustParse("25.00"), money.MustParse("25.00"))(&transactions[0])
+		})).Return(nil).Once()
+		mockRepo.On("CreateTransactions", mock.Anything, mock.MatchedBy(func(transactions []models.Transaction) bool {
+			return len(transactions) == 1 && transactions[0].GiftCardID == 3
+		})).Return(nil).Once()
+
+		resp, err := useCase.IssueGiftCards(ctx, issueReq)
+		assert.NoError(t, err)
+		assert.Equal(t, models.IssuanceJobStatusPending, resp.Status)
+		assert.Equal(t, 3, resp.Count)
+		assert.Equal(t, models.IssuanceJobStatusCompleted, job.Status)
+		assert.Equal(t, 3, job.IssuedCount)
+		assert.NotNil(t, job.CompletedAt)
+		assert.Len(t, pins, 3)
+		for i, jobPin := range pins {
+			assert.Equal(t, uint(i+1), jobPin.GiftCardID)
+			assert.Len(t, jobPin.Pin, pin.Length)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("physical cards are issued in their inventory", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, mockSettings)
+		physicalReq := issueReq
+		physicalReq.Count = 1
+		physicalReq.Type = models.GiftCardTypePhysical
+		physicalReq.InventoryID = 4
+		mockRepo.On("GetInventoryByID", ctx, uint(4)).Return(&models.Inventory{ID: 4, Status: models.InventoryStatusInStock}, nil).Once()
+		mockRepo.On("CreateIssuanceJob", ctx, mock.MatchedBy(func(job *models.IssuanceJob) bool {
+			return job.InventoryID != nil && *job.InventoryID == 4
+		})).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", mock.Anything, mock.AnythingOfType("*models.IssuanceJob")).Return(nil)
+		mockRepo.On("GetIssuanceJobForUpdate", mock.Anything, mock.Anything).Return(&models.IssuanceJob{Status: models.IssuanceJobStatusRunning}, nil)
+		mockSettings.On("GetSetting", mock.Anything, "giftcard_number.prefix.physical").Return(nil, nil).Once()
+		var created []models.GiftCard
+		mockRepo.On("CreateGiftCards", mock.Anything, mock.MatchedBy(func(giftCards []models.GiftCard) bool {
+			return len(giftCards) == 1 && giftCards[0].Status == models.GiftCardStatusIssued && giftCards[0].InventoryID != nil && *giftCards[0].InventoryID == 4
+		})).Run(func(args mock.Arguments) {
+			created = args.Get(1).([]models.GiftCard)
+		}).Return(int64(1), nil).Once()
+		issued := mockRepo.On("GetIssuedGiftCards", mock.Anything, mock.Anything, mock.Anything).Once()
+		issued.Run(func(args mock.Arguments) {
+			issued.Return([]models.GiftCard{{ID: 1, GiftCardNumber: created[0].GiftCardNumber, Balance: money.MustParse("25.00")}}, nil)
+		})
+		mockRepo.On("CreateTransactions", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateIssuanceJobPins", mock.Anything, mock.Anything).Return(nil).Once()
+
+		_, err := useCase.IssueGiftCards(ctx, physicalReq)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown inventory", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, new(MockSettingRepository))
+		physicalReq := issueReq
+		physicalReq.Type = models.GiftCardTypePhysical
+		physicalReq.InventoryID = 4
+		mockRepo.On("GetInventoryByID", ctx, uint(4)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.IssueGiftCards(ctx, physicalReq)
+		assert.ErrorIs(t, err, app.ErrInventoryNotFound)
+		mockRepo.AssertNotCalled(t, "CreateIssuanceJob", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a failing batch fails the job", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, mockSettings)
+		var job *models.IssuanceJob
+		mockRepo.On("CreateIssuanceJob", ctx, mock.AnythingOfType("*models.IssuanceJob")).Run(func(args mock.Arguments) {
+			job = args.Get(1).(*models.IssuanceJob)
+		}).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", mock.Anything, mock.AnythingOfType("*models.IssuanceJob")).Return(nil)
+		mockRepo.On("GetIssuanceJobForUpdate", mock.Anything, mock.Anything).Return(&models.IssuanceJob{Status: models.IssuanceJobStatusRunning}, nil)
+		mockSettings.On("GetSetting", mock.Anything, "giftcard_number.prefix.virtual").Return(nil, nil).Once()
+		mockRepo.On("CreateGiftCards", mock.Anything, mock.Anything).Return(int64(0), errors.New("insert error")).Once()
+
+		_, err := useCase.IssueGiftCards(ctx, issueReq)
+		assert.NoError(t, err)
+		assert.Equal(t, models.IssuanceJobStatusFailed, job.Status)
+		assert.Equal(t, "insert error", job.Error)
+		assert.Equal(t, 0, job.IssuedCount)
+	})
+
+	t.Run("a job failed by the recovery stops", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, mockSettings)
+		var job *models.IssuanceJob
+		mockRepo.On("CreateIssuanceJob", ctx, mock.AnythingOfType("*models.IssuanceJob")).Run(func(args mock.Arguments) {
+			job = args.Get(1).(*models.IssuanceJob)
+		}).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", mock.Anything, mock.AnythingOfType("*models.IssuanceJob")).Return(nil).Once()
+		mockRepo.On("GetIssuanceJobForUpdate", mock.Anything, mock.Anything).Return(&models.IssuanceJob{Status: models.IssuanceJobStatusFailed}, nil).Once()
+		mockSettings.On("GetSetting", mock.Anything, "giftcard_number.prefix.virtual").Return(nil, nil).Once()
+
+		_, err := useCase.IssueGiftCards(ctx, issueReq)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, job.IssuedCount)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateGiftCards", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a job cancelled by the campaign scheduler stops", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := newSyncGiftCardUseCase(mockRepo, mockSettings)
+		var job *models.IssuanceJob
+		mockRepo.On("CreateIssuanceJob", ctx, mock.AnythingOfType("*models.IssuanceJob")).Run(func(args mock.Arguments) {
+			job = args.Get(1).(*models.IssuanceJob)
+		}).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", mock.Anything, mock.AnythingOfType("*models.IssuanceJob")).Return(nil).Once()
+		mockRepo.On("GetIssuanceJobForUpdate", mock.Anything, mock.Anything).Return(&models.IssuanceJob{Status: models.IssuanceJobStatusCancelled}, nil).Once()
+		mockSettings.On("GetSetting", mock.Anything, "giftcard_number.prefix.virtual").Return(nil, nil).Once()
+
+		_, err := useCase.IssueGiftCards(ctx, issueReq)
+		assert.NoError(t, err)
+		assert.Equal(t, models.IssuanceJobStatusRunning, job.Status)
+		assert.Equal(t, 0, job.IssuedCount)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateGiftCards", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGiftCardUseCase_RecoverIssuanceJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fails interrupted jobs and releases their campaign budget", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaignID := uint(12)
+		mockRepo.On("ListStaleIssuanceJobsForUpdate", ctx, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= issuanceJobStaleAfter
+		})).Return([]models.IssuanceJob{
+			{Code: "job-1", Count: 10, IssuedCount: 4, Denomination: money.MustParse("25.00"), CampaignID: &campaignID, Status: models.IssuanceJobStatusRunning},
+			{Code: "job-2", Count: 5, Denomination: money.MustParse("25.00"), Status: models.IssuanceJobStatusPending},
+		}, nil).Once()
+		mockRepo.On("UpdateIssuanceJob", ctx, mock.MatchedBy(func(job *models.IssuanceJob) bool {
+			return job.Status == models.IssuanceJobStatusFailed && job.Error == errIssuanceJobAbandoned.Error() && job.CompletedAt != nil
+		})).Return(nil).Twice()
+		campaignRepo.On("ReleaseCampaignBudget", ctx, uint(12), 6, money.MustParse("150.00")).Return(nil).Once()
+
+		failed, err := useCase.recoverIssuanceJobs(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, failed)
+		mockRepo.AssertExpectations(t)
+		campaignRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failing update is returned", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		mockRepo.On("ListStaleIssuanceJobsForUpdate", ctx, mock.AnythingOfType("time.Time")).Return([]models.IssuanceJob{
+			{Code: "job-1", Count: 10, Denomination: money.MustParse("25.00"), Status: models.IssuanceJobStatusRunning},
+		}, nil).Once()
+		mockRepo.On("UpdateIssuanceJob", ctx, mock.AnythingOfType("*models.IssuanceJob")).Return(assert.AnError).Once()
+
+		failed, err := useCase.recoverIssuanceJobs(ctx)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0, failed)
+	})
+}
+
+func TestGiftCardUseCase_PurgeIssuanceJobPins(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("purges the PINs of jobs finished before the TTL", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		mockRepo.On("PurgeIssuanceJobPins", ctx, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) >= issuanceJobPinTTL && time.Since(before) < issuanceJobPinTTL+time.Minute
+		})).Return(int64(3), nil).Once()
+
+		purged, err := useCase.purgeIssuanceJobPins(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failing purge is returned", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		mockRepo.On("PurgeIssuanceJobPins", ctx, mock.AnythingOfType("time.Time")).Return(int64(0), assert.AnError).Once()
+
+		_, err := useCase.purgeIssuanceJobPins(ctx)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestGiftCardUseCase_ExportIssuanceJobCSV(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes the issued cards", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		expiration := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+		mockRepo.On("GetIssuanceJobByCode", ctx, "job-1").Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted}, nil).Once()
+		mockRepo.On("GetIssuanceJobForUpdate", ctx, uint(9)).Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted}, nil).Once()
+		mockRepo.On("ListIssuedGiftCards", ctx, uint(9), issuanceExportPageSize, 0).Return([]models.GiftCard{
+			{ID: 4, GiftCardNumber: "GC12345678901237", Code: "c1", Balance: money.MustParse("25.00"), Currency: "USD", ExpirationDate: expiration, Status: models.GiftCardStatusActive},
+		}, nil).Once()
+		mockRepo.On("ListIssuanceJobPins", ctx, []uint{4}).Return([]models.IssuanceJobPin{{GiftCardID: 4, IssuanceJobID: 9, Pin: "123456"}}, nil).Once()
+		mockRepo.On("DeleteIssuanceJobPins", ctx, uint(9)).Return(nil).Once()
+		mockRepo.On("UpdateIssuanceJob", ctx, mock.MatchedBy(func(job *models.IssuanceJob) bool {
+			return job.ID == 9 && job.ExportedAt != nil
+		})).Return(nil).Once()
+
+		var buf bytes.Buffer
+		err := useCase.ExportIssuanceJobCSV(ctx, "job-1", &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "gift_card_number,code,pin,balance,currency,expiration_date,status\nGC12345678901237,c1,123456,25.00,USD,2027-01-31,active\n", buf.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("jobs can only be exported once", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		exportedAt := time.Now().Add(-time.Hour)
+		mockRepo.On("GetIssuanceJobByCode", ctx, "job-1").Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted}, nil).Once()
+		mockRepo.On("GetIssuanceJobForUpdate", ctx, uint(9)).Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted, ExportedAt: &exportedAt}, nil).Once()
+
+		err := useCase.ExportIssuanceJobCSV(ctx, "job-1", new(bytes.Buffer))
+		assert.ErrorIs(t, err, app.ErrIssuanceJobExported)
+		mockRepo.AssertNotCalled(t, "ListIssuedGiftCards", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteIssuanceJobPins", mock.Anything, mock.Anything)
+	})
+
+	t.Run("jobs cannot be exported once their PINs expired", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		completedAt := time.Now().Add(-issuanceJobPinTTL - time.Minute)
+		mockRepo.On("GetIssuanceJobByCode", ctx, "job-1").Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted}, nil).Once()
+		mockRepo.On("GetIssuanceJobForUpdate", ctx, uint(9)).Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusCompleted, CompletedAt: &completedAt}, nil).Once()
+
+		err := useCase.ExportIssuanceJobCSV(ctx, "job-1", new(bytes.Buffer))
+		assert.ErrorIs(t, err, app.ErrIssuanceJobPinsExpired)
+		mockRepo.AssertNotCalled(t, "ListIssuedGiftCards", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("running jobs cannot be exported", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetIssuanceJobByCode", ctx, "job-1").Return(&models.IssuanceJob{ID: 9, Code: "job-1", Status: models.IssuanceJobStatusRunning}, nil).Once()
+
+		err := useCase.ExportIssuanceJobCSV(ctx, "job-1", new(bytes.Buffer))
+		assert.ErrorIs(t, err, app.ErrIssuanceJobNotCompleted)
+		mockRepo.AssertNotCalled(t, "ListIssuedGiftCards", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetIssuanceJobByCode", ctx, "missing").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.GetIssuanceJob(ctx, "missing")
+		assert.ErrorIs(t, err, app.ErrIssuanceJobNotFound)
+	})
+}
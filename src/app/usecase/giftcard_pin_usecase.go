@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/pin"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// pinMaxAttemptsSetting is the models.Setting holding how many consecutive
+	// wrong PINs lock a card.
+	pinMaxAttemptsSetting = "pin.max_attempts"
+
+	defaultPinMaxAttempts = 5
+)
+
+// GetGiftCardBalance answers a balance inquiry for a card holder, who must
+// know the card PIN.
+func (g *GiftCardUseCase) GetGiftCardBalance(ctx context.Context, giftCardNumber string, data request.GiftCardBalanceRequest) (response.GiftCardBalanceResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("GetGiftCardBalance use case")
+
+	if err := g.checkGiftCardNumber(ctx, giftCardNumber); err != nil {
+		return response.GiftCardBalanceResponse{}, err
+	}
+
+	var result response.GiftCardBalanceResponse
+	// pinErr carries a rejection whose side effects must still be committed.
+	var pinErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		if commit, err := g.verifyPin(ctx, giftCard, data.Pin); err != nil {
+			if commit {
+				pinErr = err
+				return nil
+			}
+			return err
+		}
+
+		result = response.GiftCardBalanceResponse{
+			GiftCardNumber: giftCard.GiftCardNumber,
+			Balance:        giftCard.Balance,
+			Currency:       giftCard.Currency,
+			Status:         string(giftCard.Status),
+		}
+		return nil
+	})
+	if err != nil {
+		return response.GiftCardBalanceResponse{}, err
+	}
+	if pinErr != nil {
+		return response.GiftCardBalanceResponse{}, pinErr
+	}
+
+	log.Infof("Balance of gift card %s retrieved", giftCardNumber)
+	return result, nil
+}
+
+// ResetGiftCardPin clears the failed attempts of a card and unlocks its PIN.
+// With Reissue a new PIN is generated and returned once.
+func (g *GiftCardUseCase) ResetGiftCardPin(ctx context.Context, id string, data request.ResetGiftCardPinRequest) (response.GiftCardPinResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ResetGiftCardPin use case")
+
+	var result response.GiftCardPinResponse
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetGiftCardByCodeForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card with code %s not found: %v", id, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card with code %s: %v", id, err)
+			return err
+		}
+
+		result = response.GiftCardPinResponse{GiftCardNumber: giftCard.GiftCardNumber}
+		pinHash := giftCard.PinHash
+		action := fmt.Sprintf("reset PIN of gift card %s by %s", giftCard.GiftCardNumber, data.ResetBy)
+		if data.Reissue {
+			result.Pin, err = pin.Generate()
+			if err != nil {
+				log.Errorf("Error generating gift card PIN: %v", err)
+				return err
+			}
+			pinHash, err = pin.Hash(result.Pin)
+			if err != nil {
+				log.Errorf("Error hashing gift card PIN: %v", err)
+				return err
+			}
+			action = fmt.Sprintf("reissue PIN of gift card %s by %s", giftCard.GiftCardNumber, data.ResetBy)
+		}
+
+		if err := g.giftCardRepo.UpdateGiftCardPin(ctx, giftCard.Code, pinHash, 0, nil); err != nil {
+			log.Errorf("Error resetting PIN of gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+
+		return g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{Action: action})
+	})
+	if err != nil {
+		return response.GiftCardPinResponse{}, err
+	}
+
+	log.Infof("PIN of gift card %s reset by %s", result.GiftCardNumber, data.ResetBy)
+	return result, nil
+}
+
+// verifyPin checks the PIN given for a locked card. Cards issued without a
+// PIN accept any request, but imported cards without one are rejected until
+// a PIN is reissued with ResetGiftCardPin. Every wrong PIN is counted and audited, and the
+// card is locked once the configured number of attempts is reached; commit
+// reports that the returned error must be returned only after committing
+// the transaction, so the attempt is persisted.
+func (g *GiftCardUseCase) verifyPin(ctx context.Context, giftCard *models.GiftCard, givenPin string) (commit bool, err error) {
+	log := logrus.WithContext(ctx)
+
+	if giftCard.PinHash == "" {
+		if giftCard.Imported {
+			log.Warnf("Imported gift card %s has no PIN yet", giftCard.GiftCardNumber)
+			return false, customerrors.ErrPinResetRequired
+		}
+		return false, nil
+	}
+	if giftCard.PinLockedAt != nil {
+		log.Warnf("PIN of gift card %s is locked since %s", giftCard.GiftCardNumber, giftCard.PinLockedAt.Format("2006-01-02 15:04:05"))
+		return false, customerrors.ErrPinLocked
+	}
+	if givenPin == "" {
+		log.Warnf("No PIN given for gift card %s", giftCard.GiftCardNumber)
+		return false, customerrors.ErrInvalidPin
+	}
+
+	ok, err := pin.Verify(giftCard.PinHash, givenPin)
+	if err != nil {
+		log.Errorf("Error verifying PIN of gift card %s: %v", giftCard.GiftCardNumber, err)
+		return false, err
+	}
+	if ok {
+		if giftCard.FailedPinAttempts == 0 {
+			return false, nil
+		}
+		if err := g.giftCardRepo.UpdateGiftCardPin(ctx, giftCard.Code, giftCard.PinHash, 0, nil); err != nil {
+			log.Errorf("Error clearing failed PIN attempts of gift card %s: %v", giftCard.GiftCardNumber, err)
+			return false, err
+		}
+		giftCard.FailedPinAttempts = 0
+		return false, nil
+	}
+
+	maxAttempts, err := g.pinMaxAttempts(ctx)
+	if err != nil {
+		log.Errorf("Error reading PIN attempt limit: %v", err)
+		return false, err
+	}
+
+	attempts := giftCard.FailedPinAttempts + 1
+	var lockedAt *time.Time
+	if attempts >= maxAttempts {
+		now := time.Now()
+		lockedAt = &now
+	}
+	if err := g.giftCardRepo.UpdateGiftCardPin(ctx, giftCard.Code, giftCard.PinHash, attempts, lockedAt); err != nil {
+		log.Errorf("Error recording failed PIN attempt on gift card %s: %v", giftCard.GiftCardNumber, err)
+		return false, err
+	}
+	giftCard.FailedPinAttempts = attempts
+	giftCard.PinLockedAt = lockedAt
+
+	log.Warnf("Wrong PIN for gift card %s (%d/%d attempts)", giftCard.GiftCardNumber, attempts, maxAttempts)
+	if err := g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{
+		Action: fmt.Sprintf("failed PIN attempt %d/%d on gift card %s", attempts, maxAttempts, giftCard.GiftCardNumber),
+	}); err != nil {
+		return false, err
+	}
+	if lockedAt == nil {
+		return true, customerrors.ErrInvalidPin
+	}
+
+	log.Warnf("PIN of gift card %s locked after %d failed attempts", giftCard.GiftCardNumber, attempts)
+	if err := g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{
+		Action: fmt.Sprintf("lock PIN of gift card %s after %d failed attempts", giftCard.GiftCardNumber, attempts),
+	}); err != nil {
+		return false, err
+	}
+	return true, customerrors.ErrPinLocked
+}
+
+// hashPins hashes pins, in the same order. bcrypt is slow by design, so the
+// hashes are computed on all CPUs.
+func hashPins(pins []string) ([]string, error) {
+	hashes := make([]string, len(pins))
+	errs := make([]error, len(pins))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hashes[i], errs[i] = pin.Hash(pins[i])
+			}
+		}()
+	}
+	for i := range pins {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// pinMaxAttempts reads how many wrong PINs lock a card, falling back to
+// defaultPinMaxAttempts when it is not configured.
+func (g *GiftCardUseCase) pinMaxAttempts(ctx context.Context) (int, error) {
+	attempts, ok, err := intSetting(ctx, g.settingRepo, pinMaxAttemptsSetting)
+	if err != nil {
+		return 0, err
+	}
+	if !ok || attempts <= 0 {
+		return defaultPinMaxAttempts, nil
+	}
+	return attempts, nil
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGiftCardUseCase_PinProtection(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+	pinHash, err := pin.Hash("123456")
+	require.NoError(t, err)
+
+	card := func(failedAttempts int, lockedAt *time.Time) *models.GiftCard {
+		return &models.GiftCard{
+			ID:                7,
+			Code:              "test-code-pin",
+			GiftCardNumber:    cardNumber,
+			Balance:           money.MustParse("80.00"),
+			Currency:          "USD",
+			Status:            models.GiftCardStatusActive,
+			ExpirationDate:    time.Now().AddDate(1, 0, 0),
+			PinHash:           pinHash,
+			FailedPinAttempts: failedAttempts,
+			PinLockedAt:       lockedAt,
+		}
+	}
+	maxAttempts := func(mockSettings *MockSettingRepository, value string) {
+		mockSettings.On("GetSetting", ctx, pinMaxAttemptsSetting).Return(&models.Setting{Name: pinMaxAttemptsSetting, Value: value}, nil).Once()
+	}
+
+	t.Run("balance inquiry with the right PIN", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(0, nil), nil).Once()
+
+		resp, err := useCase.GetGiftCardBalance(ctx, cardNumber, request.GiftCardBalanceRequest{Pin: "123456"})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("80.00"), resp.Balance)
+		assert.Equal(t, "active", resp.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("right PIN clears earlier failed attempts", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(2, nil), nil).Once()
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-pin", pinHash, 0, (*time.Time)(nil)).Return(nil).Once()
+
+		_, err := useCase.GetGiftCardBalance(ctx, cardNumber, request.GiftCardBalanceRequest{Pin: "123456"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong PIN is counted and audited", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, mockAudit, fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(1, nil), nil).Once()
+		maxAttempts(mockSettings, "3")
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-pin", pinHash, 2, (*time.Time)(nil)).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasPrefix(auditLog.Action, "failed PIN attempt 2/3")
+		})).Return(nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, cardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00"), Pin: "000000"})
+		assert.ErrorIs(t, err, app.ErrInvalidPin)
+		assert.False(t, resp.IsUsed)
+		assert.Equal(t, "Invalid PIN.", resp.Message)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last wrong PIN locks the card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, mockAudit, fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, authorizationExpirySetting).Return(nil, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(2, nil), nil).Once()
+		maxAttempts(mockSettings, "3")
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-pin", pinHash, 3, mock.AnythingOfType("*time.Time")).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasPrefix(auditLog.Action, "failed PIN attempt 3/3")
+		})).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasPrefix(auditLog.Action, "lock PIN of gift card")
+		})).Return(nil).Once()
+
+		_, err := useCase.AuthorizeGiftCardAmount(ctx, cardNumber, request.AuthorizeGiftCardRequest{Amount: money.MustParse("10.00"), Pin: "000000"})
+		assert.ErrorIs(t, err, app.ErrPinLocked)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("locked card rejects even the right PIN", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		lockedAt := time.Now().Add(-time.Hour)
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(5, &lockedAt), nil).Once()
+
+		_, err := useCase.GetGiftCardBalance(ctx, cardNumber, request.GiftCardBalanceRequest{Pin: "123456"})
+		assert.ErrorIs(t, err, app.ErrPinLocked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("imported card without a PIN is rejected until one is reissued", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		imported := card(0, nil)
+		imported.PinHash = ""
+		imported.Imported = true
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(imported, nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, cardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00"), Pin: "123456"})
+		assert.ErrorIs(t, err, app.ErrPinResetRequired)
+		assert.False(t, resp.IsUsed)
+		assert.Equal(t, "PIN must be reissued.", resp.Message)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing PIN is rejected without counting an attempt", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card(0, nil), nil).Once()
+
+		_, err := useCase.GetGiftCardBalance(ctx, cardNumber, request.GiftCardBalanceRequest{})
+		assert.ErrorIs(t, err, app.ErrInvalidPin)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardPin", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGiftCardUseCase_ResetGiftCardPin(t *testing.T) {
+	ctx := context.Background()
+	lockedAt := time.Now().Add(-time.Hour)
+	card := func() *models.GiftCard {
+		return &models.GiftCard{ID: 7, Code: "test-code-pin", GiftCardNumber: "GC12345678901237", PinHash: "old-hash", FailedPinAttempts: 5, PinLockedAt: &lockedAt}
+	}
+
+	t.Run("reset unlocks and keeps the PIN", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-pin").Return(card(), nil).Once()
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-pin", "old-hash", 0, (*time.Time)(nil)).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return auditLog.Action == "reset PIN of gift card GC12345678901237 by admin-1"
+		})).Return(nil).Once()
+
+		resp, err := useCase.ResetGiftCardPin(ctx, "test-code-pin", request.ResetGiftCardPinRequest{ResetBy: "admin-1"})
+		assert.NoError(t, err)
+		assert.Empty(t, resp.Pin)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("reissue stores the hash of a new PIN", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		var newHash string
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "test-code-pin").Return(card(), nil).Once()
+		mockRepo.On("UpdateGiftCardPin", ctx, "test-code-pin", mock.AnythingOfType("string"), 0, (*time.Time)(nil)).Run(func(args mock.Arguments) {
+			newHash = args.String(2)
+		}).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.HasPrefix(auditLog.Action, "reissue PIN")
+		})).Return(nil).Once()
+
+		resp, err := useCase.ResetGiftCardPin(ctx, "test-code-pin", request.ResetGiftCardPinRequest{Reissue: true, ResetBy: "admin-1"})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Pin, pin.Length)
+		ok, err := pin.Verify(newHash, resp.Pin)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, "missing").Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.ResetGiftCardPin(ctx, "missing", request.ResetGiftCardPinRequest{ResetBy: "admin-1"})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+	})
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// salePrice is what is paid for gift cards of a face value. The cards carry
+// the full face value; the price is lower by the campaign discount.
+type salePrice struct {
+	faceValue money.Amount
+	discount  money.Amount
+	price     money.Amount
+	// campaign the cards are sold under, nil for none
+	campaign *models.Campaign
+}
+
+// campaignPrice prices faceValue under campaign campaignID, 0 meaning no
+// campaign. The campaign discount only applies at now while the campaign is
+// enabled and between its start and end dates; before its start date the
+// cards are sold at face value. Cards are not issued at all under a campaign
+// that is paused or has ended.
+func (g *GiftCardUseCase) campaignPrice(ctx context.Context, campaignID uint, faceValue money.Amount, now time.Time) (salePrice, error) {
+	log := logrus.WithContext(ctx)
+
+	result := salePrice{faceValue: faceValue, price: faceValue}
+	if campaignID == 0 {
+		return result, nil
+	}
+
+	campaign, err := g.campaignRepo.GetCampaign(ctx, int(campaignID))
+	if err != nil {
+		log.Errorf("Error retrieving campaign %d: %v", campaignID, err)
+		return salePrice{}, err
+	}
+	if campaign == nil {
+		log.Warnf("Campaign %d not found", campaignID)
+		return salePrice{}, customerrors.ErrCampaignNotFound
+	}
+	result.campaign = campaign
+	if err := checkCampaignOpen(campaign, now); err != nil {
+		log.Warnf("Campaign %d does not issue cards: %v", campaignID, err)
+		return salePrice{}, err
+	}
+	if !campaignRunning(campaign, now) {
+		log.Infof("Campaign %d is not running, selling at face value", campaignID)
+		return result, nil
+	}
+	if campaign.DiscountPercentage <= 0 {
+		return result, nil
+	}
+
+	// Formatting keeps the percentage as stored, e.g. 12.5, rather than its binary approximation
+	percentage, ok := new(big.Rat).SetString(strconv.FormatFloat(campaign.DiscountPercentage, 'f', -1, 64))
+	if !ok || percentage.Cmp(big.NewRat(100, 1)) > 0 {
+		return salePrice{}, fmt.Errorf("invalid discount percentage %v for campaign %d", campaign.DiscountPercentage, campaignID)
+	}
+	result.discount, err = faceValue.Mul(percentage.Quo(percentage, big.NewRat(100, 1)))
+	if err != nil {
+		return salePrice{}, err
+	}
+	result.price = faceValue - result.discount
+	return result, nil
+}
+
+// reserveCampaignBudget counts cards cards of sale against the budget, the
+// card limit and the per-customer cap of its campaign. It must run in the
+// transaction creating the cards, so the reservation is undone with them.
+// The campaign is read again under lock, as the scheduler ends campaigns
+// under the same lock: a campaign ending meanwhile either rejects the cards
+// here or sees them when it expires its cards.
+func (g *GiftCardUseCase) reserveCampaignBudget(ctx context.Context, sale salePrice, cards int, customerID uint) error {
+	log := logrus.WithContext(ctx)
+
+	campaign := sale.campaign
+	if campaign == nil {
+		return nil
+	}
+
+	locked, err := g.campaignRepo.GetCampaignByIDForUpdate(ctx, campaign.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Warnf("Campaign %d was deleted before its cards were issued", campaign.ID)
+		return customerrors.ErrCampaignNotFound
+	}
+	if err != nil {
+		log.Errorf("Error locking campaign %d: %v", campaign.ID, err)
+		return err
+	}
+	if err := checkCampaignOpen(locked, time.Now()); err != nil {
+		log.Warnf("Campaign %d closed before its cards were issued", campaign.ID)
+		return err
+	}
+
+	value := sale.faceValue * money.Amount(cards)
+	ok, err := g.campaignRepo.ReserveCampaignBudget(ctx, campaign.ID, cards, value)
+	if err != nil {
+		log.Errorf("Error reserving budget of campaign %d: %v", campaign.ID, err)
+		return err
+	}
+	if !ok {
+		log.Warnf("Campaign %d cannot issue %d more cards worth %s", campaign.ID, cards, value)
+		return customerrors.ErrCampaignExhausted
+	}
+
+	if customerID == 0 || campaign.MaxCardsPerCustomer == 0 {
+		return nil
+	}
+	// The reservation locked the campaign row, so concurrent sales to the
+	// customer are committed before they are counted here
+	ordered, err := g.campaignRepo.CountCustomerCampaignCards(ctx, campaign.ID, customerID)
+	if err != nil {
+		log.Errorf("Error counting cards of customer %d in campaign %d: %v", customerID, campaign.ID, err)
+		return err
+	}
+	if ordered+int64(cards) > int64(campaign.MaxCardsPerCustomer) {
+		log.Warnf("Customer %d already has %d of %d cards of campaign %d", customerID, ordered, campaign.MaxCardsPerCustomer, campaign.ID)
+		return customerrors.ErrCampaignCustomerCap
+	}
+	return nil
+}
+
+// optionalID maps the zero id used by requests to a NULL foreign key.
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// campaignRunning reports whether campaign is enabled and within its dates
+// at now. The campaign runs through the whole of its end date.
+func campaignRunning(campaign *models.Campaign, now time.Time) bool {
+	return campaignStatus(campaign, now) == models.CampaignStatusRunning
+}
+
+// checkCampaignOpen rejects issuing cards under campaign when it is paused or
+// has ended at now.
+func checkCampaignOpen(campaign *models.Campaign, now time.Time) error {
+	switch campaignStatus(campaign, now) {
+	case models.CampaignStatusPaused, models.CampaignStatusEnded:
+		return customerrors.ErrCampaignClosed
+	}
+	return nil
+}
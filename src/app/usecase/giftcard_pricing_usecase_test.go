@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftCardUseCase_CampaignPrice(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	campaign := func(enabled bool, start, end string, discount float64) *models.Campaign {
+		startDate, _ := time.Parse("2006-01-02", start)
+		endDate, _ := time.Parse("2006-01-02", end)
+		return &models.Campaign{ID: 12, IsEnabled: enabled, StartDate: startDate, EndDate: endDate, DiscountPercentage: discount}
+	}
+
+	tests := []struct {
+		name     string
+		campaign *models.Campaign
+		price    string
+		discount string
+		err      error
+	}{
+		{name: "running campaign", campaign: campaign(true, "2026-06-01", "2026-06-30", 12.5), price: "70.00", discount: "10.00"},
+		{name: "last day of the campaign", campaign: campaign(true, "2026-06-01", "2026-06-15", 10), price: "72.00", discount: "8.00"},
+		{name: "disabled campaign", campaign: campaign(false, "2026-06-01", "2026-06-30", 12.5), err: app.ErrCampaignClosed},
+		{name: "campaign not started", campaign: campaign(true, "2026-06-16", "2026-06-30", 12.5), price: "80.00", discount: "0.00"},
+		{name: "campaign ended", campaign: campaign(true, "2026-05-01", "2026-06-14", 12.5), err: app.ErrCampaignClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockGiftCardRepository)
+			campaignRepo := new(MockCampaignRepository)
+			useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+			campaignRepo.On("GetCampaign", ctx, 12).Return(tt.campaign, nil).Once()
+
+			sale, err := useCase.campaignPrice(ctx, 12, money.MustParse("80.00"), now)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, money.MustParse("80.00"), sale.faceValue)
+			assert.Equal(t, money.MustParse(tt.price), sale.price)
+			assert.Equal(t, money.MustParse(tt.discount), sale.discount)
+		})
+	}
+
+	t.Run("unknown campaign", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaignRepo.On("GetCampaign", ctx, 12).Return(nil, nil).Once()
+
+		_, err := useCase.campaignPrice(ctx, 12, money.MustParse("80.00"), now)
+		assert.ErrorIs(t, err, app.ErrCampaignNotFound)
+	})
+}
+
+func TestGiftCardUseCase_CreateGiftCard_CampaignDiscount(t *testing.T) {
+	ctx := context.Background()
+	createReq := request.CreateGiftCardRequest{
+		Type:           "virtual",
+		Balance:        money.MustParse("100.00"),
+		Currency:       "USD",
+		ExpirationDate: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+		Status:         "active",
+		CampaignID:     12,
+	}
+	mockRepo := new(MockGiftCardRepository)
+	campaignRepo := new(MockCampaignRepository)
+	useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+	campaign := &models.Campaign{
+		ID: 12, IsEnabled: true, StartDate: time.Now().AddDate(0, 0, -1), EndDate: time.Now().AddDate(0, 0, 1), DiscountPercentage: 20,
+	}
+	campaignRepo.On("GetCampaign", ctx, 12).Return(campaign, nil).Once()
+	campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(campaign, nil).Once()
+	campaignRepo.On("ReserveCampaignBudget", ctx, uint(12), 1, money.MustParse("100.00")).Return(true, nil).Once()
+	mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+	mockRepo.On("CreateGiftCard", ctx, createReq, mock.Anything, mock.Anything, mock.Anything).Return(&models.GiftCard{ID: 7, Balance: money.MustParse("100.00"), Currency: "USD"}, nil).Once()
+	mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+		return transaction.Amount == money.MustParse("100.00") && *transaction.PricePaid == money.MustParse("80.00")
+	})).Return(nil).Once()
+	mockRepo.On("CreateOrder", ctx, mock.MatchedBy(func(order *models.Order) bool {
+		return *order.CampaignID == 12 && order.FaceValue == money.MustParse("100.00") && order.DiscountAmount == money.MustParse("20.00") && order.TotalAmount == money.MustParse("80.00")
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Order).ID = 31
+	}).Return(nil).Once()
+
+	resp, err := useCase.CreateGiftCard(ctx, createReq)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(31), resp.OrderID)
+	assert.Equal(t, money.MustParse("100.00"), resp.FaceValue)
+	assert.Equal(t, money.MustParse("80.00"), resp.PricePaid)
+	mockRepo.AssertExpectations(t)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUseCase_IssueGiftCards_CampaignDiscount(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	campaignRepo := new(MockCampaignRepository)
+	useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+	// The job itself is not run
+	useCase.background = func(fn func()) {}
+	campaign := &models.Campaign{
+		ID: 12, IsEnabled: true, StartDate: time.Now().AddDate(0, 0, -1), EndDate: time.Now().AddDate(0, 0, 1), DiscountPercentage: 10,
+	}
+	campaignRepo.On("GetCampaign", ctx, 12).Return(campaign, nil).Once()
+	campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(campaign, nil).Once()
+	campaignRepo.On("ReserveCampaignBudget", ctx, uint(12), 4, money.MustParse("100.00")).Return(true, nil).Once()
+	mockRepo.On("CreateIssuanceJob", ctx, mock.MatchedBy(func(job *models.IssuanceJob) bool {
+		return job.UnitPrice == money.MustParse("22.50")
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.IssuanceJob).ID = 9
+	}).Return(nil).Once()
+	mockRepo.On("CreateOrder", ctx, mock.MatchedBy(func(order *models.Order) bool {
+		return *order.IssuanceJobID == 9 && order.Quantity == 4 && order.FaceValue == money.MustParse("100.00") && order.TotalAmount == money.MustParse("90.00")
+	})).Return(nil).Once()
+
+	resp, err := useCase.IssueGiftCards(ctx, request.IssueGiftCardsRequest{
+		Count: 4, Denomination: money.MustParse("25.00"), Currency: "USD", Type: "virtual", ExpirationDate: "2030-06-30", CampaignID: 12,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("22.50"), resp.UnitPrice)
+	mockRepo.AssertExpectations(t)
+	campaignRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUseCase_ReserveCampaignBudget(t *testing.T) {
+	ctx := context.Background()
+	sale := func(maxPerCustomer int) salePrice {
+		return salePrice{
+			faceValue: money.MustParse("25.00"),
+			price:     money.MustParse("25.00"),
+			campaign:  &models.Campaign{ID: 12, MaxCardsPerCustomer: maxPerCustomer},
+		}
+	}
+	open := &models.Campaign{ID: 12, IsEnabled: true, StartDate: time.Now().AddDate(0, 0, -1), EndDate: time.Now().AddDate(0, 0, 1)}
+
+	t.Run("sales without a campaign are not limited", func(t *testing.T) {
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+
+		err := useCase.reserveCampaignBudget(ctx, salePrice{faceValue: money.MustParse("25.00")}, 4, 3)
+		assert.NoError(t, err)
+		campaignRepo.AssertNotCalled(t, "ReserveCampaignBudget", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("campaign ended since it was priced", func(t *testing.T) {
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		ended := &models.Campaign{ID: 12, IsEnabled: true, StartDate: time.Now().AddDate(0, 0, -10), EndDate: time.Now().AddDate(0, 0, -2)}
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(ended, nil).Once()
+
+		err := useCase.reserveCampaignBudget(ctx, sale(0), 4, 3)
+		assert.ErrorIs(t, err, app.ErrCampaignClosed)
+		campaignRepo.AssertNotCalled(t, "ReserveCampaignBudget", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("exhausted campaign", func(t *testing.T) {
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(open, nil).Once()
+		campaignRepo.On("ReserveCampaignBudget", ctx, uint(12), 4, money.MustParse("100.00")).Return(false, nil).Once()
+
+		err := useCase.reserveCampaignBudget(ctx, sale(0), 4, 3)
+		assert.ErrorIs(t, err, app.ErrCampaignExhausted)
+		campaignRepo.AssertExpectations(t)
+	})
+
+	t.Run("customer within the cap", func(t *testing.T) {
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(open, nil).Once()
+		campaignRepo.On("ReserveCampaignBudget", ctx, uint(12), 4, money.MustParse("100.00")).Return(true, nil).Once()
+		campaignRepo.On("CountCustomerCampaignCards", ctx, uint(12), uint(3)).Return(int64(1), nil).Once()
+
+		err := useCase.reserveCampaignBudget(ctx, sale(5), 4, 3)
+		assert.NoError(t, err)
+		campaignRepo.AssertExpectations(t)
+	})
+
+	t.Run("customer over the cap", func(t *testing.T) {
+		campaignRepo := new(MockCampaignRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+		campaignRepo.On("GetCampaignByIDForUpdate", ctx, uint(12)).Return(open, nil).Once()
+		campaignRepo.On("ReserveCampaignBudget", ctx, uint(12), 4, money.MustParse("100.00")).Return(true, nil).Once()
+		campaignRepo.On("CountCustomerCampaignCards", ctx, uint(12), uint(3)).Return(int64(2), nil).Once()
+
+		err := useCase.reserveCampaignBudget(ctx, sale(5), 4, 3)
+		assert.ErrorIs(t, err, app.ErrCampaignCustomerCap)
+		campaignRepo.AssertExpectations(t)
+	})
+}
+
+func TestGiftCardUseCase_FailIssuanceJob_ReleasesCampaignBudget(t *testing.T) {
+	ctx := context.Background()
+	campaignID := uint(12)
+	mockRepo := new(MockGiftCardRepository)
+	campaignRepo := new(MockCampaignRepository)
+	useCase := NewGiftCardUseCase(mockRepo, campaignRepo, noSettings(), new(MockAuditLogRepository), fakeTransactor{}).(*GiftCardUseCase)
+	job := &models.IssuanceJob{Code: "job-1", Count: 10, IssuedCount: 4, Denomination: money.MustParse("25.00"), CampaignID: &campaignID}
+	mockRepo.On("UpdateIssuanceJob", ctx, job).Return(nil).Once()
+	campaignRepo.On("ReleaseCampaignBudget", ctx, uint(12), 6, money.MustParse("150.00")).Return(nil).Once()
+
+	useCase.failIssuanceJob(ctx, job, assert.AnError)
+	assert.Equal(t, models.IssuanceJobStatusFailed, job.Status)
+	mockRepo.AssertExpectations(t)
+	campaignRepo.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RefundTransaction returns money from a prior redemption to its gift card.
+// A redemption can be refunded several times, partially, until the refunds add
+// up to the redeemed amount. A card that was used up is reactivated. The refund
+// is written to the ledger and the audit log in the same transaction as the
+// balance change.
+func (g *GiftCardUseCase) RefundTransaction(ctx context.Context, transactionID uint, data request.RefundTransactionRequest) (response.RefundResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("RefundTransaction use case")
+
+	var result response.RefundResponse
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		original, err := g.giftCardRepo.GetTransactionByID(ctx, transactionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Transaction %d not found: %v", transactionID, err)
+				return customerrors.ErrTransactionNotFound
+			}
+			log.Errorf("Error retrieving transaction %d: %v", transactionID, err)
+			return err
+		}
+		if original.TransactionType != models.TransactionTypeRedemption {
+			log.Warnf("Transaction %d is a %s and cannot be refunded", transactionID, original.TransactionType)
+			return customerrors.ErrTransactionNotRefundable
+		}
+
+		// Locking the card serializes refunds of the same redemption.
+		giftCard, err := g.giftCardRepo.GetGiftCardByIDForUpdate(ctx, original.GiftCardID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %d of transaction %d not found: %v", original.GiftCardID, transactionID, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %d: %v", original.GiftCardID, err)
+			return err
+		}
+
+		refunded, err := g.giftCardRepo.GetRefundedAmount(ctx, original.ID)
+		if err != nil {
+			log.Errorf("Error retrieving refunds of transaction %d: %v", transactionID, err)
+			return err
+		}
+		refundable := -original.Amount - refunded
+
+		amount := data.Amount
+		if amount == 0 {
+			amount = refundable
+		}
+		if amount <= 0 || amount > refundable {
+			log.Warnf("Refund of %s exceeds the refundable %s of transaction %d", amount, refundable, transactionID)
+			return customerrors.ErrRefundExceedsRedemption
+		}
+
+		if giftCard.Status != models.GiftCardStatusActive && giftCard.Status != models.GiftCardStatusUsed {
+			log.Warnf("Gift card %s is not active. Current status: %s", giftCard.GiftCardNumber, giftCard.Status)
+			return customerrors.ErrGiftCardNotActive
+		}
+
+		newBalance := giftCard.Balance + amount
+		if err := g.setBalanceAndStatus(ctx, giftCard, newBalance, models.GiftCardStatusActive, "refund"); err != nil {
+			log.Errorf("Error updating gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+
+		refund := &models.Transaction{
+			GiftCardID:            giftCard.ID,
+			Amount:                amount,
+			BalanceAfter:          newBalance,
+			Currency:              giftCard.Currency,
+			TransactionType:       models.TransactionTypeRefund,
+			Reference:             original.Reference,
+			RefundedTransactionID: &original.ID,
+		}
+		if err := g.giftCardRepo.CreateTransaction(ctx, refund); err != nil {
+			log.Errorf("Error recording refund for gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+
+		action := fmt.Sprintf("refund %s %s to gift card %s for transaction %d", amount, giftCard.Currency, giftCard.GiftCardNumber, original.ID)
+		if data.Reason != "" {
+			action += ": " + data.Reason
+		}
+		if err := g.auditRepo.CreateAuditLog(ctx, &models.AuditLog{Action: action}); err != nil {
+			log.Errorf("Error auditing refund for gift card %s: %v", giftCard.GiftCardNumber, err)
+			return err
+		}
+
+		result = response.RefundResponse{
+			TransactionID:         refund.ID,
+			RefundedTransactionID: original.ID,
+			GiftCardNumber:        giftCard.GiftCardNumber,
+			Amount:                amount,
+			Balance:               newBalance,
+			Currency:              giftCard.Currency,
+			Status:                string(giftCard.Status),
+			RefundableAmount:      refundable - amount,
+		}
+		return nil
+	})
+	if err != nil {
+		return response.RefundResponse{}, err
+	}
+
+	log.Infof("Refunded %s of transaction %d to gift card %s", result.Amount, transactionID, result.GiftCardNumber)
+	return result, nil
+}
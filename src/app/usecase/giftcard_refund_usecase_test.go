@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGiftCardUseCase_RefundTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	redemption := func() *models.Transaction {
+		return &models.Transaction{
+			ID:              12,
+			GiftCardID:      7,
+			Amount:          money.MustParse("-40.00"),
+			BalanceAfter:    money.MustParse("0.00"),
+			Currency:        "USD",
+			TransactionType: models.TransactionTypeRedemption,
+			Reference:       "auth-code-1",
+		}
+	}
+	card := func(balance string, status models.GiftCardStatus) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             7,
+			Code:           "test-code-refund",
+			GiftCardNumber: "GC12345678901237",
+			Balance:        money.MustParse(balance),
+			Currency:       "USD",
+			Status:         status,
+			ExpirationDate: time.Now().AddDate(0, 0, 1),
+		}
+	}
+
+	t.Run("partial refund reactivates a used card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("0.00", "used"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.MustParse("10.00"), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-refund", money.MustParse("25.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(models.GiftCardStatusUsed, models.GiftCardStatusActive))).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.RefundedTransactionID != nil && *transaction.RefundedTransactionID == 12 &&
+				isLedgerEntry(models.TransactionTypeRefund, money.MustParse("25.00"), money.MustParse("25.00"))(transaction)
+		})).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+			return strings.Contains(auditLog.Action, "transaction 12") && strings.HasSuffix(auditLog.Action, ": damaged item")
+		})).Return(nil).Once()
+
+		resp, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{Amount: money.MustParse("25.00"), Reason: "damaged item"})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("25.00"), resp.Balance)
+		assert.Equal(t, "active", resp.Status)
+		assert.Equal(t, money.MustParse("5.00"), resp.RefundableAmount)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("refunds the remaining amount by default", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("10.00", "active"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.MustParse("15.00"), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-refund", money.MustParse("35.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeRefund, money.MustParse("25.00"), money.MustParse("35.00")))).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.AnythingOfType("*models.AuditLog")).Return(nil).Once()
+
+		resp, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("25.00"), resp.Amount)
+		assert.Equal(t, money.Amount(0), resp.RefundableAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cannot refund more than was redeemed", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("0.00", "used"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.MustParse("30.00"), nil).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{Amount: money.MustParse("10.01")})
+		assert.ErrorIs(t, err, app.ErrRefundExceedsRedemption)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fully refunded redemption", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("40.00", "active"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.MustParse("40.00"), nil).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{})
+		assert.ErrorIs(t, err, app.ErrRefundExceedsRedemption)
+	})
+
+	t.Run("only redemptions can be refunded", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		reload := redemption()
+		reload.TransactionType = models.TransactionTypeReload
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(reload, nil).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{})
+		assert.ErrorIs(t, err, app.ErrTransactionNotRefundable)
+	})
+
+	t.Run("refunds are not issued to expired cards", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("0.00", "expired"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.Amount(0), nil).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotActive)
+	})
+
+	t.Run("audit failure fails the refund", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), mockAudit, fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(12)).Return(redemption(), nil).Once()
+		mockRepo.On("GetGiftCardByIDForUpdate", ctx, uint(7)).Return(card("0.00", "used"), nil).Once()
+		mockRepo.On("GetRefundedAmount", ctx, uint(12)).Return(money.Amount(0), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-refund", money.MustParse("40.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.AnythingOfType("*models.GiftCardStatusChange")).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.AnythingOfType("*models.Transaction")).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.AnythingOfType("*models.AuditLog")).Return(errors.New("insert failed")).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 12, request.RefundTransactionRequest{})
+		assert.EqualError(t, err, "insert failed")
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetTransactionByID", ctx, uint(99)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.RefundTransaction(ctx, 99, request.RefundTransactionRequest{})
+		assert.ErrorIs(t, err, app.ErrTransactionNotFound)
+	})
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Settings limiting reloads. Limits that are not configured do not apply.
+const (
+	// reloadMaxBalanceSetting caps the balance a card may reach through reloads.
+	reloadMaxBalanceSetting = "reload.max_balance"
+	// reloadDailyLimitSetting caps the total reloaded on one card per calendar day.
+	reloadDailyLimitSetting = "reload.daily_limit"
+	// reloadPromotionalSetting allows reloading promotional cards; it defaults to false.
+	reloadPromotionalSetting = "reload.allow_promotional"
+)
+
+// reloadLimits holds the reload settings read for one request.
+type reloadLimits struct {
+	maxBalance       money.Amount
+	hasMaxBalance    bool
+	dailyLimit       money.Amount
+	hasDailyLimit    bool
+	allowPromotional bool
+}
+
+// ReloadGiftCard adds funds to a gift card as a ledger credit. A card that was
+// used up is reactivated. The reload is rejected when it would take the card
+// over the configured maximum balance or daily reload limit.
+func (g *GiftCardUseCase) ReloadGiftCard(ctx context.Context, giftCardNumber string, data request.ReloadGiftCardRequest) (response.ReloadGiftCardResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ReloadGiftCard use case")
+
+	limits, err := g.reloadLimits(ctx)
+	if err != nil {
+		log.Errorf("Error reading reload limits: %v", err)
+		return response.ReloadGiftCardResponse{}, err
+	}
+
+	var result response.ReloadGiftCardResponse
+	// reloadErr carries a rejection whose side effects must still be committed.
+	var reloadErr error
+	err = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		if giftCard.IsPromotional && !limits.allowPromotional {
+			log.Warnf("Gift card %s is promotional and cannot be reloaded", giftCardNumber)
+			return customerrors.ErrGiftCardNotReloadable
+		}
+		if giftCard.Status != models.GiftCardStatusActive && giftCard.Status != models.GiftCardStatusUsed {
+			log.Warnf("Gift card %s cannot be reloaded. Current status: %s", giftCardNumber, giftCard.Status)
+			return customerrors.ErrGiftCardNotActive
+		}
+		expired, err := g.pastExpiry(ctx, giftCard, time.Now())
+		if err != nil {
+			return err
+		}
+		if expired {
+			log.Warnf("Gift card %s has expired on %s", giftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"))
+			if err := g.expireGiftCard(ctx, giftCard, "expiration date passed"); err != nil {
+				log.Errorf("Failed to update status to 'expired' for gift card %s (code: %s): %v", giftCardNumber, giftCard.Code, err)
+				return customerrors.ErrGiftCardExpired
+			}
+			reloadErr = customerrors.ErrGiftCardExpired
+			return nil
+		}
+
+		amount := data.Amount
+		if data.Currency != "" && data.Currency != giftCard.Currency {
+			amount, err = g.convertAmount(ctx, data.Amount, data.Currency, giftCard.Currency)
+			if err != nil {
+				log.Warnf("Cannot reload %s %s on gift card %s in %s: %v", data.Amount, data.Currency, giftCardNumber, giftCard.Currency, err)
+				return err
+			}
+		}
+
+		newBalance := giftCard.Balance + amount
+		if limits.hasMaxBalance && newBalance > limits.maxBalance {
+			log.Warnf("Reload of %s on gift card %s exceeds the maximum balance %s", amount, giftCardNumber, limits.maxBalance)
+			return customerrors.ErrMaxBalanceExceeded
+		}
+
+		if limits.hasDailyLimit {
+			now := time.Now()
+			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			reloaded, err := g.giftCardRepo.GetTransactionTotalSince(ctx, giftCard.ID, models.TransactionTypeReload, startOfDay)
+			if err != nil {
+				log.Errorf("Error retrieving today's reloads for gift card %s: %v", giftCardNumber, err)
+				return err
+			}
+			if reloaded+amount > limits.dailyLimit {
+				log.Warnf("Reload of %s on gift card %s exceeds the daily limit %s (already reloaded %s)", amount, giftCardNumber, limits.dailyLimit, reloaded)
+				return customerrors.ErrReloadLimitExceeded
+			}
+		}
+
+		if err := g.setBalanceAndStatus(ctx, giftCard, newBalance, models.GiftCardStatusActive, "reload"); err != nil {
+			log.Errorf("Error updating gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		transaction := &models.Transaction{
+			GiftCardID:      giftCard.ID,
+			Amount:          amount,
+			BalanceAfter:    newBalance,
+			Currency:        giftCard.Currency,
+			TransactionType: models.TransactionTypeReload,
+			Reference:       uuid.NewString(),
+		}
+		if err := g.giftCardRepo.CreateTransaction(ctx, transaction); err != nil {
+			log.Errorf("Error recording reload for gift card %s: %v", giftCardNumber, err)
+			return err
+		}
+
+		result = response.ReloadGiftCardResponse{
+			GiftCardNumber: giftCard.GiftCardNumber,
+			TransactionID:  transaction.ID,
+			Amount:         amount,
+			Balance:        newBalance,
+			Currency:       giftCard.Currency,
+			Status:         string(giftCard.Status),
+		}
+		return nil
+	})
+	if err != nil {
+		return response.ReloadGiftCardResponse{}, err
+	}
+	if reloadErr != nil {
+		return response.ReloadGiftCardResponse{}, reloadErr
+	}
+
+	log.Infof("Reloaded %s on gift card %s. New balance: %s", result.Amount, giftCardNumber, result.Balance)
+	return result, nil
+}
+
+func (g *GiftCardUseCase) reloadLimits(ctx context.Context) (reloadLimits, error) {
+	var limits reloadLimits
+	var err error
+
+	if limits.maxBalance, limits.hasMaxBalance, err = amountSetting(ctx, g.settingRepo, reloadMaxBalanceSetting); err != nil {
+		return reloadLimits{}, err
+	}
+	if limits.dailyLimit, limits.hasDailyLimit, err = amountSetting(ctx, g.settingRepo, reloadDailyLimitSetting); err != nil {
+		return reloadLimits{}, err
+	}
+	if limits.allowPromotional, err = boolSetting(ctx, g.settingRepo, reloadPromotionalSetting, false); err != nil {
+		return reloadLimits{}, err
+	}
+	return limits, nil
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGiftCardUseCase_ReloadGiftCard(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+
+	card := func(balance string, status models.GiftCardStatus, promotional bool) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             7,
+			Code:           "test-code-reload",
+			GiftCardNumber: cardNumber,
+			Balance:        money.MustParse(balance),
+			Currency:       "USD",
+			Status:         status,
+			IsPromotional:  promotional,
+			ExpirationDate: time.Now().AddDate(0, 0, 1),
+		}
+	}
+	settings := func(values map[string]string) *MockSettingRepository {
+		mockSettings := new(MockSettingRepository)
+		for _, name := range []string{"reload.max_balance", "reload.daily_limit", "reload.allow_promotional"} {
+			if value, ok := values[name]; ok {
+				mockSettings.On("GetSetting", ctx, name).Return(&models.Setting{Name: name, Value: value}, nil).Once()
+			} else {
+				mockSettings.On("GetSetting", ctx, name).Return(nil, nil).Once()
+			}
+		}
+		return mockSettings
+	}
+
+	t.Run("credits the card and reactivates it", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(nil), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("0.00", "used", false), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-reload", money.MustParse("50.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(models.GiftCardStatusUsed, models.GiftCardStatusActive))).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeReload, money.MustParse("50.00"), money.MustParse("50.00")))).Return(nil).Once()
+
+		resp, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("50.00")})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("50.00"), resp.Balance)
+		assert.Equal(t, "active", resp.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a reload over the maximum balance", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(map[string]string{"reload.max_balance": "500.00"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("450.00", "active", false), nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("50.01")})
+		assert.ErrorIs(t, err, app.ErrMaxBalanceExceeded)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a reload over the daily limit", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(map[string]string{"reload.daily_limit": "200.00"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("10.00", "active", false), nil).Once()
+		mockRepo.On("GetTransactionTotalSince", ctx, uint(7), models.TransactionTypeReload, mock.Anything).Return(money.MustParse("150.00"), nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("60.00")})
+		assert.ErrorIs(t, err, app.ErrReloadLimitExceeded)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("allows a reload within the daily limit", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(map[string]string{"reload.daily_limit": "200.00"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("10.00", "active", false), nil).Once()
+		mockRepo.On("GetTransactionTotalSince", ctx, uint(7), models.TransactionTypeReload, mock.Anything).Return(money.MustParse("150.00"), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-reload", money.MustParse("60.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.AnythingOfType("*models.Transaction")).Return(nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("50.00")})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("promotional cards are not reloadable by default", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(nil), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("10.00", "active", true), nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotReloadable)
+	})
+
+	t.Run("promotional cards can be made reloadable", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(map[string]string{"reload.allow_promotional": "true"}), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("10.00", "active", true), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-reload", money.MustParse("20.00"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.AnythingOfType("*models.Transaction")).Return(nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("10.00")})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("blocked cards cannot be reloaded", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), settings(nil), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(card("10.00", "inactive", false), nil).Once()
+
+		_, err := useCase.ReloadGiftCard(ctx, cardNumber, request.ReloadGiftCardRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotActive)
+	})
+}
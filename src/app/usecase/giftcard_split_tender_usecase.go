@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// splitTenderDraw is the amount planned to be redeemed from one card, in the
+// card currency.
+type splitTenderDraw struct {
+	giftCard *models.GiftCard
+	amount   money.Amount
+}
+
+// RedeemSplitTender pays an amount with several cards, drawing from each in
+// the order given until the amount is covered. It runs the same checks as
+// UseGiftCardAmount on every card it draws from, and all the redemptions
+// commit or roll back together. Whatever the cards cannot cover is returned
+// as the remaining amount, to be charged to another tender.
+func (g *GiftCardUseCase) RedeemSplitTender(ctx context.Context, data request.SplitTenderRequest) (response.SplitTenderResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("RedeemSplitTender use case")
+
+	for _, card := range data.Cards {
+		if err := g.checkGiftCardNumber(ctx, card.GiftCardNumber); err != nil {
+			return response.SplitTenderResponse{}, err
+		}
+	}
+
+	var result response.SplitTenderResponse
+	// redeemErr carries a rejection whose side effects must still be committed.
+	var redeemErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		giftCards, err := g.lockSplitTenderCards(ctx, data.Cards)
+		if err != nil {
+			return err
+		}
+
+		currency := data.Currency
+		if currency == "" {
+			currency = giftCards[0].Currency
+		}
+
+		// Every draw is planned before any funds move, so a rejection that
+		// must be committed, such as a wrong PIN, leaves all balances untouched.
+		remaining := data.Amount
+		var draws []splitTenderDraw
+		for i, giftCard := range giftCards {
+			if remaining == 0 {
+				break
+			}
+
+			if commit, err := g.verifyPin(ctx, giftCard, data.Cards[i].Pin); err != nil {
+				if commit {
+					redeemErr = err
+					return nil
+				}
+				return err
+			}
+			if commit, err := g.checkRedeemable(ctx, giftCard); err != nil {
+				if commit {
+					redeemErr = err
+					return nil
+				}
+				return err
+			}
+
+			available, err := g.availableBalance(ctx, giftCard)
+			if err != nil {
+				log.Errorf("Error computing available balance for gift card %s: %v", giftCard.GiftCardNumber, err)
+				return err
+			}
+			if available <= 0 {
+				log.Infof("Gift card %s has no available balance, skipping it", giftCard.GiftCardNumber)
+				continue
+			}
+			if err := checkUsesLeft(ctx, giftCard); err != nil {
+				return err
+			}
+
+			// owed is the remaining amount in the card currency
+			owed := remaining
+			if currency != giftCard.Currency {
+				owed, err = g.convertAmount(ctx, remaining, currency, giftCard.Currency)
+				if err != nil {
+					log.Warnf("Cannot redeem %s %s from gift card %s in %s: %v", remaining, currency, giftCard.GiftCardNumber, giftCard.Currency, err)
+					return err
+				}
+			}
+			if owed <= 0 {
+				continue
+			}
+
+			amount := min(owed, available)
+			covered := remaining
+			if amount < owed {
+				// The card covers the same share of the remaining amount as of owed
+				covered, err = remaining.Mul(big.NewRat(amount.MinorUnits(), owed.MinorUnits()))
+				if err != nil {
+					return err
+				}
+			}
+			draws = append(draws, splitTenderDraw{giftCard: giftCard, amount: amount})
+			remaining -= covered
+		}
+
+		result = response.SplitTenderResponse{
+			Amount:    data.Amount,
+			Currency:  currency,
+			Remaining: remaining,
+			Draws:     []response.SplitTenderDraw{},
+		}
+		for _, draw := range draws {
+			authorization, err := g.authorize(ctx, draw.giftCard, draw.amount, time.Now())
+			if err != nil {
+				log.Errorf("Failed to authorize %s on gift card %s: %v", draw.amount, draw.giftCard.GiftCardNumber, err)
+				return err
+			}
+			transaction, err := g.capture(ctx, draw.giftCard, authorization, draw.amount)
+			if err != nil {
+				log.Errorf("Failed to capture %s on gift card %s: %v", draw.amount, draw.giftCard.GiftCardNumber, err)
+				return err
+			}
+
+			result.Draws = append(result.Draws, response.SplitTenderDraw{
+				GiftCardNumber: draw.giftCard.GiftCardNumber,
+				Amount:         draw.amount,
+				Currency:       draw.giftCard.Currency,
+				Balance:        draw.giftCard.Balance,
+				TransactionID:  transaction.ID,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return response.SplitTenderResponse{}, err
+	}
+	if redeemErr != nil {
+		return response.SplitTenderResponse{}, redeemErr
+	}
+
+	log.Infof("Split tender of %s %s redeemed from %d gift cards, %s remaining", result.Amount, result.Currency, len(result.Draws), result.Remaining)
+	return result, nil
+}
+
+// lockSplitTenderCards locks the cards of a split tender and returns them in
+// the order given. Rows are locked in card number order, so concurrent split
+// tenders sharing cards cannot deadlock.
+func (g *GiftCardUseCase) lockSplitTenderCards(ctx context.Context, cards []request.SplitTenderCard) ([]*models.GiftCard, error) {
+	log := logrus.WithContext(ctx)
+
+	numbers := make([]string, len(cards))
+	for i, card := range cards {
+		numbers[i] = card.GiftCardNumber
+	}
+	sort.Strings(numbers)
+
+	locked := make(map[string]*models.GiftCard, len(numbers))
+	for _, number := range numbers {
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, number)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", number, err)
+				return nil, customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", number, err)
+			return nil, err
+		}
+		locked[number] = giftCard
+	}
+
+	giftCards := make([]*models.GiftCard, len(cards))
+	for i, card := range cards {
+		giftCards[i] = locked[card.GiftCardNumber]
+	}
+	return giftCards, nil
+}
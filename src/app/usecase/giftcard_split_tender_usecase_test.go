@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGiftCardUseCase_RedeemSplitTender(t *testing.T) {
+	ctx := context.Background()
+	first, second := "GC99999999999902", "GC12345678901237"
+	card := func(id uint, number string, balance string, currency string) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             id,
+			Code:           "code-" + number,
+			GiftCardNumber: number,
+			Balance:        money.MustParse(balance),
+			Currency:       currency,
+			Status:         models.GiftCardStatusActive,
+			ExpirationDate: time.Now().AddDate(1, 0, 0),
+		}
+	}
+	expectCapture := func(mockRepo *MockGiftCardRepository, giftCard *models.GiftCard, amount string, balanceAfter string) {
+		mockRepo.On("CreateAuthorization", ctx, mock.MatchedBy(func(authorization *models.Authorization) bool {
+			return authorization.GiftCardID == giftCard.ID && authorization.Amount == money.MustParse(amount)
+		})).Return(nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, giftCard.Code, money.MustParse(balanceAfter), mock.Anything).Return(nil).Once()
+		mockRepo.On("RecordGiftCardUse", ctx, giftCard.Code, mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.GiftCardID == giftCard.ID && isLedgerEntry(models.TransactionTypeRedemption, -money.MustParse(amount), money.MustParse(balanceAfter))(transaction)
+		})).Return(nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(func(authorization *models.Authorization) bool {
+			return authorization.GiftCardID == giftCard.ID
+		})).Return(nil).Once()
+	}
+	tender := func(amount string, numbers ...string) request.SplitTenderRequest {
+		data := request.SplitTenderRequest{Amount: money.MustParse(amount)}
+		for _, number := range numbers {
+			data.Cards = append(data.Cards, request.SplitTenderCard{GiftCardNumber: number})
+		}
+		return data
+	}
+
+	t.Run("draws from each card in order until the total is covered", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		firstCard, secondCard := card(1, first, "30.00", "USD"), card(2, second, "50.00", "USD")
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, first).Return(firstCard, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, second).Return(secondCard, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(1), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(2), mock.Anything).Return(money.MustParse("5.00"), nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(models.GiftCardStatusActive, models.GiftCardStatusUsed))).Return(nil).Once()
+		expectCapture(mockRepo, firstCard, "30.00", "0.00")
+		expectCapture(mockRepo, secondCard, "30.00", "20.00")
+
+		resp, err := useCase.RedeemSplitTender(ctx, tender("60.00", first, second))
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", resp.Currency)
+		assert.Equal(t, money.Amount(0), resp.Remaining)
+		if assert.Len(t, resp.Draws, 2) {
+			assert.Equal(t, first, resp.Draws[0].GiftCardNumber)
+			assert.Equal(t, money.MustParse("30.00"), resp.Draws[0].Amount)
+			assert.Equal(t, money.MustParse("20.00"), resp.Draws[1].Balance)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports what the cards cannot cover", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		firstCard, secondCard := card(1, first, "30.00", "USD"), card(2, second, "0.00", "USD")
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, first).Return(firstCard, nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, second).Return(secondCard, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(1), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(2), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.Anything).Return(nil).Once()
+		expectCapture(mockRepo, firstCard, "30.00", "0.00")
+
+		resp, err := useCase.RedeemSplitTender(ctx, tender("45.00", first, second))
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("15.00"), resp.Remaining)
+		assert.Len(t, resp.Draws, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("converts the remaining amount into each card currency", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), policySettings(map[string]string{"exchange_rate.USD.EUR": "0.5"}), new(MockAuditLogRepository), fakeTransactor{})
+		euroCard := card(1, first, "20.00", "EUR")
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, first).Return(euroCard, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(1), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.Anything).Return(nil).Once()
+		expectCapture(mockRepo, euroCard, "20.00", "0.00")
+
+		data := tender("100.00", first)
+		data.Currency = "USD"
+		resp, err := useCase.RedeemSplitTender(ctx, data)
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("60.00"), resp.Remaining)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong PIN on a later card moves no funds", func(t *testing.T) {
+		pinHash, err := pin.Hash("123456")
+		require.NoError(t, err)
+		mockRepo := new(MockGiftCardRepository)
+		mockAudit := new(MockAuditLogRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), mockAudit, fakeTransactor{})
+		secondCard := card(2, second, "50.00", "USD")
+		secondCard.PinHash = pinHash
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, first).Return(card(1, first, "30.00", "USD"), nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, second).Return(secondCard, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(1), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("UpdateGiftCardPin", ctx, secondCard.Code, pinHash, 1, (*time.Time)(nil)).Return(nil).Once()
+		mockAudit.On("CreateAuditLog", ctx, mock.Anything).Return(nil).Once()
+
+		data := tender("60.00", first, second)
+		data.Cards[1].Pin = "000000"
+		_, err = useCase.RedeemSplitTender(ctx, data)
+		assert.ErrorIs(t, err, app.ErrInvalidPin)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown card fails the whole tender", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, second).Return(card(2, second, "50.00", "USD"), nil).Once()
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, first).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.RedeemSplitTender(ctx, tender("60.00", second, first))
+		assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+		mockRepo.AssertNotCalled(t, "CreateAuthorization", mock.Anything, mock.Anything)
+	})
+}
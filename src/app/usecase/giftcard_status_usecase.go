@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/money"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// giftCardTransitions lists the statuses each status may move to.
+// Expired and cancelled are final. Some transitions are only made by the
+// system, see manualTransitionsDenied.
+var giftCardTransitions = map[models.GiftCardStatus][]models.GiftCardStatus{
+	models.GiftCardStatusIssued:    {models.GiftCardStatusActive, models.GiftCardStatusCancelled},
+	models.GiftCardStatusActive:    {models.GiftCardStatusSuspended, models.GiftCardStatusUsed, models.GiftCardStatusExpired, models.GiftCardStatusCancelled},
+	models.GiftCardStatusSuspended: {models.GiftCardStatusActive, models.GiftCardStatusExpired, models.GiftCardStatusCancelled},
+	models.GiftCardStatusUsed:      {models.GiftCardStatusActive, models.GiftCardStatusExpired, models.GiftCardStatusCancelled},
+}
+
+// manualTransitionsDenied are the transitions of giftCardTransitions that
+// ChangeGiftCardStatus refuses, as only the use cases that record them may
+// make them: ActivateGiftCard activates issued cards, and a refund or reload
+// reactivates used cards by putting a balance back through the ledger.
+var manualTransitionsDenied = map[models.GiftCardStatus]models.GiftCardStatus{
+	models.GiftCardStatusIssued: models.GiftCardStatusActive,
+	models.GiftCardStatusUsed:   models.GiftCardStatusActive,
+}
+
+// canTransition reports whether a gift card may move from one status to another.
+func canTransition(from models.GiftCardStatus, to models.GiftCardStatus) bool {
+	for _, allowed := range giftCardTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeGiftCardStatus applies a manual lifecycle transition, such as
+// suspending or cancelling a card, if the transition table allows it. Issued
+// and used cards cannot be made active here, see manualTransitionsDenied.
+func (g *GiftCardUseCase) ChangeGiftCardStatus(ctx context.Context, id string, data request.ChangeGiftCardStatusRequest) (response.GetAllGiftCardResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("ChangeGiftCardStatus use case") // id is the gift card code
+
+	var giftCard *models.GiftCard
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		giftCard, err = g.giftCardRepo.GetGiftCardByCodeForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card with code %s not found: %v", id, err)
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error getting gift card by code %s: %v", id, err)
+			return err
+		}
+
+		status := models.GiftCardStatus(data.Status)
+		if denied, ok := manualTransitionsDenied[giftCard.Status]; ok && denied == status {
+			log.Warnf("Gift card %s cannot be moved from %s to %s manually", id, giftCard.Status, status)
+			return customerrors.ErrInvalidStatusTransition
+		}
+		return g.setBalanceAndStatus(ctx, giftCard, giftCard.Balance, status, data.Reason)
+	})
+	if err != nil {
+		return response.GetAllGiftCardResponse{}, err
+	}
+
+	log.Infof("Gift card %s is now %s", id, giftCard.Status)
+	return response.GetAllGiftCardResponse{
+		ID:             giftCard.ID,
+		GiftCardNumber: giftCard.GiftCardNumber,
+		Type:           giftCard.Type,
+		Balance:        giftCard.Balance,
+		Currency:       giftCard.Currency,
+		ExpirationDate: giftCard.ExpirationDate.Format("2006-01-02"),
+		Status:         string(giftCard.Status),
+		IsPromotional:  giftCard.IsPromotional,
+	}, nil
+}
+
+// setBalanceAndStatus persists a new balance and status for a locked gift card.
+// A status change must be allowed by giftCardTransitions and is recorded, with
+// reason, in the card's status history. giftCard is updated in place.
+func (g *GiftCardUseCase) setBalanceAndStatus(ctx context.Context, giftCard *models.GiftCard, balance money.Amount, status models.GiftCardStatus, reason string) error {
+	log := logrus.WithContext(ctx)
+
+	changed := status != giftCard.Status
+	if changed && !canTransition(giftCard.Status, status) {
+		log.Warnf("Gift card %s cannot move from %s to %s", giftCard.GiftCardNumber, giftCard.Status, status)
+		return customerrors.ErrInvalidStatusTransition
+	}
+
+	if err := g.giftCardRepo.UpdateGiftCardBalanceAndStatus(ctx, giftCard.Code, balance, status); err != nil {
+		return err
+	}
+
+	if changed {
+		if err := g.giftCardRepo.CreateGiftCardStatusChange(ctx, &models.GiftCardStatusChange{
+			GiftCardID: giftCard.ID,
+			FromStatus: giftCard.Status,
+			ToStatus:   status,
+			Reason:     reason,
+		}); err != nil {
+			return err
+		}
+		log.Infof("Gift card %s moved from %s to %s", giftCard.GiftCardNumber, giftCard.Status, status)
+	}
+
+	giftCard.Balance = balance
+	giftCard.Status = status
+	return nil
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     models.GiftCardStatus
+		to       models.GiftCardStatus
+		expected bool
+	}{
+		{models.GiftCardStatusIssued, models.GiftCardStatusActive, true},
+		{models.GiftCardStatusIssued, models.GiftCardStatusUsed, false},
+		{models.GiftCardStatusActive, models.GiftCardStatusSuspended, true},
+		{models.GiftCardStatusActive, models.GiftCardStatusIssued, false},
+		{models.GiftCardStatusSuspended, models.GiftCardStatusActive, true},
+		{models.GiftCardStatusSuspended, models.GiftCardStatusUsed, false},
+		{models.GiftCardStatusUsed, models.GiftCardStatusActive, true},
+		{models.GiftCardStatusExpired, models.GiftCardStatusActive, false},
+		{models.GiftCardStatusCancelled, models.GiftCardStatusActive, false},
+		{"inactive", models.GiftCardStatusActive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, canTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestGiftCardUseCase_ChangeGiftCardStatus(t *testing.T) {
+	ctx := context.Background()
+	code := "test-code-status"
+	card := func(status models.GiftCardStatus) *models.GiftCard {
+		return &models.GiftCard{
+			ID:             7,
+			Code:           code,
+			GiftCardNumber: "GC12345678901237",
+			Balance:        money.MustParse("25.00"),
+			Currency:       "USD",
+			Status:         status,
+			ExpirationDate: time.Now().AddDate(0, 0, 1),
+		}
+	}
+
+	t.Run("suspends an active card and records the transition", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(card(models.GiftCardStatusActive), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, code, money.MustParse("25.00"), models.GiftCardStatusSuspended).Return(nil).Once()
+		mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(func(change *models.GiftCardStatusChange) bool {
+			return isStatusChange(models.GiftCardStatusActive, models.GiftCardStatusSuspended)(change) && change.Reason == "reported stolen"
+		})).Return(nil).Once()
+
+		resp, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "suspended", Reason: "reported stolen"})
+		assert.NoError(t, err)
+		assert.Equal(t, "suspended", resp.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a transition out of a final status", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(card(models.GiftCardStatusCancelled), nil).Once()
+
+		_, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "active"})
+		assert.ErrorIs(t, err, app.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects activating an issued card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(card(models.GiftCardStatusIssued), nil).Once()
+
+		_, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "active"})
+		assert.ErrorIs(t, err, app.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateGiftCardStatusChange", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects reactivating a used card", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(card(models.GiftCardStatusUsed), nil).Once()
+
+		_, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "active"})
+		assert.ErrorIs(t, err, app.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("setting the current status is a no-op", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(card(models.GiftCardStatusActive), nil).Once()
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, code, money.MustParse("25.00"), models.GiftCardStatusActive).Return(nil).Once()
+
+		_, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "active"})
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "CreateGiftCardStatusChange", mock.Anything, mock.Anything)
+	})
+
+	t.Run("gift card not found", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCodeForUpdate", ctx, code).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.ChangeGiftCardStatus(ctx, code, request.ChangeGiftCardStatusRequest{Status: "suspended"})
+		assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+	})
+}
@@ -2,13 +2,18 @@ package usecase
 
 import (
 	customerrors "GiftWize/src/app"
+	"GiftWize/src/entity/models"
 	"GiftWize/src/entity/request"
 	"GiftWize/src/entity/response"
 	"GiftWize/src/infreaestructure/repository"
 	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"math/rand"
 	"time"
 
@@ -19,46 +24,86 @@ import (
 
 // IGiftCardUseCase defines the interface for gift card use case operations.
 type IGiftCardUseCase interface {
-	GenerateGiftCardNumber(ctx context.Context) (string, error)
-	CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest) error
+	GenerateGiftCardNumber(ctx context.Context, cardType string, campaignID uint) (string, error)
+	CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest) (response.CreateGiftCardResponse, error)
 	GetAllGiftCardList(ctx context.Context) ([]response.GetAllGiftCardResponse, error)
 	GetGiftCardByID(ctx context.Context, id string) (response.GetAllGiftCardResponse, error) // id here is the Code
 	UpdateGiftCard(ctx context.Context, id string, data request.UpdateGiftCardRequest) error // id here is the Code
 	FullTextSearchGiftCard(ctx context.Context, query string) ([]response.GetAllGiftCardResponse, error)
 	DeleteGiftCard(ctx context.Context, id string) error // id here is the Code
-	UseGiftCardAmount(ctx context.Context, giftCardNumber string, amount float64) (response.UseGiftCardAmountResponse, error)
+	UseGiftCardAmount(ctx context.Context, giftCardNumber string, data request.UseGiftCardAmountRequest) (response.UseGiftCardAmountResponse, error)
+	RedeemSplitTender(ctx context.Context, data request.SplitTenderRequest) (response.SplitTenderResponse, error)
+	GetGiftCardTransactions(ctx context.Context, code string, page int, pageSize int) (response.TransactionListResponse, error)
+	AuthorizeGiftCardAmount(ctx context.Context, giftCardNumber string, data request.AuthorizeGiftCardRequest) (response.AuthorizationResponse, error)
+	CaptureAuthorization(ctx context.Context, code string, data request.CaptureAuthorizationRequest) (response.AuthorizationResponse, error)
+	VoidAuthorization(ctx context.Context, code string) (response.AuthorizationResponse, error)
+	RefundTransaction(ctx context.Context, transactionID uint, data request.RefundTransactionRequest) (response.RefundResponse, error)
+	ReloadGiftCard(ctx context.Context, giftCardNumber string, data request.ReloadGiftCardRequest) (response.ReloadGiftCardResponse, error)
+	ChangeGiftCardStatus(ctx context.Context, id string, data request.ChangeGiftCardStatusRequest) (response.GetAllGiftCardResponse, error) // id here is the Code
+	ActivateGiftCard(ctx context.Context, giftCardNumber string, data request.ActivateGiftCardRequest) (response.ActivateGiftCardResponse, error)
+	GetGiftCardBalance(ctx context.Context, giftCardNumber string, data request.GiftCardBalanceRequest) (response.GiftCardBalanceResponse, error)
+	InquireGiftCardBalance(ctx context.Context, giftCardNumber string, data request.BalanceInquiryRequest) (response.BalanceInquiryResponse, error)
+	ResetGiftCardPin(ctx context.Context, id string, data request.ResetGiftCardPinRequest) (response.GiftCardPinResponse, error) // id here is the Code
+	ExtendGiftCardExpiration(ctx context.Context, id string, data request.ExtendGiftCardExpirationRequest) (response.GiftCardExpirationResponse, error) // id here is the Code
+	IssueGiftCards(ctx context.Context, data request.IssueGiftCardsRequest) (response.IssuanceJobResponse, error)
+	GetIssuanceJob(ctx context.Context, code string) (response.IssuanceJobResponse, error)
+	ExportIssuanceJobCSV(ctx context.Context, code string, w io.Writer) error
+	ImportGiftCardsCSV(ctx context.Context, r io.Reader, dryRun bool) (response.GiftCardImportResponse, error)
+	ExportGiftCardsCSV(ctx context.Context, filter request.GiftCardExportFilter, w io.Writer) error
+	StartExpirySweeper(ctx context.Context)
+	StartCampaignScheduler(ctx context.Context)
+	StartIssuanceJobRecovery(ctx context.Context)
+	GetExpirySweepStatus(ctx context.Context) (response.ExpirySweepStatusResponse, error)
 }
 
 type GiftCardUseCase struct {
 	giftCardRepo repository.IGiftCardRepository // Depends on the interface
+	campaignRepo repository.ICampaignRepository
+	settingRepo  repository.ISettingRepository
+	auditRepo    repository.IAuditLogRepository
+	transactor   repository.ITransactor
+	// background runs long jobs such as bulk issuance outside the request
+	background func(fn func())
 }
 
 // NewGiftCardUseCase creates a new GiftCardUseCase instance.
 // It accepts IGiftCardRepository and returns IGiftCardUseCase.
-func NewGiftCardUseCase(giftCardRepo repository.IGiftCardRepository) IGiftCardUseCase {
+func NewGiftCardUseCase(giftCardRepo repository.IGiftCardRepository, campaignRepo repository.ICampaignRepository, settingRepo repository.ISettingRepository, auditRepo repository.IAuditLogRepository, transactor repository.ITransactor) IGiftCardUseCase {
 	return &GiftCardUseCase{
 		giftCardRepo: giftCardRepo,
+		campaignRepo: campaignRepo,
+		settingRepo:  settingRepo,
+		auditRepo:    auditRepo,
+		transactor:   transactor,
+		background:   func(fn func()) { go fn() },
 	}
 }
 
 // Ensure GiftCardUseCase implements IGiftCardUseCase
 var _ IGiftCardUseCase = (*GiftCardUseCase)(nil)
 
-// This is the actual implementation, the empty one above will be removed.
-func (c *GiftCardUseCase) GenerateGiftCardNumber(ctx context.Context) (string, error) {
+// GenerateGiftCardNumber draws an unused card number with the prefix
+// configured for the campaign or, failing that, for the card type.
+func (c *GiftCardUseCase) GenerateGiftCardNumber(ctx context.Context, cardType string, campaignID uint) (string, error) {
 	log := logrus.WithContext(ctx)
 	log.Info("GenerateGiftCardNumber use case")
 
-	const (
-		giftcardLength = 16
-		maxAttempts    = 100
-		prefix         = "GC"
-	)
+	const maxAttempts = 100
+
+	prefix, err := c.giftCardNumberPrefix(ctx, cardType, campaignID)
+	if err != nil {
+		log.Errorf("Error reading gift card number prefix: %v", err)
+		return "", err
+	}
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
 	for attempts := 0; attempts < maxAttempts; attempts++ {
-		giftCardNumber := generators.GenerateGiftcardNumber(giftcardLength, prefix)
+		giftCardNumber, err := generators.GenerateGiftcardNumber(generators.NumberLength, prefix)
+		if err != nil {
+			log.Errorf("Error generating gift card number with prefix %q: %v", prefix, err)
+			return "", err
+		}
 		exists, err := c.giftCardRepo.GiftCardNumberExists(ctx, giftCardNumber)
 		if err != nil {
 			log.Errorf("Error checking gift card number existence: %v", err)
@@ -75,7 +120,9 @@ func (c *GiftCardUseCase) GenerateGiftCardNumber(ctx context.Context) (string, e
 	return "", errors.New("no se pudo generar un número único después de los intentos máximos")
 }
 
-func (g *GiftCardUseCase) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest) error {
+// CreateGiftCard issues a new card with a random PIN. Only the PIN hash is
+// stored; the PIN itself is returned once in the response.
+func (g *GiftCardUseCase) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest) (response.CreateGiftCardResponse, error) {
 	log := logrus.WithContext(ctx)
 	log.Info("CreateGiftCard use case")
 
@@ -88,25 +135,91 @@ func (g *GiftCardUseCase) CreateGiftCard(ctx context.Context, data request.Creat
 	generatedCode, err := uuid.NewRandom() // Generates a random UUID
 	if err != nil {
 		log.Errorf("Error generating UUID for Code: %v", err)
-		return err
+		return response.CreateGiftCardResponse{}, err
 	}
 	giftCardCode := generatedCode.String()
 
-	giftCardNumber, err := g.GenerateGiftCardNumber(ctx)
+	// The card carries the full balance; the campaign discount lowers the price paid
+	sale, err := g.campaignPrice(ctx, data.CampaignID, data.Balance, time.Now())
+	if err != nil {
+		log.Errorf("Error pricing gift card: %v", err)
+		return response.CreateGiftCardResponse{}, err
+	}
+
+	giftCardNumber, err := g.GenerateGiftCardNumber(ctx, data.Type, data.CampaignID)
 	if err != nil {
 		log.Errorf("Error generating gift card number: %v", err)
-		return err
+		return response.CreateGiftCardResponse{}, err
 	}
 
-	// Pass giftCardCode as the 'uuid' parameter to the repository, which maps to 'Code' in the DB model
-	err = g.giftCardRepo.CreateGiftCard(ctx, data, giftCardCode, giftCardNumber)
+	giftCardPin, err := pin.Generate()
+	if err != nil {
+		log.Errorf("Error generating gift card PIN: %v", err)
+		return response.CreateGiftCardResponse{}, err
+	}
+	pinHash, err := pin.Hash(giftCardPin)
+	if err != nil {
+		log.Errorf("Error hashing gift card PIN: %v", err)
+		return response.CreateGiftCardResponse{}, err
+	}
+
+	// Physical cards are only spendable once activated at the register
+	if data.Type == models.GiftCardTypePhysical {
+		data.Status = string(models.GiftCardStatusIssued)
+	}
+
+	var order *models.Order
+	err = g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := g.reserveCampaignBudget(ctx, sale, 1, data.CustomerID); err != nil {
+			return err
+		}
+
+		// Pass giftCardCode as the 'uuid' parameter to the repository, which maps to 'Code' in the DB model
+		giftCard, err := g.giftCardRepo.CreateGiftCard(ctx, data, giftCardCode, giftCardNumber, pinHash)
+		if err != nil {
+			log.Errorf("Error creating gift card: %v", err)
+			return err
+		}
+
+		if err := g.giftCardRepo.CreateTransaction(ctx, &models.Transaction{
+			GiftCardID:      giftCard.ID,
+			Amount:          giftCard.Balance,
+			BalanceAfter:    giftCard.Balance,
+			Currency:        giftCard.Currency,
+			TransactionType: models.TransactionTypeCreate,
+			Reference:       giftCardCode,
+			PricePaid:       &sale.price,
+		}); err != nil {
+			return err
+		}
+
+		order = &models.Order{
+			CustomerID:     optionalID(data.CustomerID),
+			GiftCardID:     &giftCard.ID,
+			CampaignID:     optionalID(data.CampaignID),
+			Quantity:       1,
+			FaceValue:      sale.faceValue,
+			DiscountAmount: sale.discount,
+			TotalAmount:    sale.price,
+			Currency:       data.Currency,
+			Status:         models.OrderStatusPaid,
+		}
+		return g.giftCardRepo.CreateOrder(ctx, order)
+	})
 	if err != nil {
 		log.Errorf("Error creating gift card: %v", err)
-		return err
+		return response.CreateGiftCardResponse{}, err
 	}
 
 	log.Info("Gift card created successfully")
-	return nil
+	return response.CreateGiftCardResponse{
+		Code:           giftCardCode,
+		GiftCardNumber: giftCardNumber,
+		Pin:            giftCardPin,
+		OrderID:        order.ID,
+		FaceValue:      sale.faceValue,
+		PricePaid:      sale.price,
+	}, nil
 }
 
 func (g *GiftCardUseCase) GetAllGiftCardList(ctx context.Context) ([]response.GetAllGiftCardResponse, error) {
@@ -126,9 +239,10 @@ func (g *GiftCardUseCase) GetAllGiftCardList(ctx context.Context) ([]response.Ge
 			ID:             giftCard.ID,
 			Type:           giftCard.Type,
 			Balance:        giftCard.Balance,
+			Currency:       giftCard.Currency,
 			GiftCardNumber: giftCard.GiftCardNumber,
 			ExpirationDate: giftCard.ExpirationDate.Format("2006-01-02"),
-			Status:         giftCard.Status,
+			Status:         string(giftCard.Status),
 			IsPromotional:  giftCard.IsPromotional,
 		}
 		responseList = append(responseList, responseItem)
@@ -160,8 +274,9 @@ func (g *GiftCardUseCase) GetGiftCardByID(ctx context.Context, id string) (respo
 		GiftCardNumber: giftCard.GiftCardNumber,
 		Type:           giftCard.Type,
 		Balance:        giftCard.Balance,
+		Currency:       giftCard.Currency,
 		ExpirationDate: giftCard.ExpirationDate.Format("2006-01-02"),
-		Status:         giftCard.Status,
+		Status:         string(giftCard.Status),
 		IsPromotional:  giftCard.IsPromotional,
 	}
 
@@ -171,8 +286,6 @@ func (g *GiftCardUseCase) GetGiftCardByID(ctx context.Context, id string) (respo
 
 func (g *GiftCardUseCase) UpdateGiftCard(ctx context.Context, id string, data request.UpdateGiftCardRequest) error {
 	log := logrus.WithContext(ctx)
-	log.Info("UpdateGiftCard use case")
-
 	log.Info("UpdateGiftCard use case") // id is the gift card code (string)
 
 	// First, check if the gift card exists by its code
@@ -211,15 +324,17 @@ func (g *GiftCardUseCase) FullTextSearchGiftCard(ctx context.Context, query stri
 		return []response.GetAllGiftCardResponse{}, err
 	}
 
-	var responseList []response.GetAllGiftCardResponse
+	// Best matches first, as ranked by the repository
+	responseList := []response.GetAllGiftCardResponse{}
 	for _, giftCard := range results {
 		responseItem := response.GetAllGiftCardResponse{
 			ID:             giftCard.ID,
 			GiftCardNumber: giftCard.GiftCardNumber,
 			Type:           giftCard.Type,
 			Balance:        giftCard.Balance,
+			Currency:       giftCard.Currency,
 			ExpirationDate: giftCard.ExpirationDate.Format("2006-01-02"),
-			Status:         giftCard.Status,
+			Status:         string(giftCard.Status),
 			IsPromotional:  giftCard.IsPromotional,
 		}
 		responseList = append(responseList, responseItem)
@@ -258,84 +373,256 @@ func (g *GiftCardUseCase) DeleteGiftCard(ctx context.Context, id string) error {
 	return nil
 }
 
-func (g *GiftCardUseCase) UseGiftCardAmount(ctx context.Context, giftCardNumber string, amount float64) (response.UseGiftCardAmountResponse, error) {
+// UseGiftCardAmount deducts amount from the gift card. The read, the checks and the
+// write run in one transaction holding a row lock on the card, so concurrent
+// redemptions of the same card are serialized and cannot overspend it.
+// An amount in another currency is converted with the configured exchange rate.
+// It is the one-shot path: funds held by pending authorizations cannot be used,
+// and the amount is authorized and captured in the same transaction.
+// Each redemption counts against the card's MaxUses.
+func (g *GiftCardUseCase) UseGiftCardAmount(ctx context.Context, giftCardNumber string, data request.UseGiftCardAmountRequest) (response.UseGiftCardAmountResponse, error) {
 	log := logrus.WithContext(ctx)
 	log.Info("UseGiftCardAmount use case")
 
-	response := response.UseGiftCardAmountResponse{IsUsed: false} // Default to IsUsed: false
-	log.Debugf("Attempting to use amount %.2f from gift card %s", amount, giftCardNumber)
+	amount := data.Amount
 
-	// 1. Retrieve the gift card
-	giftCard, err := g.giftCardRepo.GetByGiftCardNumber(ctx, giftCardNumber)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+	response := response.UseGiftCardAmountResponse{GiftCardNumber: giftCardNumber, IsUsed: false} // Default to IsUsed: false
+	log.Debugf("Attempting to use amount %s from gift card %s", amount, giftCardNumber)
+
+	if err := g.checkGiftCardNumber(ctx, giftCardNumber); err != nil {
+		if errors.Is(err, customerrors.ErrInvalidGiftCardNumber) {
+			response.Message = "Invalid gift card number."
+		}
+		return response, err
+	}
+
+	// redeemErr carries a rejection whose side effects must still be committed.
+	var redeemErr error
+	err := g.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		// 1. Retrieve and lock the gift card
+		giftCard, err := g.giftCardRepo.GetByGiftCardNumberForUpdate(ctx, giftCardNumber)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Warnf("Gift card %s not found: %v", giftCardNumber, err)
+				response.Message = "Gift card not found."
+				return customerrors.ErrGiftCardNotFound
+			}
+			log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
+			response.Message = "Error retrieving gift card."
+			return err // DB or other unexpected error
+		}
+		// giftCard is a pointer, check for nil though gorm.ErrRecordNotFound should catch it.
+		if giftCard == nil {
+			log.Warnf("Gift card %s not found (nil returned)", giftCardNumber)
 			response.Message = "Gift card not found."
-			return response, customerrors.ErrGiftCardNotFound
+			return customerrors.ErrGiftCardNotFound
+		}
+
+		response.Balance = giftCard.Balance // Populate current balance for all responses
+		response.Currency = giftCard.Currency
+
+		// 2. Check the PIN; failed attempts are counted even though the redemption is rejected
+		if commit, err := g.verifyPin(ctx, giftCard, data.Pin); err != nil {
+			response.Message = "Invalid PIN."
+			if errors.Is(err, customerrors.ErrPinLocked) {
+				response.Message = "PIN is locked."
+			}
+			if errors.Is(err, customerrors.ErrPinResetRequired) {
+				response.Message = "PIN must be reissued."
+			}
+			if commit {
+				redeemErr = err
+				return nil
+			}
+			return err
+		}
+
+		// 3. Check that the card is active and not expired
+		if commit, err := g.checkRedeemable(ctx, giftCard); err != nil {
+			switch {
+			case errors.Is(err, customerrors.ErrGiftCardNotActive):
+				response.Message = fmt.Sprintf("Gift card is not active. Status: %s.", giftCard.Status)
+			case errors.Is(err, customerrors.ErrGiftCardExpired):
+				response.Message = "Gift card has expired."
+			}
+			if commit {
+				response.Balance = 0
+				redeemErr = err
+				return nil
+			}
+			return err
+		}
+		if err := checkUsesLeft(ctx, giftCard); err != nil {
+			response.Message = "Gift card has no uses left."
+			return err
+		}
+
+		// 4. Convert the amount into the card currency
+		if data.Currency != "" && data.Currency != giftCard.Currency {
+			converted, err := g.convertAmount(ctx, amount, data.Currency, giftCard.Currency)
+			if err != nil {
+				log.Warnf("Cannot redeem %s %s from gift card %s in %s: %v", amount, data.Currency, giftCardNumber, giftCard.Currency, err)
+				response.Message = "Currency not accepted for this gift card."
+				return err
+			}
+			log.Infof("Converted %s %s to %s %s", amount, data.Currency, converted, giftCard.Currency)
+			amount = converted
+		}
+
+		// 5. Check if the available balance (ledger balance minus holds) is sufficient
+		available, err := g.availableBalance(ctx, giftCard)
+		if err != nil {
+			log.Errorf("Error computing available balance for gift card %s: %v", giftCardNumber, err)
+			response.Message = "Error retrieving gift card."
+			return err
+		}
+		if available < amount {
+			log.Warnf("Insufficient balance in gift card %s. Available: %s, Tried: %s", giftCardNumber, available, amount)
+			response.Message = "Insufficient balance."
+			// response.Balance is already set
+			return customerrors.ErrInsufficientBalance
+		}
+
+		// 6-8. Authorize and immediately capture the amount
+		authorization, err := g.authorize(ctx, giftCard, amount, time.Now())
+		if err != nil {
+			log.Errorf("Failed to authorize %s on gift card %s: %v", amount, giftCardNumber, err)
+			response.Message = "Failed to update gift card after use."
+			return err
+		}
+		transaction, err := g.capture(ctx, giftCard, authorization, amount)
+		if err != nil {
+			log.Errorf("Failed to capture %s on gift card %s (code: %s): %v", amount, giftCardNumber, giftCard.Code, err)
+			response.Message = "Failed to update gift card after use."
+			return err
 		}
-		log.Errorf("Error retrieving gift card %s: %v", giftCardNumber, err)
-		response.Message = "Error retrieving gift card."
-		return response, err // DB or other unexpected error
+
+		response.Balance = giftCard.Balance
+		response.TransactionID = transaction.ID
+		return nil
+	})
+	if err != nil {
+		return response, err
 	}
-	// giftCard is a pointer, check for nil though gorm.ErrRecordNotFound should catch it.
-	if giftCard == nil {
-		log.Warnf("Gift card %s not found (nil returned)", giftCardNumber)
-		response.Message = "Gift card not found."
-		return response, customerrors.ErrGiftCardNotFound
+	if redeemErr != nil {
+		return response, redeemErr
 	}
 
-	response.Balance = giftCard.Balance // Populate current balance for all responses
+	// 9. Return success response
+	response.IsUsed = true
+	response.Message = "Gift card amount used successfully."
+	log.Infof("Successfully used %s from gift card %s. Remaining balance: %s", amount, giftCardNumber, response.Balance)
+	return response, nil
+}
 
-	// 2. Check if the gift card Status is "active"
-	if giftCard.Status != "active" { // TODO: Use constants for statuses
-		log.Warnf("Gift card %s is not active. Current status: %s", giftCardNumber, giftCard.Status)
-		response.Message = fmt.Sprintf("Gift card is not active. Status: %s.", giftCard.Status)
-		return response, customerrors.ErrGiftCardNotActive
+// expireGiftCard marks the card as expired for reason and forfeits its
+// remaining balance with a ledger entry. It must run inside a transaction.
+func (g *GiftCardUseCase) expireGiftCard(ctx context.Context, giftCard *models.GiftCard, reason string) error {
+	forfeited := giftCard.Balance
+	if err := g.setBalanceAndStatus(ctx, giftCard, 0, models.GiftCardStatusExpired, reason); err != nil {
+		return err
 	}
+	if forfeited == 0 {
+		return nil
+	}
+	return g.giftCardRepo.CreateTransaction(ctx, &models.Transaction{
+		GiftCardID:      giftCard.ID,
+		Amount:          -forfeited,
+		BalanceAfter:    0,
+		Currency:        giftCard.Currency,
+		TransactionType: models.TransactionTypeExpiryForfeit,
+		Reference:       giftCard.Code,
+	})
+}
 
-	// 3. Check if the ExpirationDate has passed
-	if time.Now().After(giftCard.ExpirationDate) {
-		log.Warnf("Gift card %s has expired on %s", giftCardNumber, giftCard.ExpirationDate.Format("2006-01-02"))
-		// Persist this status change
-		if updateErr := g.giftCardRepo.UpdateGiftCardBalanceAndStatus(ctx, giftCard.Code, giftCard.Balance, "expired"); updateErr != nil {
-			log.Errorf("Failed to update status to 'expired' for gift card %s (code: %s): %v", giftCardNumber, giftCard.Code, updateErr)
-			// Log the error but still return the primary error for this path
+func (g *GiftCardUseCase) GetGiftCardTransactions(ctx context.Context, code string, page int, pageSize int) (response.TransactionListResponse, error) {
+	log := logrus.WithContext(ctx)
+	log.Info("GetGiftCardTransactions use case")
+
+	giftCard, err := g.giftCardRepo.GetGiftCardByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warnf("Gift card with code %s not found: %v", code, err)
+			return response.TransactionListResponse{}, customerrors.ErrGiftCardNotFound
 		}
-		response.Message = "Gift card has expired."
-		return response, customerrors.ErrGiftCardExpired
+		log.Errorf("Error getting gift card by code %s: %v", code, err)
+		return response.TransactionListResponse{}, err
+	}
+
+	transactions, total, err := g.giftCardRepo.ListTransactions(ctx, giftCard.ID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Errorf("Error listing transactions for gift card %s: %v", code, err)
+		return response.TransactionListResponse{}, err
 	}
 
-	// 4. Check if giftCard.Balance is sufficient
-	if giftCard.Balance < amount {
-		log.Warnf("Insufficient balance in gift card %s. Has: %.2f, Tried: %.2f", giftCardNumber, giftCard.Balance, amount)
-		response.Message = "Insufficient balance."
-		// response.Balance is already set
-		return response, customerrors.ErrInsufficientBalance
+	result := response.TransactionListResponse{
+		Transactions: []response.TransactionResponse{},
+		Page:         page,
+		PageSize:     pageSize,
+		Total:        total,
+	}
+	for _, transaction := range transactions {
+		result.Transactions = append(result.Transactions, response.TransactionResponse{
+			ID:           transaction.ID,
+			Type:         transaction.TransactionType,
+			Amount:       transaction.Amount,
+			BalanceAfter: transaction.BalanceAfter,
+			Currency:     transaction.Currency,
+			Reference:    transaction.Reference,
+			CreatedAt:    transaction.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
 	}
 
-	// 5. Deduct the amount
-	newBalance := giftCard.Balance - amount
-	log.Infof("Deducted %.2f from gift card %s. New balance: %.2f", amount, giftCardNumber, newBalance)
+	log.Info("Gift card transactions retrieved successfully")
+	return result, nil
+}
 
-	// 6. If balance becomes 0, update status
-	newStatus := giftCard.Status
-	if newBalance == 0 {
-		newStatus = "used" // TODO: Use constants for statuses
-		log.Infof("Gift card %s balance is now 0. Setting status to '%s'", giftCardNumber, newStatus)
+// giftCardNumberPrefix resolves the prefix of new card numbers: the
+// campaign prefix, then the card type prefix, then generators.DefaultPrefix.
+// Prefixes are configured as models.Setting, e.g.
+// "giftcard_number.prefix.campaign.12" = "GC6035" or "giftcard_number.prefix.physical" = "GP".
+func (c *GiftCardUseCase) giftCardNumberPrefix(ctx context.Context, cardType string, campaignID uint) (string, error) {
+	names := []string{fmt.Sprintf("giftcard_number.prefix.%s", cardType)}
+	if campaignID != 0 {
+		names = append([]string{fmt.Sprintf("giftcard_number.prefix.campaign.%d", campaignID)}, names...)
 	}
 
-	// 7. Persist changes (balance and potentially status)
-	if err := g.giftCardRepo.UpdateGiftCardBalanceAndStatus(ctx, giftCard.Code, newBalance, newStatus); err != nil {
-		log.Errorf("Failed to update balance/status for gift card %s (code: %s): %v", giftCardNumber, giftCard.Code, err)
-		response.Message = "Failed to update gift card after use."
-		// Return current balance before attempted deduction, as the transaction failed
-		return response, err
+	for _, name := range names {
+		prefix, ok, err := stringSetting(ctx, c.settingRepo, name)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+		if !generators.ValidPrefix(prefix, generators.NumberLength) {
+			return "", fmt.Errorf("invalid %s setting %q: %w", name, prefix, generators.ErrInvalidPrefix)
+		}
+		return prefix, nil
 	}
+	return generators.DefaultPrefix, nil
+}
 
-	// 8. Return success response
-	response.Balance = newBalance
-	response.IsUsed = true
-	response.Message = "Gift card amount used successfully."
-	log.Infof("Successfully used %.2f from gift card %s. Remaining balance: %.2f", amount, giftCardNumber, newBalance)
-	return response, nil
+// exchangeRateSetting is the name of the models.Setting holding the rate that
+// converts one unit of from into the to currency, e.g. "exchange_rate.EUR.USD" = "1.08".
+func exchangeRateSetting(from string, to string) string {
+	return "exchange_rate." + from + "." + to
+}
+
+// convertAmount converts amount between currencies with the configured exchange rate.
+// It returns ErrCurrencyMismatch when no rate is configured for the pair.
+func (g *GiftCardUseCase) convertAmount(ctx context.Context, amount money.Amount, from string, to string) (money.Amount, error) {
+	setting, err := g.settingRepo.GetSetting(ctx, exchangeRateSetting(from, to))
+	if err != nil {
+		return 0, err
+	}
+	if setting == nil {
+		return 0, customerrors.ErrCurrencyMismatch
+	}
+
+	rate, ok := new(big.Rat).SetString(setting.Value)
+	if !ok || rate.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate %q for %s", setting.Value, setting.Name)
+	}
+	return amount.Mul(rate)
 }
@@ -0,0 +1,286 @@
+//go:build integration
+
+package usecase
+
+import (
+	"GiftWize/src/app"
+	"GiftWize/src/entity/models"
+	"GiftWize/src/entity/request"
+	"GiftWize/src/entity/response"
+	"GiftWize/src/infreaestructure/repository"
+	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openIntegrationDB connects to the Postgres database in GIFTCARD_TEST_DSN, e.g.
+// "host=localhost user=postgres password=postgres dbname=giftcard_test port=5432 sslmode=disable".
+// Run with: go test -tags integration ./...
+func openIntegrationDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("GIFTCARD_TEST_DSN")
+	if dsn == "" {
+		t.Skip("GIFTCARD_TEST_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Campaign{}, &models.Inventory{}, &models.GiftCard{}, &models.Transaction{}, &models.Setting{}, &models.Authorization{}, &models.GiftCardStatusChange{}, &models.AuditLog{}, &models.IssuanceJob{}, &models.ExpirySweepRun{}, &models.BalanceInquiry{}, &models.IssuanceJobPin{}))
+	require.NoError(t, repository.MigrateSearch(db))
+	return db
+}
+
+func TestGiftCardUseCase_UseGiftCardAmount_Concurrent(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	cardNumber, err := generators.GenerateGiftcardNumber(generators.NumberLength, "IT")
+	require.NoError(t, err)
+	card := models.GiftCard{
+		Code:           fmt.Sprintf("it-%d", time.Now().UnixNano()),
+		GiftCardNumber: cardNumber,
+		Type:           "virtual",
+		Balance:        money.MustParse("100.00"),
+		Status:         models.GiftCardStatusActive,
+		ExpirationDate: time.Now().AddDate(1, 0, 0),
+	}
+	require.NoError(t, db.Create(&card).Error)
+	t.Cleanup(func() {
+		db.Where("gift_card_id = ?", card.ID).Delete(&models.Transaction{})
+		db.Where("gift_card_id = ?", card.ID).Delete(&models.Authorization{})
+		db.Where("gift_card_id = ?", card.ID).Delete(&models.GiftCardStatusChange{})
+		db.Delete(&models.GiftCard{}, card.ID)
+	})
+
+	useCase := NewGiftCardUseCase(repository.NewGiftCardRepository(db), repository.NewCampaignRepository(db), repository.NewSettingRepository(db), repository.NewAuditLogRepository(db), repository.NewTransactor(db))
+
+	const workers = 25
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		rejected  int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := useCase.UseGiftCardAmount(ctx, card.GiftCardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00")})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, app.ErrInsufficientBalance), errors.Is(err, app.ErrGiftCardNotActive):
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var stored models.GiftCard
+	require.NoError(t, db.First(&stored, card.ID).Error)
+	assert.Equal(t, 10, succeeded)
+	assert.Equal(t, workers-10, rejected)
+	assert.Equal(t, money.Amount(0), stored.Balance)
+	assert.Equal(t, models.GiftCardStatusUsed, stored.Status)
+
+	var ledgerTotal money.Amount
+	require.NoError(t, db.Model(&models.Transaction{}).Where("gift_card_id = ?", card.ID).Select("COALESCE(SUM(amount), 0)").Scan(&ledgerTotal).Error)
+	assert.Equal(t, money.MustParse("-100.00"), ledgerTotal)
+}
+
+func TestGiftCardUseCase_PurgeIssuanceJobPins_Postgres(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	expiredAt := time.Now().Add(-issuanceJobPinTTL - time.Minute)
+	recentAt := time.Now()
+	expired := models.IssuanceJob{Code: fmt.Sprintf("it-expired-%d", time.Now().UnixNano()), Status: models.IssuanceJobStatusCompleted, CompletedAt: &expiredAt}
+	recent := models.IssuanceJob{Code: fmt.Sprintf("it-recent-%d", time.Now().UnixNano()), Status: models.IssuanceJobStatusCompleted, CompletedAt: &recentAt}
+	running := models.IssuanceJob{Code: fmt.Sprintf("it-running-%d", time.Now().UnixNano()), Status: models.IssuanceJobStatusRunning}
+	require.NoError(t, db.Create(&expired).Error)
+	require.NoError(t, db.Create(&recent).Error)
+	require.NoError(t, db.Create(&running).Error)
+	cardID := uint(time.Now().UnixNano() % 1_000_000_000)
+	require.NoError(t, db.Create(&[]models.IssuanceJobPin{
+		{GiftCardID: cardID, IssuanceJobID: expired.ID, Pin: "111111"},
+		{GiftCardID: cardID + 1, IssuanceJobID: expired.ID, Pin: "222222"},
+		{GiftCardID: cardID + 2, IssuanceJobID: recent.ID, Pin: "333333"},
+		{GiftCardID: cardID + 3, IssuanceJobID: running.ID, Pin: "444444"},
+	}).Error)
+	t.Cleanup(func() {
+		db.Where("issuance_job_id IN ?", []uint{expired.ID, recent.ID, running.ID}).Delete(&models.IssuanceJobPin{})
+		db.Delete(&models.IssuanceJob{}, []uint{expired.ID, recent.ID, running.ID})
+	})
+
+	useCase := NewGiftCardUseCase(repository.NewGiftCardRepository(db), repository.NewCampaignRepository(db), repository.NewSettingRepository(db), repository.NewAuditLogRepository(db), repository.NewTransactor(db)).(*GiftCardUseCase)
+	_, err := useCase.purgeIssuanceJobPins(ctx)
+	require.NoError(t, err)
+
+	countPins := func(jobID uint) int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.IssuanceJobPin{}).Where("issuance_job_id = ?", jobID).Count(&count).Error)
+		return count
+	}
+	assert.Zero(t, countPins(expired.ID), "no plain PIN is left after the TTL")
+	assert.Equal(t, int64(1), countPins(recent.ID))
+	assert.Equal(t, int64(1), countPins(running.ID))
+
+	err = useCase.ExportIssuanceJobCSV(ctx, expired.Code, new(bytes.Buffer))
+	assert.ErrorIs(t, err, app.ErrIssuanceJobPinsExpired)
+}
+
+func TestCampaignUseCase_GetCampaignStats_Postgres(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	campaign := models.Campaign{Name: "Stats", StartDate: time.Now().AddDate(0, -1, 0), EndDate: time.Now().AddDate(0, 1, 0), IsEnabled: true}
+	require.NoError(t, db.Create(&campaign).Error)
+	newCard := func(balance string, status models.GiftCardStatus) models.GiftCard {
+		cardNumber, err := generators.GenerateGiftcardNumber(generators.NumberLength, "IT")
+		require.NoError(t, err)
+		card := models.GiftCard{
+			Code:           fmt.Sprintf("it-%d", time.Now().UnixNano()),
+			GiftCardNumber: cardNumber,
+			Type:           "virtual",
+			Balance:        money.MustParse(balance),
+			Currency:       "USD",
+			Status:         status,
+			ExpirationDate: time.Now().AddDate(1, 0, 0),
+			CampaignID:     &campaign.ID,
+		}
+		require.NoError(t, db.Create(&card).Error)
+		return card
+	}
+	redeemed := newCard("0.00", models.GiftCardStatusUsed)
+	expired := newCard("0.00", models.GiftCardStatusExpired)
+	require.NoError(t, db.Create(&[]models.Transaction{
+		{GiftCardID: redeemed.ID, Amount: money.MustParse("50.00"), Currency: "USD", TransactionType: models.TransactionTypeCreate},
+		{GiftCardID: redeemed.ID, Amount: money.MustParse("-50.00"), Currency: "USD", TransactionType: models.TransactionTypeRedemption},
+		{GiftCardID: expired.ID, Amount: money.MustParse("25.00"), Currency: "USD", TransactionType: models.TransactionTypeCreate},
+		{GiftCardID: expired.ID, Amount: money.MustParse("-25.00"), Currency: "USD", TransactionType: models.TransactionTypeExpiryForfeit},
+	}).Error)
+	t.Cleanup(func() {
+		db.Where("gift_card_id IN ?", []uint{redeemed.ID, expired.ID}).Delete(&models.Transaction{})
+		db.Delete(&models.GiftCard{}, []uint{redeemed.ID, expired.ID})
+		db.Delete(&models.Campaign{}, campaign.ID)
+	})
+
+	stats, err := NewCampaignUseCase(repository.NewCampaignRepository(db)).GetCampaignStats(ctx, int(campaign.ID), "week")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.CardsIssued)
+	assert.Equal(t, int64(2), stats.CardsActivated)
+	assert.Equal(t, int64(1), stats.CardsFullyRedeemed)
+	assert.Equal(t, int64(1), stats.CardsExpired)
+	if assert.Len(t, stats.Totals, 1) {
+		assert.Equal(t, money.MustParse("75.00"), stats.Totals[0].FaceValue)
+		assert.Equal(t, money.MustParse("50.00"), stats.Totals[0].Redeemed)
+		assert.Equal(t, money.MustParse("25.00"), stats.Totals[0].Breakage)
+		assert.Equal(t, money.Amount(0), stats.Totals[0].Outstanding)
+	}
+	if assert.Len(t, stats.Redemptions, 1) {
+		assert.Equal(t, int64(1), stats.Redemptions[0].Redemptions)
+		assert.Equal(t, money.MustParse("50.00"), stats.Redemptions[0].Amount)
+	}
+}
+
+func TestCampaignUseCase_SearchCampaign_Postgres(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	// A word unlikely to appear in other campaigns of the test database
+	word := fmt.Sprintf("zq%d", time.Now().UnixNano())
+	campaigns := []models.Campaign{
+		{Name: "Holidays", Description: "Save on " + word + " gifts", StartDate: time.Now(), EndDate: time.Now().AddDate(0, 1, 0), IsEnabled: true},
+		{Name: "Big " + word, Description: "Once a year", StartDate: time.Now(), EndDate: time.Now().AddDate(0, 1, 0), IsEnabled: true},
+		{Name: "Unrelated", Description: "Nothing to see", StartDate: time.Now(), EndDate: time.Now().AddDate(0, 1, 0), IsEnabled: true},
+	}
+	require.NoError(t, db.Create(&campaigns).Error)
+	t.Cleanup(func() {
+		db.Delete(&models.Campaign{}, []uint{campaigns[0].ID, campaigns[1].ID, campaigns[2].ID})
+	})
+	useCase := NewCampaignUseCase(repository.NewCampaignRepository(db))
+
+	results, err := useCase.SearchCampaign(ctx, word)
+	require.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		// A match in the name outranks one in the description
+		assert.Equal(t, campaigns[1].ID, results[0].ID)
+		assert.Equal(t, campaigns[0].ID, results[1].ID)
+	}
+
+	results, err = useCase.SearchCampaign(ctx, word+" -year")
+	require.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, campaigns[0].ID, results[0].ID)
+	}
+}
+
+func TestGiftCardUseCase_FullTextSearchGiftCard_Postgres(t *testing.T) {
+	db := openIntegrationDB(t)
+	ctx := context.Background()
+
+	cardNumber, err := generators.GenerateGiftcardNumber(generators.NumberLength, "IT")
+	require.NoError(t, err)
+	card := models.GiftCard{
+		Code:           fmt.Sprintf("it-%d", time.Now().UnixNano()),
+		GiftCardNumber: cardNumber,
+		Type:           "virtual",
+		Balance:        money.MustParse("10.00"),
+		Status:         models.GiftCardStatusActive,
+		ExpirationDate: time.Now().AddDate(1, 0, 0),
+	}
+	require.NoError(t, db.Create(&card).Error)
+	t.Cleanup(func() {
+		db.Delete(&models.GiftCard{}, card.ID)
+	})
+	useCase := NewGiftCardUseCase(repository.NewGiftCardRepository(db), repository.NewCampaignRepository(db), repository.NewSettingRepository(db), repository.NewAuditLogRepository(db), repository.NewTransactor(db))
+
+	contains := func(results []response.GetAllGiftCardResponse) bool {
+		for _, result := range results {
+			if result.GiftCardNumber == cardNumber {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("part of the number", func(t *testing.T) {
+		results, err := useCase.FullTextSearchGiftCard(ctx, cardNumber[len(cardNumber)-8:])
+		require.NoError(t, err)
+		assert.True(t, contains(results))
+	})
+
+	t.Run("number with a typo", func(t *testing.T) {
+		last := byte('0')
+		if cardNumber[len(cardNumber)-1] == '0' {
+			last = '1'
+		}
+		results, err := useCase.FullTextSearchGiftCard(ctx, cardNumber[:len(cardNumber)-1]+string(last))
+		require.NoError(t, err)
+		if assert.NotEmpty(t, results) {
+			assert.Equal(t, cardNumber, results[0].GiftCardNumber)
+		}
+	})
+
+	t.Run("wildcards are matched literally", func(t *testing.T) {
+		results, err := useCase.FullTextSearchGiftCard(ctx, "%_%")
+		require.NoError(t, err)
+		assert.False(t, contains(results))
+	})
+}
@@ -6,8 +6,12 @@ import (
 	"GiftWize/src/entity/request"
 	"GiftWize/src/entity/response"
 	"GiftWize/src/infreaestructure/repository" // Used for IGiftCardRepository
+	"GiftWize/src/shared/generators"
+	"GiftWize/src/shared/money"
+	"GiftWize/src/shared/pin"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,14 +28,22 @@ type MockGiftCardRepository struct {
 // Ensure MockGiftCardRepository implements IGiftCardRepository
 var _ repository.IGiftCardRepository = (*MockGiftCardRepository)(nil)
 
+func (m *MockGiftCardRepository) ImportedGiftCardNumberExists(ctx context.Context, giftCardNumber string) (bool, error) {
+	args := m.Called(ctx, giftCardNumber)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockGiftCardRepository) GiftCardNumberExists(ctx context.Context, giftCardNumber string) (bool, error) {
 	args := m.Called(ctx, giftCardNumber)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockGiftCardRepository) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, code string, giftCardNumber string) error {
-	args := m.Called(ctx, data, code, giftCardNumber)
-	return args.Error(0)
+func (m *MockGiftCardRepository) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, code string, giftCardNumber string, pinHash string) (*models.GiftCard, error) {
+	args := m.Called(ctx, data, code, giftCardNumber, pinHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GiftCard), args.Error(1)
 }
 
 func (m *MockGiftCardRepository) GetGiftCardByCode(ctx context.Context, code string) (*models.GiftCard, error) {
@@ -50,6 +62,14 @@ func (m *MockGiftCardRepository) GetByGiftCardNumber(ctx context.Context, giftCa
 	return args.Get(0).(*models.GiftCard), args.Error(1)
 }
 
+func (m *MockGiftCardRepository) GetByGiftCardNumberForUpdate(ctx context.Context, giftCardNumber string) (*models.GiftCard, error) {
+	args := m.Called(ctx, giftCardNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GiftCard), args.Error(1)
+}
+
 func (m *MockGiftCardRepository) GetAllGiftCardList(ctx context.Context) ([]models.GiftCard, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -67,7 +87,7 @@ func (m *MockGiftCardRepository) UpdateGiftCard(ctx context.Context, code string
 	return args.Error(0)
 }
 
-func (m *MockGiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance float64, status string) error {
+func (m *MockGiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance money.Amount, status models.GiftCardStatus) error {
 	args := m.Called(ctx, code, balance, status)
 	return args.Error(0)
 }
@@ -89,17 +109,345 @@ func (m *MockGiftCardRepository) DeleteGiftCard(ctx context.Context, code string
 	return args.Error(0)
 }
 
+func (m *MockGiftCardRepository) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) ListTransactions(ctx context.Context, giftCardID uint, limit int, offset int) ([]models.Transaction, int64, error) {
+	args := m.Called(ctx, giftCardID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Transaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockGiftCardRepository) GetGiftCardByIDForUpdate(ctx context.Context, id uint) (*models.GiftCard, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetRefundedAmount(ctx context.Context, transactionID uint) (money.Amount, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetTransactionTotalSince(ctx context.Context, giftCardID uint, transactionType string, since time.Time) (money.Amount, error) {
+	args := m.Called(ctx, giftCardID, transactionType, since)
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetGiftCardByCodeForUpdate(ctx context.Context, code string) (*models.GiftCard, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateGiftCardStatusChange(ctx context.Context, change *models.GiftCardStatusChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) UpdateGiftCardActivation(ctx context.Context, code string, activationDate time.Time, activatedBy string, initialBalance money.Amount) error {
+	args := m.Called(ctx, code, activationDate, activatedBy, initialBalance)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetInventoryByID(ctx context.Context, id uint) (*models.Inventory, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Inventory), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) RecordGiftCardUse(ctx context.Context, code string, usedAt time.Time) error {
+	args := m.Called(ctx, code, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) CreateGiftCards(ctx context.Context, giftCards []models.GiftCard) (int64, error) {
+	args := m.Called(ctx, giftCards)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetIssuedGiftCards(ctx context.Context, issuanceJobID uint, giftCardNumbers []string) ([]models.GiftCard, error) {
+	args := m.Called(ctx, issuanceJobID, giftCardNumbers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListIssuedGiftCards(ctx context.Context, issuanceJobID uint, limit int, offset int) ([]models.GiftCard, error) {
+	args := m.Called(ctx, issuanceJobID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) CreateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetIssuanceJobByCode(ctx context.Context, code string) (*models.IssuanceJob, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IssuanceJob), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) UpdateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetGiftCardsByNumbers(ctx context.Context, giftCardNumbers []string) ([]models.GiftCard, error) {
+	args := m.Called(ctx, giftCardNumbers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListGiftCards(ctx context.Context, filter request.GiftCardExportFilter, afterID uint, limit int) ([]models.GiftCard, error) {
+	args := m.Called(ctx, filter, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListExpiredGiftCardsForUpdate(ctx context.Context, now time.Time, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error) {
+	args := m.Called(ctx, now, statuses, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateExpirySweepRun(ctx context.Context, run *models.ExpirySweepRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetLastExpirySweepRun(ctx context.Context) (*models.ExpirySweepRun, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExpirySweepRun), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetIssuanceJobForUpdate(ctx context.Context, id uint) (*models.IssuanceJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IssuanceJob), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateIssuanceJobPins(ctx context.Context, pins []models.IssuanceJobPin) error {
+	args := m.Called(ctx, pins)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) ListIssuanceJobPins(ctx context.Context, giftCardIDs []uint) ([]models.IssuanceJobPin, error) {
+	args := m.Called(ctx, giftCardIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IssuanceJobPin), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) DeleteIssuanceJobPins(ctx context.Context, issuanceJobID uint) error {
+	args := m.Called(ctx, issuanceJobID)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) PurgeIssuanceJobPins(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListStaleIssuanceJobsForUpdate(ctx context.Context, before time.Time) ([]models.IssuanceJob, error) {
+	args := m.Called(ctx, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IssuanceJob), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListOpenIssuanceJobsForUpdate(ctx context.Context, campaignID uint) ([]models.IssuanceJob, error) {
+	args := m.Called(ctx, campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.IssuanceJob), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) ListPromotionalGiftCardsForUpdate(ctx context.Context, campaignID uint, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error) {
+	args := m.Called(ctx, campaignID, statuses, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GiftCard), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateBalanceInquiry(ctx context.Context, inquiry *models.BalanceInquiry) error {
+	args := m.Called(ctx, inquiry)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) UpdateGiftCardExpiration(ctx context.Context, code string, expirationDate time.Time, originalExpirationDate time.Time) error {
+	args := m.Called(ctx, code, expirationDate, originalExpirationDate)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) UpdateGiftCardPin(ctx context.Context, code string, pinHash string, failedAttempts int, lockedAt *time.Time) error {
+	args := m.Called(ctx, code, pinHash, failedAttempts, lockedAt)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetHeldAmount(ctx context.Context, giftCardID uint, now time.Time) (money.Amount, error) {
+	args := m.Called(ctx, giftCardID, now)
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) CreateAuthorization(ctx context.Context, authorization *models.Authorization) error {
+	args := m.Called(ctx, authorization)
+	return args.Error(0)
+}
+
+func (m *MockGiftCardRepository) GetAuthorizationByCode(ctx context.Context, code string) (*models.Authorization, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Authorization), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) GetAuthorizationByCodeForUpdate(ctx context.Context, code string) (*models.Authorization, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Authorization), args.Error(1)
+}
+
+func (m *MockGiftCardRepository) UpdateAuthorization(ctx context.Context, authorization *models.Authorization) error {
+	args := m.Called(ctx, authorization)
+	return args.Error(0)
+}
+
+// MockSettingRepository is a mock type for the ISettingRepository
+type MockSettingRepository struct {
+	mock.Mock
+}
+
+// Ensure MockSettingRepository implements ISettingRepository
+var _ repository.ISettingRepository = (*MockSettingRepository)(nil)
+
+func (m *MockSettingRepository) GetSetting(ctx context.Context, name string) (*models.Setting, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Setting), args.Error(1)
+}
+
+// noSettings answers every setting as not configured.
+func noSettings() *MockSettingRepository {
+	mockSettings := new(MockSettingRepository)
+	mockSettings.On("GetSetting", mock.Anything, mock.Anything).Return(nil, nil)
+	return mockSettings
+}
+
+// MockAuditLogRepository is a mock type for the IAuditLogRepository
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+// Ensure MockAuditLogRepository implements IAuditLogRepository
+var _ repository.IAuditLogRepository = (*MockAuditLogRepository)(nil)
+
+func (m *MockAuditLogRepository) CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) error {
+	args := m.Called(ctx, auditLog)
+	return args.Error(0)
+}
+
+// isLedgerEntry matches a ledger entry by type, signed amount and resulting balance.
+func isLedgerEntry(transactionType string, amount money.Amount, balanceAfter money.Amount) func(*models.Transaction) bool {
+	return func(transaction *models.Transaction) bool {
+		return transaction.TransactionType == transactionType && transaction.Amount == amount && transaction.BalanceAfter == balanceAfter
+	}
+}
+
+// isAuthorization matches an authorization by status and captured amount.
+func isAuthorization(status string, capturedAmount money.Amount) func(*models.Authorization) bool {
+	return func(authorization *models.Authorization) bool {
+		return authorization.Status == status && authorization.CapturedAmount == capturedAmount
+	}
+}
+
+// isStatusChange matches a status history entry by its transition.
+func isStatusChange(from models.GiftCardStatus, to models.GiftCardStatus) func(*models.GiftCardStatusChange) bool {
+	return func(change *models.GiftCardStatusChange) bool {
+		return change.FromStatus == from && change.ToStatus == to
+	}
+}
+
+// fakeTransactor runs the unit of work directly, without a database transaction.
+type fakeTransactor struct{}
+
+var _ repository.ITransactor = fakeTransactor{}
+
+func (fakeTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// TryAdvisoryLock always gets the lock, as if no other replica were running.
+func (fakeTransactor) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	return true, nil
+}
+
 
 func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
 	ctx := context.Background()
 
-	activeStatus := "active"
-	expiredStatus := "expired"
-	usedStatus := "used"
-	inactiveStatus := "inactive"
+	activeStatus := models.GiftCardStatusActive
+	expiredStatus := models.GiftCardStatusExpired
+	usedStatus := models.GiftCardStatusUsed
+	suspendedStatus := models.GiftCardStatusSuspended
 
 	validCardCode := "test-code-123"
-	validCardNumber := "GC1234567890123456"
+	validCardNumber := "GC12345678901237"
 
 	now := time.Now()
 	yesterday := now.AddDate(0, 0, -1)
@@ -108,40 +456,45 @@ func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
 	tests := []struct {
 		name                    string
 		giftCardNumber          string
-		amountToUse             float64
+		amountToUse             money.Amount
 		mockSetup               func(mockRepo *MockGiftCardRepository) 
 		expectedResponse        response.UseGiftCardAmountResponse
 		expectedError           error
 		expectUpdateCall        bool 
-		expectedNewBalance      float64
-		expectedNewStatus       string
+		expectedNewBalance      money.Amount
+		expectedNewStatus       models.GiftCardStatus
 	}{
 		{
 			name:           "successful use of gift card",
 			giftCardNumber: validCardNumber,
-			amountToUse:    50.0,
+			amountToUse:    money.MustParse("50.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
 					Code:           validCardCode, 
 					GiftCardNumber: validCardNumber,
-					Balance:        100.0,
+					Balance:        money.MustParse("100.00"),
 					Status:         activeStatus,
 					ExpirationDate: tomorrow,
 				}, nil).Once()
-				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, 50.0, activeStatus).Return(nil).Once()
+				mockRepo.On("GetHeldAmount", ctx, uint(0), mock.Anything).Return(money.Amount(0), nil).Once()
+				mockRepo.On("CreateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, money.MustParse("50.00"), activeStatus).Return(nil).Once()
+				mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-50.00"), money.MustParse("50.00")))).Return(nil).Once()
+				mockRepo.On("RecordGiftCardUse", ctx, validCardCode, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusCaptured, money.MustParse("50.00")))).Return(nil).Once()
 			},
-			expectedResponse: response.UseGiftCardAmountResponse{Balance: 50.0, IsUsed: true, Message: "Gift card amount used successfully."},
+			expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("50.00"), IsUsed: true, Message: "Gift card amount used successfully."},
 			expectedError:    nil,
 			expectUpdateCall: true,
-			expectedNewBalance: 50.0, 
+			expectedNewBalance: money.MustParse("50.00"), 
 			expectedNewStatus: activeStatus,
 		},
 		{
 			name:           "gift card not found",
-			giftCardNumber: "GCUNKNOWN",
-			amountToUse:    10.0,
+			giftCardNumber: "GC99999999999902",
+			amountToUse:    money.MustParse("10.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, "GCUNKNOWN").Return(nil, gorm.ErrRecordNotFound).Once()
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, "GC99999999999902").Return(nil, gorm.ErrRecordNotFound).Once()
 			},
 			expectedResponse: response.UseGiftCardAmountResponse{Balance: 0, IsUsed: false, Message: "Gift card not found."},
 			expectedError:    app.ErrGiftCardNotFound,
@@ -150,83 +503,92 @@ func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
 		{
 			name:           "gift card not active",
 			giftCardNumber: validCardNumber,
-			amountToUse:    10.0,
+			amountToUse:    money.MustParse("10.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
 					Code:           validCardCode, 
 					GiftCardNumber: validCardNumber,
-					Balance:        100.0,
-					Status:         inactiveStatus, 
+					Balance:        money.MustParse("100.00"),
+					Status:         suspendedStatus, 
 					ExpirationDate: tomorrow,
 				}, nil).Once()
 			},
-			expectedResponse: response.UseGiftCardAmountResponse{Balance: 100.0, IsUsed: false, Message: "Gift card is not active. Status: inactive."},
+			expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("100.00"), IsUsed: false, Message: "Gift card is not active. Status: suspended."},
 			expectedError:    app.ErrGiftCardNotActive,
 			expectUpdateCall: false,
 		},
 		{
 			name:           "gift card expired",
 			giftCardNumber: validCardNumber,
-			amountToUse:    10.0,
+			amountToUse:    money.MustParse("10.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
 					Code:           validCardCode, 
 					GiftCardNumber: validCardNumber,
-					Balance:        100.0,
+					Balance:        money.MustParse("100.00"),
 					Status:         activeStatus,
 					ExpirationDate: yesterday, 
 				}, nil).Once()
-				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, 100.0, expiredStatus).Return(nil).Once()
+				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, money.MustParse("0.00"), expiredStatus).Return(nil).Once()
+				mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(activeStatus, expiredStatus))).Return(nil).Once()
+				mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeExpiryForfeit, money.MustParse("-100.00"), money.MustParse("0.00")))).Return(nil).Once()
 			},
-			expectedResponse: response.UseGiftCardAmountResponse{Balance: 100.0, IsUsed: false, Message: "Gift card has expired."},
+			expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("0.00"), IsUsed: false, Message: "Gift card has expired."},
 			expectedError:    app.ErrGiftCardExpired,
 			expectUpdateCall: true,
-			expectedNewBalance: 100.0, 
+			expectedNewBalance: money.MustParse("0.00"), 
 			expectedNewStatus: expiredStatus,
 		},
 		{
 			name:           "insufficient balance",
 			giftCardNumber: validCardNumber,
-			amountToUse:    150.0,
+			amountToUse:    money.MustParse("150.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
 					Code:           validCardCode, 
 					GiftCardNumber: validCardNumber,
-					Balance:        100.0, 
+					Balance:        money.MustParse("100.00"), 
 					Status:         activeStatus,
 					ExpirationDate: tomorrow,
 				}, nil).Once()
+				mockRepo.On("GetHeldAmount", ctx, uint(0), mock.Anything).Return(money.Amount(0), nil).Once()
 			},
-			expectedResponse: response.UseGiftCardAmountResponse{Balance: 100.0, IsUsed: false, Message: "Insufficient balance."},
+			expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("100.00"), IsUsed: false, Message: "Insufficient balance."},
 			expectedError:    app.ErrInsufficientBalance,
 			expectUpdateCall: false,
 		},
 		{
 			name:           "using exact balance",
 			giftCardNumber: validCardNumber,
-			amountToUse:    100.0,
+			amountToUse:    money.MustParse("100.00"),
 			mockSetup: func(mockRepo *MockGiftCardRepository) {
-				mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+				mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
 					Code:           validCardCode, 
 					GiftCardNumber: validCardNumber,
-					Balance:        100.0,
+					Balance:        money.MustParse("100.00"),
 					Status:         activeStatus,
 					ExpirationDate: tomorrow,
 				}, nil).Once()
-				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, 0.0, usedStatus).Return(nil).Once()
+				mockRepo.On("GetHeldAmount", ctx, uint(0), mock.Anything).Return(money.Amount(0), nil).Once()
+				mockRepo.On("CreateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+				mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, money.MustParse("0.00"), usedStatus).Return(nil).Once()
+				mockRepo.On("CreateGiftCardStatusChange", ctx, mock.MatchedBy(isStatusChange(activeStatus, usedStatus))).Return(nil).Once()
+				mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-100.00"), money.MustParse("0.00")))).Return(nil).Once()
+				mockRepo.On("RecordGiftCardUse", ctx, validCardCode, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				mockRepo.On("UpdateAuthorization", ctx, mock.MatchedBy(isAuthorization(models.AuthorizationStatusCaptured, money.MustParse("100.00")))).Return(nil).Once()
 			},
-			expectedResponse: response.UseGiftCardAmountResponse{Balance: 0.0, IsUsed: true, Message: "Gift card amount used successfully."},
+			expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("0.00"), IsUsed: true, Message: "Gift card amount used successfully."},
 			expectedError:    nil,
 			expectUpdateCall: true,
-			expectedNewBalance: 0.0, 
+			expectedNewBalance: money.MustParse("0.00"), 
 			expectedNewStatus: usedStatus,
 		},
         {
             name:           "error from GetByGiftCardNumber (not RecordNotFound)",
-            giftCardNumber: "GCERROR",
-            amountToUse:    10.0,
+            giftCardNumber: "GC88888888888800",
+            amountToUse:    money.MustParse("10.00"),
             mockSetup: func(mockRepo *MockGiftCardRepository) {
-                mockRepo.On("GetByGiftCardNumber", ctx, "GCERROR").Return(nil, errors.New("generic DB error")).Once()
+                mockRepo.On("GetByGiftCardNumberForUpdate", ctx, "GC88888888888800").Return(nil, errors.New("generic DB error")).Once()
             },
             expectedResponse: response.UseGiftCardAmountResponse{Balance: 0, IsUsed: false, Message: "Error retrieving gift card."},
             expectedError:    errors.New("generic DB error"),
@@ -235,41 +597,43 @@ func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
         {
             name:           "error updating status when card expires",
             giftCardNumber: validCardNumber,
-            amountToUse:    10.0,
+            amountToUse:    money.MustParse("10.00"),
             mockSetup: func(mockRepo *MockGiftCardRepository) {
-                mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+                mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
                     Code:           validCardCode, 
                     GiftCardNumber: validCardNumber,
-                    Balance:        100.0,
+                    Balance:        money.MustParse("100.00"),
                     Status:         activeStatus,
                     ExpirationDate: yesterday, 
                 }, nil).Once()
-                mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, 100.0, expiredStatus).Return(errors.New("update failed")).Once();
+                mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, money.MustParse("0.00"), expiredStatus).Return(errors.New("update failed")).Once();
             },
-            expectedResponse: response.UseGiftCardAmountResponse{Balance: 100.0, IsUsed: false, Message: "Gift card has expired."},
+            expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("100.00"), IsUsed: false, Message: "Gift card has expired."},
             expectedError:    app.ErrGiftCardExpired, 
 			expectUpdateCall: true,
-			expectedNewBalance: 100.0,
+			expectedNewBalance: money.MustParse("0.00"),
 			expectedNewStatus: expiredStatus,
         },
 		{
             name:           "error from UpdateGiftCardBalanceAndStatus on successful use",
             giftCardNumber: validCardNumber,
-            amountToUse:    50.0,
+            amountToUse:    money.MustParse("50.00"),
             mockSetup: func(mockRepo *MockGiftCardRepository) {
-                mockRepo.On("GetByGiftCardNumber", ctx, validCardNumber).Return(&models.GiftCard{
+                mockRepo.On("GetByGiftCardNumberForUpdate", ctx, validCardNumber).Return(&models.GiftCard{
                     Code:           validCardCode, 
                     GiftCardNumber: validCardNumber,
-                    Balance:        100.0,
+                    Balance:        money.MustParse("100.00"),
                     Status:         activeStatus,
                     ExpirationDate: tomorrow,
                 }, nil).Once()
-                mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, 50.0, activeStatus).Return(errors.New("update failed")).Once()
+                mockRepo.On("GetHeldAmount", ctx, uint(0), mock.Anything).Return(money.Amount(0), nil).Once()
+                mockRepo.On("CreateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+                mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, validCardCode, money.MustParse("50.00"), activeStatus).Return(errors.New("update failed")).Once()
             },
-            expectedResponse: response.UseGiftCardAmountResponse{Balance: 100.0, IsUsed: false, Message: "Failed to update gift card after use."}, 
+            expectedResponse: response.UseGiftCardAmountResponse{Balance: money.MustParse("100.00"), IsUsed: false, Message: "Failed to update gift card after use."}, 
             expectedError:    errors.New("update failed"),
 			expectUpdateCall: true,
-			expectedNewBalance: 50.0,
+			expectedNewBalance: money.MustParse("50.00"),
 			expectedNewStatus: activeStatus,
         },
 	}
@@ -277,10 +641,12 @@ func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(MockGiftCardRepository) 
-			useCase := NewGiftCardUseCase(mockRepo) 
+			// Expired cards read their expiry policy, which is not configured
+			useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), noSettings(), new(MockAuditLogRepository), fakeTransactor{}) 
 			tt.mockSetup(mockRepo)
 
-			resp, err := useCase.UseGiftCardAmount(ctx, tt.giftCardNumber, tt.amountToUse)
+			resp, err := useCase.UseGiftCardAmount(ctx, tt.giftCardNumber, request.UseGiftCardAmountRequest{Amount: tt.amountToUse})
+			tt.expectedResponse.GiftCardNumber = tt.giftCardNumber
 
 			assert.Equal(t, tt.expectedResponse, resp, "Response struct does not match for test: %s", tt.name)
 			
@@ -294,25 +660,77 @@ func TestGiftCardUseCase_UseGiftCardAmount(t *testing.T) {
 			if tt.expectUpdateCall { 
 				mockRepo.AssertCalled(t, "UpdateGiftCardBalanceAndStatus", ctx, validCardCode, tt.expectedNewBalance, tt.expectedNewStatus)
 			} else {
-				mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.AnythingOfTypeArgument("context.backgroundCtx"), mock.AnythingOfTypeArgument("string"), mock.AnythingOfTypeArgument("float64"), mock.AnythingOfTypeArgument("string"))
+				mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.AnythingOfTypeArgument("context.backgroundCtx"), mock.AnythingOfTypeArgument("string"), mock.AnythingOfTypeArgument("money.Amount"), mock.AnythingOfTypeArgument("models.GiftCardStatus"))
 			}
 			mockRepo.AssertExpectations(t) 
 		})
 	}
 }
 
+func TestGiftCardUseCase_UseGiftCardAmount_Currency(t *testing.T) {
+	ctx := context.Background()
+	cardNumber := "GC12345678901237"
+	newCard := func() *models.GiftCard {
+		return &models.GiftCard{
+			ID:             1,
+			Code:           "test-code-fx",
+			GiftCardNumber: cardNumber,
+			Balance:        money.MustParse("100.00"),
+			Currency:       "USD",
+			Status:         "active",
+			ExpirationDate: time.Now().AddDate(0, 0, 1),
+		}
+	}
+
+	t.Run("converts with the configured exchange rate", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(newCard(), nil).Once()
+		mockSettings.On("GetSetting", ctx, "exchange_rate.EUR.USD").Return(&models.Setting{Name: "exchange_rate.EUR.USD", Value: "1.085"}, nil).Once()
+		mockRepo.On("GetHeldAmount", ctx, uint(1), mock.Anything).Return(money.Amount(0), nil).Once()
+		mockRepo.On("CreateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+		mockRepo.On("RecordGiftCardUse", ctx, "test-code-fx", mock.AnythingOfType("time.Time")).Return(nil).Once()
+		mockRepo.On("UpdateAuthorization", ctx, mock.AnythingOfType("*models.Authorization")).Return(nil).Once()
+		// 10.00 EUR * 1.085 = 10.85 USD
+		mockRepo.On("UpdateGiftCardBalanceAndStatus", ctx, "test-code-fx", money.MustParse("89.15"), models.GiftCardStatusActive).Return(nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.Currency == "USD" && isLedgerEntry(models.TransactionTypeRedemption, money.MustParse("-10.85"), money.MustParse("89.15"))(transaction)
+		})).Return(nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, cardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00"), Currency: "EUR"})
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("89.15"), resp.Balance)
+		assert.Equal(t, "USD", resp.Currency)
+		mockRepo.AssertExpectations(t)
+		mockSettings.AssertExpectations(t)
+	})
+
+	t.Run("rejects a currency without exchange rate", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetByGiftCardNumberForUpdate", ctx, cardNumber).Return(newCard(), nil).Once()
+		mockSettings.On("GetSetting", ctx, "exchange_rate.CLP.USD").Return(nil, nil).Once()
+
+		resp, err := useCase.UseGiftCardAmount(ctx, cardNumber, request.UseGiftCardAmountRequest{Amount: money.MustParse("1000.00"), Currency: "CLP"})
+		assert.ErrorIs(t, err, app.ErrCurrencyMismatch)
+		assert.False(t, resp.IsUsed)
+		assert.Equal(t, money.MustParse("100.00"), resp.Balance)
+		mockRepo.AssertNotCalled(t, "UpdateGiftCardBalanceAndStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
 func TestGiftCardUseCase_UpdateGiftCard(t *testing.T) {
     ctx := context.Background()
     testCode := "test-code-for-update" 
     updateReq := request.UpdateGiftCardRequest{
-        Type:    "virtual",
-        Balance: 50.0,
-        Status:  "inactive",
+        Type: "virtual",
     }
 
     t.Run("successful update", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(&models.GiftCard{Code: testCode}, nil).Once() 
         mockRepo.On("UpdateGiftCard", ctx, testCode, updateReq).Return(nil).Once()
 
@@ -323,7 +741,7 @@ func TestGiftCardUseCase_UpdateGiftCard(t *testing.T) {
 
 	t.Run("update returns error", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(&models.GiftCard{Code: testCode}, nil).Once() 
         mockRepo.On("UpdateGiftCard", ctx, testCode, updateReq).Return(errors.New("db update error")).Once()
 
@@ -335,7 +753,7 @@ func TestGiftCardUseCase_UpdateGiftCard(t *testing.T) {
 
     t.Run("gift card not found for update", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(nil, gorm.ErrRecordNotFound).Once() 
 
         err := useCase.UpdateGiftCard(ctx, testCode, updateReq)
@@ -347,7 +765,7 @@ func TestGiftCardUseCase_UpdateGiftCard(t *testing.T) {
 
 	t.Run("error from GetGiftCardByCode (not RecordNotFound)", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(nil, errors.New("other db error")).Once() 
 
         err := useCase.UpdateGiftCard(ctx, testCode, updateReq)
@@ -365,7 +783,7 @@ func TestGiftCardUseCase_DeleteGiftCard(t *testing.T) {
 
     t.Run("successful delete", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(&models.GiftCard{Code: testCode}, nil).Once() 
         mockRepo.On("DeleteGiftCard", ctx, testCode).Return(nil).Once()
 
@@ -376,7 +794,7 @@ func TestGiftCardUseCase_DeleteGiftCard(t *testing.T) {
 
 	t.Run("delete returns error", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(&models.GiftCard{Code: testCode}, nil).Once() 
         mockRepo.On("DeleteGiftCard", ctx, testCode).Return(errors.New("db delete error")).Once()
 
@@ -388,7 +806,7 @@ func TestGiftCardUseCase_DeleteGiftCard(t *testing.T) {
 
     t.Run("gift card not found for delete", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(nil, gorm.ErrRecordNotFound).Once() 
 
         err := useCase.DeleteGiftCard(ctx, testCode)
@@ -400,7 +818,7 @@ func TestGiftCardUseCase_DeleteGiftCard(t *testing.T) {
 
 	t.Run("error from GetGiftCardByCode (not RecordNotFound) on delete", func(t *testing.T) {
 		mockRepo := new(MockGiftCardRepository)
-		useCase := NewGiftCardUseCase(mockRepo)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
         mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(nil, errors.New("another db error")).Once() 
 
         err := useCase.DeleteGiftCard(ctx, testCode)
@@ -410,3 +828,177 @@ func TestGiftCardUseCase_DeleteGiftCard(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "DeleteGiftCard", mock.Anything, mock.Anything)
     })
 }
+
+func TestGiftCardUseCase_UseGiftCardAmount_MalformedNumber(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+	// A well formed number without a valid check digit may still be imported
+	mockRepo.On("ImportedGiftCardNumberExists", ctx, "GC12345678901238").Return(false, nil).Once()
+
+	for _, number := range []string{"GC12345678901238", "GC1234", "gc12345678901237", "GC1234'; --"} {
+		resp, err := useCase.UseGiftCardAmount(ctx, number, request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00")})
+		assert.ErrorIs(t, err, app.ErrInvalidGiftCardNumber, number)
+		assert.Equal(t, "Invalid gift card number.", resp.Message)
+	}
+	mockRepo.AssertNotCalled(t, "GetByGiftCardNumberForUpdate", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUseCase_UseGiftCardAmount_ImportedNumber(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockGiftCardRepository)
+	useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+	mockRepo.On("ImportedGiftCardNumberExists", ctx, "AB12CD34EF56").Return(true, nil).Once()
+	mockRepo.On("GetByGiftCardNumberForUpdate", ctx, "AB12CD34EF56").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := useCase.UseGiftCardAmount(ctx, "AB12CD34EF56", request.UseGiftCardAmountRequest{Amount: money.MustParse("10.00")})
+	assert.ErrorIs(t, err, app.ErrGiftCardNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGiftCardUseCase_CreateGiftCard(t *testing.T) {
+	ctx := context.Background()
+	createReq := request.CreateGiftCardRequest{
+		Type:           "virtual",
+		Balance:        money.MustParse("75.00"),
+		ExpirationDate: time.Now().AddDate(1, 0, 0).Format("2006-01-02"),
+		Status:         "active",
+	}
+
+	t.Run("records the opening balance in the ledger", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, mock.AnythingOfType("string")).Return(nil, nil)
+		mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+		var pinHash string
+		mockRepo.On("CreateGiftCard", ctx, createReq, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			pinHash = args.String(4)
+		}).Return(&models.GiftCard{ID: 7, Balance: money.MustParse("75.00")}, nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.GiftCardID == 7 && isLedgerEntry(models.TransactionTypeCreate, money.MustParse("75.00"), money.MustParse("75.00"))(transaction) &&
+				transaction.PricePaid != nil && *transaction.PricePaid == money.MustParse("75.00")
+		})).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.MatchedBy(func(order *models.Order) bool {
+			return *order.GiftCardID == 7 && order.CampaignID == nil && order.TotalAmount == money.MustParse("75.00") && order.DiscountAmount == 0
+		})).Return(nil).Once()
+
+		resp, err := useCase.CreateGiftCard(ctx, createReq)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.GiftCardNumber)
+		assert.Equal(t, money.MustParse("75.00"), resp.PricePaid)
+		assert.Len(t, resp.Pin, pin.Length)
+		assert.NotEqual(t, resp.Pin, pinHash, "the PIN must be stored hashed")
+		ok, err := pin.Verify(pinHash, resp.Pin)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("physical cards are created issued", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, mock.AnythingOfType("string")).Return(nil, nil)
+		physicalReq := createReq
+		physicalReq.Type = "physical"
+		issuedReq := physicalReq
+		issuedReq.Status = "issued"
+		mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+		mockRepo.On("CreateGiftCard", ctx, issuedReq, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(&models.GiftCard{ID: 7, Balance: money.MustParse("75.00")}, nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.AnythingOfType("*models.Transaction")).Return(nil).Once()
+		mockRepo.On("CreateOrder", ctx, mock.AnythingOfType("*models.Order")).Return(nil).Once()
+
+		_, err := useCase.CreateGiftCard(ctx, physicalReq)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ledger failure fails the creation", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, mock.AnythingOfType("string")).Return(nil, nil)
+		mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+		mockRepo.On("CreateGiftCard", ctx, createReq, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(&models.GiftCard{ID: 7, Balance: money.MustParse("75.00")}, nil).Once()
+		mockRepo.On("CreateTransaction", ctx, mock.Anything).Return(errors.New("ledger error")).Once()
+
+		_, err := useCase.CreateGiftCard(ctx, createReq)
+		assert.EqualError(t, err, "ledger error")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("campaign prefix takes precedence over the type prefix", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "giftcard_number.prefix.campaign.12").Return(&models.Setting{Value: "GC6035"}, nil).Once()
+		mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+
+		number, err := useCase.GenerateGiftCardNumber(ctx, "virtual", 12)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(number, "GC6035"), number)
+		assert.True(t, generators.ValidGiftcardNumber(number), number)
+		mockSettings.AssertNotCalled(t, "GetSetting", ctx, "giftcard_number.prefix.virtual")
+	})
+
+	t.Run("type prefix", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "giftcard_number.prefix.physical").Return(&models.Setting{Value: "GP"}, nil).Once()
+		mockRepo.On("GiftCardNumberExists", ctx, mock.AnythingOfType("string")).Return(false, nil).Once()
+
+		number, err := useCase.GenerateGiftCardNumber(ctx, "physical", 0)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(number, "GP"), number)
+		assert.Len(t, number, generators.NumberLength)
+	})
+
+	t.Run("misconfigured prefix", func(t *testing.T) {
+		mockSettings := new(MockSettingRepository)
+		useCase := NewGiftCardUseCase(new(MockGiftCardRepository), new(MockCampaignRepository), mockSettings, new(MockAuditLogRepository), fakeTransactor{})
+		mockSettings.On("GetSetting", ctx, "giftcard_number.prefix.virtual").Return(&models.Setting{Name: "giftcard_number.prefix.virtual", Value: "gc-1"}, nil).Once()
+
+		_, err := useCase.GenerateGiftCardNumber(ctx, "virtual", 0)
+		assert.ErrorIs(t, err, generators.ErrInvalidPrefix)
+	})
+}
+
+func TestGiftCardUseCase_GetGiftCardTransactions(t *testing.T) {
+	ctx := context.Background()
+	testCode := "test-code-for-ledger"
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("returns the requested page", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(&models.GiftCard{ID: 3, Code: testCode}, nil).Once()
+		mockRepo.On("ListTransactions", ctx, uint(3), 10, 10).Return([]models.Transaction{
+			{ID: 12, Amount: money.MustParse("-20.00"), BalanceAfter: money.MustParse("30.00"), TransactionType: models.TransactionTypeRedemption, Reference: "ref-1", CreatedAt: createdAt},
+		}, int64(11), nil).Once()
+
+		result, err := useCase.GetGiftCardTransactions(ctx, testCode, 2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, response.TransactionListResponse{
+			Transactions: []response.TransactionResponse{
+				{ID: 12, Type: models.TransactionTypeRedemption, Amount: money.MustParse("-20.00"), BalanceAfter: money.MustParse("30.00"), Reference: "ref-1", CreatedAt: "2024-05-01 10:30:00"},
+			},
+			Page:     2,
+			PageSize: 10,
+			Total:    11,
+		}, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("gift card not found", func(t *testing.T) {
+		mockRepo := new(MockGiftCardRepository)
+		useCase := NewGiftCardUseCase(mockRepo, new(MockCampaignRepository), new(MockSettingRepository), new(MockAuditLogRepository), fakeTransactor{})
+		mockRepo.On("GetGiftCardByCode", ctx, testCode).Return(nil, gorm.ErrRecordNotFound).Once()
+
+		_, err := useCase.GetGiftCardTransactions(ctx, testCode, 1, 20)
+		assert.Equal(t, app.ErrGiftCardNotFound, err)
+		mockRepo.AssertNotCalled(t, "ListTransactions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
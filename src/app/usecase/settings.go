@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"GiftWize/src/infreaestructure/repository"
+	"GiftWize/src/shared/money"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// intSetting reads a positive integer setting. ok is false when it is not configured.
+func intSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string) (value int, ok bool, err error) {
+	return minIntSetting(ctx, settingRepo, name, 1)
+}
+
+// nonNegativeIntSetting reads an integer setting that may be 0, e.g. to turn
+// a rule off for one card type. ok is false when it is not configured.
+func nonNegativeIntSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string) (value int, ok bool, err error) {
+	return minIntSetting(ctx, settingRepo, name, 0)
+}
+
+// minIntSetting reads an integer setting of at least minValue. ok is false
+// when it is not configured.
+func minIntSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string, minValue int) (value int, ok bool, err error) {
+	setting, err := settingRepo.GetSetting(ctx, name)
+	if err != nil || setting == nil {
+		return 0, false, err
+	}
+
+	value, err = strconv.Atoi(setting.Value)
+	if err != nil || value < minValue {
+		return 0, false, fmt.Errorf("invalid %s setting %q", name, setting.Value)
+	}
+	return value, true, nil
+}
+
+// stringSetting reads a non-empty string setting. ok is false when it is not configured.
+func stringSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string) (value string, ok bool, err error) {
+	setting, err := settingRepo.GetSetting(ctx, name)
+	if err != nil || setting == nil || setting.Value == "" {
+		return "", false, err
+	}
+	return setting.Value, true, nil
+}
+
+// amountSetting reads a non-negative money setting. ok is false when it is not configured.
+func amountSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string) (value money.Amount, ok bool, err error) {
+	setting, err := settingRepo.GetSetting(ctx, name)
+	if err != nil || setting == nil {
+		return 0, false, err
+	}
+
+	value, err = money.Parse(setting.Value)
+	if err != nil || value < 0 {
+		return 0, false, fmt.Errorf("invalid %s setting %q", name, setting.Value)
+	}
+	return value, true, nil
+}
+
+// boolSetting reads a boolean setting, returning fallback when it is not configured.
+func boolSetting(ctx context.Context, settingRepo repository.ISettingRepository, name string, fallback bool) (bool, error) {
+	setting, err := settingRepo.GetSetting(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if setting == nil {
+		return fallback, nil
+	}
+
+	value, err := strconv.ParseBool(setting.Value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s setting %q", name, setting.Value)
+	}
+	return value, nil
+}
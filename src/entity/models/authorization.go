@@ -0,0 +1,34 @@
+package models
+
+import (
+	"GiftWize/src/shared/money"
+	"time"
+)
+
+// Authorization statuses. Only pending authorizations hold funds.
+const (
+	AuthorizationStatusPending           = "pending"
+	AuthorizationStatusCaptured          = "captured"
+	AuthorizationStatusPartiallyCaptured = "partially_captured"
+	AuthorizationStatusVoided            = "voided"
+	AuthorizationStatusExpired           = "expired"
+)
+
+// Authorization is a hold on gift card funds. While pending and not past
+// ExpiresAt it reduces the available balance of the card but not its ledger
+// balance; capturing it deducts the captured amount from the balance.
+type Authorization struct {
+	ID             uint         `gorm:"primaryKey;autoIncrement"`
+	Code           string       `gorm:"size:50;uniqueIndex;not null"`
+	GiftCardID     uint         `gorm:"index"`
+	GiftCard       GiftCard     `gorm:"foreignKey:GiftCardID"`
+	Amount         money.Amount `gorm:"type:decimal(10,2)"`
+	CapturedAmount money.Amount `gorm:"type:decimal(10,2)"`
+	Currency       string       `gorm:"size:3"`
+	Status         string       `gorm:"size:50;index"`
+	ExpiresAt      time.Time    `gorm:"index"`
+	CapturedAt     *time.Time
+	VoidedAt       *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Balance inquiry results.
+const (
+	BalanceInquiryResultOK            = "ok"
+	BalanceInquiryResultInvalidNumber = "invalid_number"
+	BalanceInquiryResultNotFound      = "not_found"
+	BalanceInquiryResultInvalidPin    = "invalid_pin"
+	BalanceInquiryResultPinLocked     = "pin_locked"
+	BalanceInquiryResultError         = "error"
+)
+
+// BalanceInquiry records a balance lookup made at a point of sale. It is a
+// non-financial event: it never moves funds and is kept apart from the
+// transaction ledger. Lookups of unknown cards are recorded too, with no
+// GiftCardID, so card number enumeration can be spotted.
+type BalanceInquiry struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement"`
+	GiftCardID     *uint     `gorm:"index"`
+	GiftCardNumber string    `gorm:"size:50;index"`
+	TerminalID     string    `gorm:"size:100"`
+	Result         string    `gorm:"size:50"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index"`
+}
@@ -1,18 +1,45 @@
 package models
 
 import (
+	"GiftWize/src/shared/money"
 	"time"
 )
 
+// Campaign lifecycle states, derived from the campaign dates and IsEnabled.
+const (
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusRunning   = "running"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusEnded     = "ended"
+)
+
 type Campaign struct {
-	ID                 uint      `gorm:"primaryKey;autoIncrement"`
-	CampaignUUID       string    `gorm:"size:255"`
-	Name               string    `gorm:"size:255"`
-	Description        string    `gorm:"type:text"`
-	StartDate          time.Time `gorm:"type:date"`
-	EndDate            time.Time `gorm:"type:date"`
-	IsEnabled          bool      `gorm:"default:true"`
-	DiscountPercentage float64   `gorm:"type:decimal(5,2)"`
-	CreatedAt          time.Time `gorm:"autoCreateTime"`
-	UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	CampaignUUID string    `gorm:"size:255"`
+	Name         string    `gorm:"size:255"`
+	Description  string    `gorm:"type:text"`
+	StartDate    time.Time `gorm:"type:date"`
+	EndDate      time.Time `gorm:"type:date"`
+	// IsEnabled is the manual switch; a disabled campaign is paused until
+	// enabled again or ended by its end date
+	IsEnabled bool
+	// Status is the lifecycle state as last applied by the campaign
+	// scheduler, and EndedAt when the scheduler ended the campaign
+	Status  string `gorm:"size:20;index;default:scheduled"`
+	EndedAt *time.Time
+	// CardsExpiryPending is set when the campaign ended with its promotional
+	// cards to be expired, until the scheduler has expired all of them
+	CardsExpiryPending bool    `gorm:"default:false"`
+	DiscountPercentage float64 `gorm:"type:decimal(5,2)"`
+	// Budget is the total face value that can be issued under the campaign,
+	// MaxCards the number of cards and MaxCardsPerCustomer the number of
+	// cards sold to one customer. Zero means no limit.
+	Budget              money.Amount `gorm:"type:decimal(12,2);default:0"`
+	MaxCards            int          `gorm:"type:int;default:0"`
+	MaxCardsPerCustomer int          `gorm:"type:int;default:0"`
+	// IssuedValue and IssuedCards are what was issued against the limits
+	IssuedValue money.Amount `gorm:"type:decimal(12,2);default:0"`
+	IssuedCards int          `gorm:"type:int;default:0"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"GiftWize/src/shared/money"
+	"time"
+)
+
+// CampaignCurrencyStats aggregates the cards of a campaign in one currency.
+// It is a query result, not a table.
+type CampaignCurrencyStats struct {
+	Currency           string
+	Cards              int64
+	ActivatedCards     int64
+	FullyRedeemedCards int64
+	ExpiredCards       int64
+	// FaceValue is what was loaded at sale and activation, Redeemed the
+	// redemptions net of refunds, Breakage the value forfeited on expiry and
+	// Outstanding the balance still spendable
+	FaceValue   money.Amount
+	Redeemed    money.Amount
+	Breakage    money.Amount
+	Outstanding money.Amount
+}
+
+// CampaignRedemptionPeriod is what was redeemed from the cards of a campaign
+// in one currency during the period starting at Period. It is a query
+// result, not a table.
+type CampaignRedemptionPeriod struct {
+	Period      time.Time
+	Currency    string
+	Redemptions int64
+	Amount      money.Amount
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Expiry sweep run statuses.
+const (
+	ExpirySweepStatusCompleted = "completed"
+	ExpirySweepStatusFailed    = "failed"
+)
+
+// ExpirySweepRun records one pass of the background sweeper that expires
+// cards past their expiration date. Only runs that held the sweep lock are
+// recorded.
+type ExpirySweepRun struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	StartedAt    time.Time `gorm:"index"`
+	FinishedAt   time.Time
+	ExpiredCount int    `gorm:"type:int"`
+	Status       string `gorm:"size:50"`
+	Error        string `gorm:"type:text"`
+}
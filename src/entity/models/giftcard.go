@@ -1,28 +1,39 @@
 package models
 
 import (
+	"GiftWize/src/shared/money"
 	"time"
 )
 
+// GiftCardTypePhysical cards are created issued and must be activated at a register.
+const GiftCardTypePhysical = "physical"
+
 type GiftCard struct {
-	ID             uint      `gorm:"primaryKey;autoIncrement"`
-	GiftCardNumber string    `gorm:"size:50;unique"`
-	Type           string    `gorm:"size:50"`
-	Balance        float64   `gorm:"type:decimal(10,2)"`
-	ExpirationDate time.Time `gorm:"type:date"`
-	Status         string    `gorm:"size:50"`
-	IsPromotional  bool      `gorm:"default:false"`
-	CampaignID     uint      `gorm:"index"`
-	Campaign       Campaign  `gorm:"foreignKey:CampaignID"`
-	Inventory      Inventory `gorm:"foreignKey:GiftCardID"`
-	InventoryID    uint      `gorm:"index"`
-	CreatedAt      time.Time `gorm:"autoCreateTime"`
-	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
-	Code           string    `gorm:"size:50;unique;not null"`
-	InitialBalance float64   `gorm:"type:decimal(10,2)"`
-	ActivationDate time.Time `gorm:"type:timestamp"`
-	LastUsedDate   time.Time `gorm:"type:timestamp"`
-	PinCode        string    `gorm:"size:6"`
-	MaxUses        int       `gorm:"type:int"`
-	CurrentUses    int       `gorm:"type:int;default:0"`
+	ID                     uint           `gorm:"primaryKey;autoIncrement"`
+	GiftCardNumber         string         `gorm:"size:50;unique"`
+	Type                   string         `gorm:"size:50"`
+	Balance                money.Amount   `gorm:"type:decimal(10,2)"`
+	Currency               string         `gorm:"size:3;not null;default:USD"`
+	ExpirationDate         time.Time      `gorm:"type:date"`
+	OriginalExpirationDate *time.Time     `gorm:"type:date"` // Set when the expiration date is first extended
+	Status                 GiftCardStatus `gorm:"size:50;index"`
+	IsPromotional          bool           `gorm:"default:false"`
+	CampaignID             *uint          `gorm:"index"`
+	Campaign               Campaign       `gorm:"foreignKey:CampaignID"`
+	Inventory              Inventory      `gorm:"foreignKey:InventoryID"`
+	InventoryID            *uint          `gorm:"index"`
+	IssuanceJobID          *uint          `gorm:"index"`         // Bulk issuance the card was created by
+	Imported               bool           `gorm:"default:false"` // Created by a CSV import, with a number issued elsewhere
+	CreatedAt              time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt              time.Time      `gorm:"autoUpdateTime"`
+	Code                   string         `gorm:"size:50;unique;not null"`
+	InitialBalance         money.Amount   `gorm:"type:decimal(10,2)"`
+	ActivationDate         *time.Time     `gorm:"type:timestamp"`
+	ActivatedBy            string         `gorm:"size:255"`
+	LastUsedDate           time.Time      `gorm:"type:timestamp"`
+	PinHash                string         `gorm:"size:255"` // bcrypt hash, the PIN itself is never stored
+	FailedPinAttempts      int            `gorm:"type:int;default:0"`
+	PinLockedAt            *time.Time     `gorm:"type:timestamp"`
+	MaxUses                int            `gorm:"type:int"`
+	CurrentUses            int            `gorm:"type:int;default:0"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// GiftCardStatus is the lifecycle state of a gift card.
+type GiftCardStatus string
+
+const (
+	// GiftCardStatusIssued cards exist but cannot be redeemed until activated.
+	GiftCardStatusIssued GiftCardStatus = "issued"
+	// GiftCardStatusActive cards accept redemptions, holds and reloads.
+	GiftCardStatusActive GiftCardStatus = "active"
+	// GiftCardStatusSuspended cards are temporarily blocked, e.g. while fraud is investigated.
+	GiftCardStatusSuspended GiftCardStatus = "suspended"
+	// GiftCardStatusUsed cards have been redeemed down to a zero balance.
+	GiftCardStatusUsed GiftCardStatus = "used"
+	// GiftCardStatusExpired cards are past their expiration date; their balance was forfeited.
+	GiftCardStatusExpired GiftCardStatus = "expired"
+	// GiftCardStatusCancelled cards were withdrawn permanently.
+	GiftCardStatusCancelled GiftCardStatus = "cancelled"
+)
+
+// GiftCardStatusChange records one lifecycle transition of a gift card.
+type GiftCardStatusChange struct {
+	ID         uint           `gorm:"primaryKey;autoIncrement"`
+	GiftCardID uint           `gorm:"index"`
+	GiftCard   GiftCard       `gorm:"foreignKey:GiftCardID"`
+	FromStatus GiftCardStatus `gorm:"size:50"`
+	ToStatus   GiftCardStatus `gorm:"size:50"`
+	Reason     string         `gorm:"size:255"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+}
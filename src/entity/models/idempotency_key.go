@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// IdempotencyKey stores the first response produced for an Idempotency-Key
+// header so that retries of the same request can be answered without
+// executing it again.
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	Key          string    `gorm:"size:255;uniqueIndex"`
+	RequestHash  string    `gorm:"size:64"`
+	Completed    bool      `gorm:"default:false"`
+	StatusCode   int       `gorm:"type:int"`
+	ContentType  string    `gorm:"size:255"`
+	ResponseBody []byte    `gorm:"type:bytea"`
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+// Inventory statuses. Only cards from distributed inventory can be activated.
+const (
+	InventoryStatusInStock     = "in_stock"
+	InventoryStatusDistributed = "distributed"
+)
+
 type Inventory struct {
 	ID           uint   `gorm:"primaryKey;autoIncrement"`
 	LocationType string `gorm:"size:50"`
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"GiftWize/src/shared/money"
+	"time"
+)
+
+// Issuance job statuses.
+const (
+	IssuanceJobStatusPending   = "pending"
+	IssuanceJobStatusRunning   = "running"
+	IssuanceJobStatusCompleted = "completed"
+	IssuanceJobStatusFailed    = "failed"
+	// IssuanceJobStatusCancelled is set when the campaign of the job ends
+	// before all its cards are issued
+	IssuanceJobStatusCancelled = "cancelled"
+)
+
+// IssuanceJob is a background bulk issuance of Count identical gift cards.
+// IssuedCount tracks the progress; the issued cards reference the job.
+type IssuanceJob struct {
+	ID           uint         `gorm:"primaryKey;autoIncrement"`
+	Code         string       `gorm:"size:50;uniqueIndex;not null"`
+	Count        int          `gorm:"type:int"`
+	IssuedCount  int          `gorm:"type:int;default:0"`
+	Denomination money.Amount `gorm:"type:decimal(10,2)"`
+	// UnitPrice is the price paid per card, after the campaign discount
+	UnitPrice      money.Amount `gorm:"type:decimal(10,2)"`
+	Currency       string       `gorm:"size:3"`
+	Type           string       `gorm:"size:50"`
+	ExpirationDate time.Time    `gorm:"type:date"`
+	IsPromotional  bool         `gorm:"default:false"`
+	CampaignID     *uint        `gorm:"index"`
+	InventoryID    *uint        `gorm:"index"`
+	CustomerID     *uint        `gorm:"index"`
+	Status         string       `gorm:"size:50;index"`
+	Error          string       `gorm:"type:text"`
+	CompletedAt    *time.Time
+	// ExportedAt is when the issued cards and their PINs were exported
+	ExportedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// IssuanceJobPin holds the PIN of a card issued by a job until the job is
+// exported, or purged unexported a while after the job finished. The card
+// itself only stores the PIN hash.
+type IssuanceJobPin struct {
+	GiftCardID    uint   `gorm:"primaryKey;autoIncrement:false"`
+	IssuanceJobID uint   `gorm:"index"`
+	Pin           string `gorm:"size:10"`
+}
@@ -1,16 +1,35 @@
 package models
 
 import (
+	"GiftWize/src/shared/money"
 	"time"
 )
 
+// Order statuses.
+const (
+	OrderStatusPaid = "paid"
+)
+
+// Order is the sale of gift cards, either a single card or the cards of a
+// bulk issuance job. FaceValue is the total balance loaded onto the cards and
+// TotalAmount the price paid for them, lower than FaceValue by the discount
+// of the campaign the cards were sold under. The amounts are wide enough for
+// the largest job: IssueGiftCardsRequest's maximum count of cards of the
+// largest decimal(10,2) denomination.
 type Order struct {
-	ID          uint      `gorm:"primaryKey;autoIncrement"`
-	CustomerID  uint      `gorm:"index"`
-	Customer    Customer  `gorm:"foreignKey:CustomerID"`
-	OrderDate   time.Time `gorm:"autoCreateTime"`
-	TotalAmount float64   `gorm:"type:decimal(10,2)"`
-	Status      string    `gorm:"size:50"`
-	CreatedAt   time.Time `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	ID             uint         `gorm:"primaryKey;autoIncrement"`
+	CustomerID     *uint        `gorm:"index"`
+	Customer       Customer     `gorm:"foreignKey:CustomerID"`
+	GiftCardID     *uint        `gorm:"index"`
+	IssuanceJobID  *uint        `gorm:"index"`
+	CampaignID     *uint        `gorm:"index"`
+	OrderDate      time.Time    `gorm:"autoCreateTime"`
+	Quantity       int          `gorm:"type:int"`
+	FaceValue      money.Amount `gorm:"type:decimal(15,2)"`
+	DiscountAmount money.Amount `gorm:"type:decimal(15,2)"`
+	TotalAmount    money.Amount `gorm:"type:decimal(15,2)"`
+	Currency       string       `gorm:"size:3"`
+	Status         string       `gorm:"size:50"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime"`
 }
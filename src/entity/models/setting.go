@@ -6,7 +6,7 @@ import (
 
 type Setting struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement"`
-	Name      string    `gorm:"size:255"`
+	Name      string    `gorm:"size:255;uniqueIndex"`
 	Value     string    `gorm:"type:text"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
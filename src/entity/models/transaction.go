@@ -1,14 +1,35 @@
 package models
 
 import (
+	"GiftWize/src/shared/money"
 	"time"
 )
 
+// Ledger movement types stored in Transaction.TransactionType.
+const (
+	TransactionTypeCreate        = "create"
+	TransactionTypeActivation    = "activation"
+	TransactionTypeRedemption    = "redemption"
+	TransactionTypeReload        = "reload"
+	TransactionTypeRefund        = "refund"
+	TransactionTypeExpiryForfeit = "expiry_forfeit"
+)
+
+// Transaction is a ledger entry for a balance movement on a gift card.
+// Amount is signed: credits are positive and debits negative.
 type Transaction struct {
-	ID              uint      `gorm:"primaryKey;autoIncrement"`
-	GiftCardID      uint      `gorm:"index"`
-	GiftCard        GiftCard  `gorm:"foreignKey:GiftCardID"`
-	Amount          float64   `gorm:"type:decimal(10,2)"`
-	TransactionType string    `gorm:"size:50"`
-	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	ID              uint         `gorm:"primaryKey;autoIncrement"`
+	GiftCardID      uint         `gorm:"index;index:idx_transactions_gift_card_type,priority:1"`
+	GiftCard        GiftCard     `gorm:"foreignKey:GiftCardID"`
+	Amount          money.Amount `gorm:"type:decimal(10,2)"`
+	BalanceAfter    money.Amount `gorm:"type:decimal(10,2)"`
+	Currency        string       `gorm:"size:3"`
+	TransactionType string       `gorm:"size:50;index:idx_transactions_gift_card_type,priority:2"` // Indexed with GiftCardID for campaign statistics
+	Reference       string       `gorm:"size:255"`
+	// RefundedTransactionID links a refund to the redemption it reverses.
+	RefundedTransactionID *uint `gorm:"index"`
+	// PricePaid is what was paid for the balance of a sold card, below Amount
+	// when the card was sold with a campaign discount. Nil on other entries.
+	PricePaid *money.Amount `gorm:"type:decimal(10,2)"`
+	CreatedAt time.Time     `gorm:"autoCreateTime"`
 }
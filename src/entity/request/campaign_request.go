@@ -1,6 +1,9 @@
 package request
 
-import "time"
+import (
+	"GiftWize/src/shared/money"
+	"time"
+)
 
 type CreateCampaignRequest struct {
 	Name               string    `json:"name" validate:"required,min=3,max=255"`
@@ -9,6 +12,10 @@ type CreateCampaignRequest struct {
 	EndDate            time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
 	IsEnabled          bool      `json:"is_enabled"`
 	DiscountPercentage float64   `json:"discount_percentage" validate:"required,min=0,max=100"`
+	// Limits of the cards issued under the campaign; 0 for no limit
+	Budget              money.Amount `json:"budget" validate:"min=0"`
+	MaxCards            int          `json:"max_cards" validate:"min=0"`
+	MaxCardsPerCustomer int          `json:"max_cards_per_customer" validate:"min=0"`
 }
 
 type UpdateCampaignRequest struct {
@@ -18,6 +25,23 @@ type UpdateCampaignRequest struct {
 	EndDate            time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
 	IsEnabled          bool      `json:"is_enabled"`
 	DiscountPercentage float64   `json:"discount_percentage" validate:"required,min=0,max=100"`
+	// Limits of the cards issued under the campaign; 0 for no limit, and
+	// left as they are when omitted
+	Budget              *money.Amount `json:"budget" validate:"omitempty,min=0"`
+	MaxCards            *int          `json:"max_cards" validate:"omitempty,min=0"`
+	MaxCardsPerCustomer *int          `json:"max_cards_per_customer" validate:"omitempty,min=0"`
+}
+
+// CampaignStatsRequest sets the period the redemptions of campaign
+// statistics are grouped by.
+type CampaignStatsRequest struct {
+	// day when omitted
+	Interval string `query:"interval" validate:"omitempty,oneof=day week month"`
+}
+
+// SearchRequest is the text of a campaign or gift card search.
+type SearchRequest struct {
+	Query string `query:"query" validate:"required,min=2,max=100"`
 }
 
 type FullTextSearchCampaignRequest struct {
@@ -1,6 +1,9 @@
 package request
 
 import (
+	"GiftWize/src/shared"
+	"GiftWize/src/shared/money"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,7 +14,7 @@ import (
 var validate *validator.Validate
 
 func init() {
-	validate = validator.New()
+	validate = shared.NewValidator()
 }
 
 func TestCreateCampaignRequest_Validation(t *testing.T) {
@@ -147,6 +150,20 @@ func TestCreateCampaignRequest_Validation(t *testing.T) {
 			expectedError: true,
 			errorFields:   []string{"DiscountPercentage"},
 		},
+		{
+			name: "negative budget and card limits",
+			request: CreateCampaignRequest{
+				Name:                "Summer Sale",
+				StartDate:           validStartDate,
+				EndDate:             validEndDate,
+				DiscountPercentage:  10.5,
+				Budget:              money.MustParse("-1.00"),
+				MaxCards:            -1,
+				MaxCardsPerCustomer: -1,
+			},
+			expectedError: true,
+			errorFields:   []string{"Budget", "MaxCards", "MaxCardsPerCustomer"},
+		},
 		{
 			name: "multiple errors",
 			request: CreateCampaignRequest{
@@ -192,6 +209,8 @@ func TestUpdateCampaignRequest_Validation(t *testing.T) {
 	now := time.Now()
 	validStartDate := now.Add(time.Hour * 24)
 	validEndDate := validStartDate.Add(time.Hour * 48)
+	noBudget, negativeBudget := money.Amount(0), money.MustParse("-1.00")
+	noLimit, negativeLimit := 0, -1
 
 	tests := []struct {
 		name          string
@@ -234,6 +253,33 @@ func TestUpdateCampaignRequest_Validation(t *testing.T) {
 			expectedError: true,
 			errorFields:   []string{"EndDate"},
 		},
+		{
+			name: "update removing the limits",
+			request: UpdateCampaignRequest{
+				Name:                "Updated Summer Sale",
+				StartDate:           validStartDate,
+				EndDate:             validEndDate,
+				DiscountPercentage:  15.0,
+				Budget:              &noBudget,
+				MaxCards:            &noLimit,
+				MaxCardsPerCustomer: &noLimit,
+			},
+			expectedError: false,
+		},
+		{
+			name: "update with negative limits",
+			request: UpdateCampaignRequest{
+				Name:                "Updated Summer Sale",
+				StartDate:           validStartDate,
+				EndDate:             validEndDate,
+				DiscountPercentage:  15.0,
+				Budget:              &negativeBudget,
+				MaxCards:            &negativeLimit,
+				MaxCardsPerCustomer: &negativeLimit,
+			},
+			expectedError: true,
+			errorFields:   []string{"Budget", "MaxCards", "MaxCardsPerCustomer"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -256,3 +302,50 @@ func TestUpdateCampaignRequest_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestCampaignStatsRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       CampaignStatsRequest
+		expectedError bool
+	}{
+		{name: "interval omitted", request: CampaignStatsRequest{}, expectedError: false},
+		{name: "weekly", request: CampaignStatsRequest{Interval: "week"}, expectedError: false},
+		{name: "unknown interval", request: CampaignStatsRequest{Interval: "hour"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSearchRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       SearchRequest
+		expectedError bool
+	}{
+		{name: "query", request: SearchRequest{Query: "summer sale"}, expectedError: false},
+		{name: "query omitted", request: SearchRequest{}, expectedError: true},
+		{name: "query too short", request: SearchRequest{Query: "s"}, expectedError: true},
+		{name: "query too long", request: SearchRequest{Query: strings.Repeat("s", 101)}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
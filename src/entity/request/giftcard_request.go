@@ -1,24 +1,176 @@
 package request
 
+import "GiftWize/src/shared/money"
+
 type CreateGiftCardRequest struct {
 	// Consider using oneof for predefined types: e.g., "virtual", "physical"
-	Type           string  `json:"type" validate:"required"`
-	Balance        float64 `json:"balance" validate:"required,min=0"`
+	Type string `json:"type" validate:"required"`
+	// Physical cards may be created empty, as their balance can be loaded at activation
+	Balance  money.Amount `json:"balance" validate:"required_unless=Type physical,min=0"`
+	Currency string       `json:"currency" validate:"required,iso4217,money_currency"`
 	// Add custom validation for future date if needed
-	ExpirationDate string  `json:"expiration_date" validate:"required"`
-	// Consider using oneof for predefined statuses: e.g., "active", "inactive", "expired"
-	Status         string  `json:"status" validate:"required"`
-	IsPromotional  bool    `json:"is_promotional"`
-	CampaignID     uint    `json:"campaign_id" validate:"omitempty,gt=0"`
+	ExpirationDate string `json:"expiration_date" validate:"required"`
+	// Cards are created issued or directly active; later changes go through ChangeGiftCardStatusRequest
+	Status        string `json:"status" validate:"required,oneof=issued active"`
+	IsPromotional bool   `json:"is_promotional"`
+	CampaignID    uint   `json:"campaign_id" validate:"omitempty,gt=0"`
+	// Inventory batch a physical card was taken from
+	InventoryID uint `json:"inventory_id" validate:"omitempty,gt=0"`
+	// Number of redemptions allowed, e.g. 1 for single-use promo cards; unlimited when omitted
+	MaxUses int `json:"max_uses" validate:"omitempty,gt=0"`
+	// Customer buying the card, counted against the campaign per-customer cap
+	CustomerID uint `json:"customer_id" validate:"omitempty,gt=0"`
 }
 
+// UpdateGiftCardRequest edits the descriptive fields of a card. The balance
+// is not among them: it only moves through reload, redemption, refund and
+// activation, which record it in the ledger. Neither is the expiration date,
+// which only moves through ExtendGiftCardExpirationRequest.
 type UpdateGiftCardRequest struct {
 	// Consider using oneof for predefined types: e.g., "virtual", "physical"
-	Type           string  `json:"type" validate:"required"`
-	Balance        float64 `json:"balance" validate:"required,min=0"`
-	// Add custom validation for future date if needed
-	ExpirationDate string  `json:"expiration_date" validate:"required"`
-	// Consider using oneof for predefined statuses: e.g., "active", "inactive", "expired"
-	Status         string  `json:"status" validate:"required"`
-	IsPromotional  bool    `json:"is_promotional"`
+	Type          string `json:"type" validate:"required"`
+	IsPromotional bool   `json:"is_promotional"`
+}
+
+// ChangeGiftCardStatusRequest moves a card along its lifecycle. Only the
+// manual transitions are accepted; used and expired are set by the system.
+type ChangeGiftCardStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended cancelled"`
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// ExtendGiftCardExpirationRequest moves the expiration date of a card later,
+// within the extension limit of its expiry policy.
+type ExtendGiftCardExpirationRequest struct {
+	ExpirationDate string `json:"expiration_date" validate:"required,datetime=2006-01-02"`
+	// Support agent or administrator performing the extension
+	ExtendedBy string `json:"extended_by" validate:"required,max=255"`
+	Reason     string `json:"reason" validate:"max=255"`
+}
+
+type ActivateGiftCardRequest struct {
+	// Amount loaded onto the card at activation; optional for preloaded cards
+	Amount money.Amount `json:"amount" validate:"omitempty,gt=0"`
+	// Operator or register that activated the card
+	ActivatedBy string `json:"activated_by" validate:"required,max=255"`
+}
+
+type UseGiftCardAmountRequest struct {
+	Amount money.Amount `json:"amount" validate:"required,gt=0"`
+	// Currency of Amount; defaults to the card currency when omitted
+	Currency string `json:"currency" validate:"omitempty,iso4217,money_currency"`
+	// PIN of the card; required for cards issued with one
+	Pin string `json:"pin" validate:"omitempty,numeric,len=6"`
+}
+
+// SplitTenderRequest pays Amount with several cards, drawing from each in
+// the order given until the amount is covered.
+type SplitTenderRequest struct {
+	Cards  []SplitTenderCard `json:"cards" validate:"required,min=1,max=5,unique=GiftCardNumber,dive"`
+	Amount money.Amount      `json:"amount" validate:"required,gt=0"`
+	// Currency of Amount; defaults to the currency of the first card when omitted
+	Currency string `json:"currency" validate:"omitempty,iso4217,money_currency"`
+}
+
+type SplitTenderCard struct {
+	GiftCardNumber string `json:"gift_card_number" validate:"required,max=50"`
+	// PIN of the card; required for cards issued with one
+	Pin string `json:"pin" validate:"omitempty,numeric,len=6"`
+}
+
+type AuthorizeGiftCardRequest struct {
+	Amount money.Amount `json:"amount" validate:"required,gt=0"`
+	// Currency of Amount; defaults to the card currency when omitted
+	Currency string `json:"currency" validate:"omitempty,iso4217,money_currency"`
+	// PIN of the card; required for cards issued with one
+	Pin string `json:"pin" validate:"omitempty,numeric,len=6"`
+}
+
+type GiftCardBalanceRequest struct {
+	// PIN of the card; required for cards issued with one
+	Pin string `json:"pin" validate:"omitempty,numeric,len=6"`
+}
+
+// BalanceInquiryRequest is a point of sale balance lookup. The body is optional.
+type BalanceInquiryRequest struct {
+	// PIN of the card; required for cards issued with one unless
+	// the balance_inquiry.require_pin setting is off
+	Pin string `json:"pin" validate:"omitempty,numeric,len=6"`
+	// Terminal making the inquiry, recorded with it
+	TerminalID string `json:"terminal_id" validate:"omitempty,max=100"`
+}
+
+// ResetGiftCardPinRequest unlocks a card's PIN. With Reissue a new PIN
+// replaces the current one.
+type ResetGiftCardPinRequest struct {
+	Reissue bool `json:"reissue"`
+	// Administrator performing the reset
+	ResetBy string `json:"reset_by" validate:"required,max=255"`
+}
+
+type ReloadGiftCardRequest struct {
+	Amount money.Amount `json:"amount" validate:"required,gt=0"`
+	// Currency of Amount; defaults to the card currency when omitted
+	Currency string `json:"currency" validate:"omitempty,iso4217,money_currency"`
+}
+
+type RefundTransactionRequest struct {
+	// Amount to refund; the remaining refundable amount when omitted
+	Amount money.Amount `json:"amount" validate:"omitempty,gt=0"`
+	Reason string       `json:"reason" validate:"max=255"`
+}
+
+type CaptureAuthorizationRequest struct {
+	// Amount to capture; the full authorized amount when omitted
+	Amount money.Amount `json:"amount" validate:"omitempty,gt=0"`
+}
+
+// IssueGiftCardsRequest orders Count identical cards, issued by a background job.
+type IssueGiftCardsRequest struct {
+	Count        int          `json:"count" validate:"required,gt=0,lte=100000"`
+	Denomination money.Amount `json:"denomination" validate:"required,gt=0"`
+	Currency     string       `json:"currency" validate:"required,iso4217,money_currency"`
+	// Consider using oneof for predefined types: e.g., "virtual", "physical"
+	Type           string `json:"type" validate:"required"`
+	ExpirationDate string `json:"expiration_date" validate:"required,datetime=2006-01-02"`
+	IsPromotional  bool   `json:"is_promotional"`
+	CampaignID     uint   `json:"campaign_id" validate:"omitempty,gt=0"`
+	// Inventory batch the physical cards are stocked in; required for them,
+	// as only cards distributed from inventory can be activated
+	InventoryID uint `json:"inventory_id" validate:"required_if=Type physical"`
+	// Customer ordering the cards, counted against the campaign per-customer cap
+	CustomerID uint `json:"customer_id" validate:"omitempty,gt=0"`
+}
+
+// ImportGiftCardsRequest selects whether a CSV import only validates its rows
+// or also creates the cards.
+type ImportGiftCardsRequest struct {
+	// dry_run when omitted
+	Mode string `query:"mode" validate:"omitempty,oneof=dry_run commit"`
+}
+
+// ImportGiftCardRow is one card of a CSV import, e.g. migrated from another
+// provider. The card keeps its number and, when given, its PIN; an imported
+// card without a PIN cannot be used until one is reissued.
+type ImportGiftCardRow struct {
+	GiftCardNumber string       `validate:"required,max=50"`
+	Type           string       `validate:"required,max=50"`
+	Balance        money.Amount `validate:"min=0"`
+	Currency       string       `validate:"required,iso4217,money_currency"`
+	ExpirationDate string       `validate:"required,datetime=2006-01-02"`
+	Status         string       `validate:"required,oneof=issued active suspended used expired cancelled"`
+	IsPromotional  bool
+	Pin            string `validate:"omitempty,numeric,min=4,max=10"`
+}
+
+// GiftCardExportFilter narrows the cards written by the CSV export. Empty
+// fields do not filter.
+type GiftCardExportFilter struct {
+	Status     string `query:"status" validate:"omitempty,oneof=issued active suspended used expired cancelled"`
+	Type       string `query:"type" validate:"max=50"`
+	Currency   string `query:"currency" validate:"omitempty,iso4217,money_currency"`
+	CampaignID uint   `query:"campaign_id" validate:"omitempty,gt=0"`
+	// Inclusive bounds on the expiration date
+	ExpiresAfter  string `query:"expires_after" validate:"omitempty,datetime=2006-01-02"`
+	ExpiresBefore string `query:"expires_before" validate:"omitempty,datetime=2006-01-02"`
 }
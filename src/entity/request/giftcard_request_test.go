@@ -1,9 +1,13 @@
 package request
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"GiftWize/src/shared"
+	"GiftWize/src/shared/money"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +36,8 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "valid request",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        100.00,
+				Balance:        money.MustParse("100.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 				IsPromotional:  false,
@@ -44,7 +49,8 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "valid request - promotional without campaign_id", // campaign_id is omitempty, gt=0
 			request: CreateGiftCardRequest{
 				Type:           "physical",
-				Balance:        50.00,
+				Balance:        money.MustParse("50.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 				IsPromotional:  true,
@@ -52,10 +58,23 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name: "status other than issued or active",
+			request: CreateGiftCardRequest{
+				Type:           "virtual",
+				Balance:        money.MustParse("50.00"),
+				Currency:       "USD",
+				ExpirationDate: futureDate,
+				Status:         "used",
+			},
+			expectedError: true,
+			errorFields:   []string{"Status"},
+		},
 		{
 			name: "missing type",
 			request: CreateGiftCardRequest{
-				Balance:        100.00,
+				Balance:        money.MustParse("100.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 			},
@@ -63,15 +82,15 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			errorFields:   []string{"Type"},
 		},
 		{
-			name: "missing balance", // Balance is float64, 'required' means it can't be zero value if not using pointers.
+			name: "missing balance", // Balance is money.Amount, 'required' means it can't be zero value if not using pointers.
 									 // The validation `min=0` allows 0. Let's test if required means it must be present.
-									 // Actually, for float64, 'required' means it cannot be the zero value (0.0).
+									 // Actually, for money.Amount, 'required' means it cannot be the zero value (0).
 									 // If 0 is a valid balance that needs to be explicitly set, the field should be a pointer or use `isset` tag.
-									 // Given `min=0`, 0 is allowed. The `required` tag on a non-pointer float64 means it must not be 0.
+									 // Given `min=0`, 0 is allowed. The `required` tag on a non-pointer money.Amount means it must not be 0.
 									 // This might be a slight conflict or nuance in validation tags.
 									 // Let's assume "required" means it must be explicitly provided if it were a pointer.
-									 // For a non-pointer, it means it cannot be its zero value (0 for float64).
-									 // If a balance of 0 is valid and distinct from "not provided", then `*float64` would be better.
+									 // For a non-pointer, it means it cannot be its zero value (0 for money.Amount).
+									 // If a balance of 0 is valid and distinct from "not provided", then `*money.Amount` would be better.
 									 // Test assuming current struct: required means not 0.
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
@@ -80,7 +99,7 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 				// Balance is 0.0
 			},
 			expectedError: true, 
-			errorFields:   []string{"Balance"}, // This will fail if 'required' on float64 allows 0. It typically doesn't.
+			errorFields:   []string{"Balance"}, // This will fail if 'required' on money.Amount allows 0. It typically doesn't.
 		},
         {
             name: "balance is exactly 0 (allowed by min=0, but required might make it fail)",
@@ -90,28 +109,65 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
                 ExpirationDate: futureDate,
                 Status:         "active",
             },
-            // If 'required' on float64 means 'not the zero value (0.0)', this will fail.
+            // If 'required' on money.Amount means 'not the zero value (0)', this will fail.
             // If 'min=0' takes precedence or 'required' is for presence (for pointers), it will pass.
             // Validator behavior: 'required' for non-pointer numeric types means != 0.
             expectedError: true, 
             errorFields:   []string{"Balance"},
         },
+		{
+			name: "physical card created empty",
+			request: CreateGiftCardRequest{
+				Type:           "physical",
+				Balance:        0,
+				Currency:       "USD",
+				ExpirationDate: futureDate,
+				Status:         "issued",
+			},
+			expectedError: false,
+		},
 		{
 			name: "balance less than zero",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        -10.00,
+				Balance:        money.MustParse("-10.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 			},
 			expectedError: true,
 			errorFields:   []string{"Balance"},
 		},
+		{
+			name: "invalid currency",
+			request: CreateGiftCardRequest{
+				Type:           "virtual",
+				Balance:        money.MustParse("100.00"),
+				Currency:       "DOLLARS",
+				ExpirationDate: futureDate,
+				Status:         "active",
+			},
+			expectedError: true,
+			errorFields:   []string{"Currency"},
+		},
+		{
+			name: "currency without cents",
+			request: CreateGiftCardRequest{
+				Type:           "virtual",
+				Balance:        money.MustParse("100.00"),
+				Currency:       "CLP",
+				ExpirationDate: futureDate,
+				Status:         "active",
+			},
+			expectedError: true,
+			errorFields:   []string{"Currency"},
+		},
 		{
 			name: "missing expiration date",
 			request: CreateGiftCardRequest{
 				Type:    "virtual",
-				Balance: 100.00,
+				Balance: money.MustParse("100.00"),
+				Currency: "USD",
 				Status:  "active",
 			},
 			expectedError: true,
@@ -121,7 +177,8 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "missing status",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        100.00,
+				Balance:        money.MustParse("100.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 			},
 			expectedError: true,
@@ -131,7 +188,8 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "invalid campaign_id (less than 1)",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        100.00,
+				Balance:        money.MustParse("100.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 				CampaignID:     0, // Invalid because of gt=0, but omitempty should make it pass if it's the zero value
@@ -144,7 +202,8 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "invalid campaign_id (explicitly 0 but gt=0)",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        100.00,
+				Balance:        money.MustParse("100.00"),
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 				CampaignID:     0, // Explicitly 0, should be omitted by `omitempty`
@@ -155,19 +214,46 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 			name: "valid campaign_id (greater than 0)",
 			request: CreateGiftCardRequest{
 				Type:           "virtual",
-				Balance:        10.00, // Not 0, so "required" passes for Balance
+				Balance:        money.MustParse("10.00"), // Not 0, so "required" passes for Balance
+				Currency:       "USD",
 				ExpirationDate: futureDate,
 				Status:         "active",
 				CampaignID:     123, 
 			},
 			expectedError: false, 
 		},
+		{
+			name: "single-use card",
+			request: CreateGiftCardRequest{
+				Type:           "virtual",
+				Balance:        money.MustParse("10.00"),
+				Currency:       "USD",
+				ExpirationDate: futureDate,
+				Status:         "active",
+				MaxUses:        1,
+			},
+			expectedError: false,
+		},
+		{
+			name: "negative max_uses",
+			request: CreateGiftCardRequest{
+				Type:           "virtual",
+				Balance:        money.MustParse("10.00"),
+				Currency:       "USD",
+				ExpirationDate: futureDate,
+				Status:         "active",
+				MaxUses:        -1,
+			},
+			expectedError: true,
+			errorFields:   []string{"MaxUses"},
+		},
 		{
 			name: "multiple errors",
 			request: CreateGiftCardRequest{
 				// Type missing
 				// Balance negative
-				Balance: -5.0,
+				Balance: money.MustParse("-5.00"),
+				Currency: "USD",
 				// ExpirationDate missing
 				// Status missing
 				CampaignID: 0, // This is fine due to omitempty
@@ -180,7 +266,7 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Ensure validator is initialized for each run if tests run in parallel or specific conditions
-			 v := validator.New()
+			 v := shared.NewValidator()
 			err := v.Struct(tt.request)
 			if tt.expectedError {
 				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
@@ -203,8 +289,6 @@ func TestCreateGiftCardRequest_Validation(t *testing.T) {
 
 func TestUpdateGiftCardRequest_Validation(t *testing.T) {
 	// validate = validator.New() // Ensure initialized if needed
-	futureDate := time.Now().Add(24 * time.Hour).Format("2006-01-02")
-
 	tests := []struct {
 		name          string
 		request       UpdateGiftCardRequest
@@ -214,51 +298,22 @@ func TestUpdateGiftCardRequest_Validation(t *testing.T) {
 		{
 			name: "valid update request",
 			request: UpdateGiftCardRequest{
-				Type:           "physical",
-				Balance:        75.00,
-				ExpirationDate: futureDate,
-				Status:         "inactive",
-				IsPromotional:  true,
+				Type:          "physical",
+				IsPromotional: true,
 			},
 			expectedError: false,
 		},
 		{
 			name: "update missing type",
-			request: UpdateGiftCardRequest{
-				Balance:        75.00,
-				ExpirationDate: futureDate,
-				Status:         "inactive",
-			},
+			request:       UpdateGiftCardRequest{},
 			expectedError: true,
 			errorFields:   []string{"Type"},
 		},
-		{
-            name: "update balance is exactly 0 (fails 'required')",
-            request: UpdateGiftCardRequest{
-                Type:           "virtual",
-                Balance:        0, 
-                ExpirationDate: futureDate,
-                Status:         "active",
-            },
-            expectedError: true, 
-            errorFields:   []string{"Balance"},
-        },
-		{
-			name: "update balance less than zero",
-			request: UpdateGiftCardRequest{
-				Type:           "physical",
-				Balance:        -5.00,
-				ExpirationDate: futureDate,
-				Status:         "inactive",
-			},
-			expectedError: true,
-			errorFields:   []string{"Balance"},
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			 v := validator.New()
+			 v := shared.NewValidator()
 			err := v.Struct(tt.request)
 			if tt.expectedError {
 				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
@@ -278,3 +333,285 @@ func TestUpdateGiftCardRequest_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestUseGiftCardAmountRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       UseGiftCardAmountRequest
+		expectedError bool
+	}{
+		{name: "valid amount", request: UseGiftCardAmountRequest{Amount: money.MustParse("25.50")}, expectedError: false},
+		{name: "missing amount", request: UseGiftCardAmountRequest{}, expectedError: true},
+		{name: "negative amount", request: UseGiftCardAmountRequest{Amount: money.MustParse("-1")}, expectedError: true},
+		{name: "with PIN", request: UseGiftCardAmountRequest{Amount: money.MustParse("25.50"), Pin: "012345"}, expectedError: false},
+		{name: "short PIN", request: UseGiftCardAmountRequest{Amount: money.MustParse("25.50"), Pin: "1234"}, expectedError: true},
+		{name: "non numeric PIN", request: UseGiftCardAmountRequest{Amount: money.MustParse("25.50"), Pin: "12a456"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestRefundTransactionRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       RefundTransactionRequest
+		expectedError bool
+	}{
+		{name: "full refund", request: RefundTransactionRequest{}, expectedError: false},
+		{name: "partial refund with reason", request: RefundTransactionRequest{Amount: money.MustParse("5.00"), Reason: "returned item"}, expectedError: false},
+		{name: "negative amount", request: RefundTransactionRequest{Amount: money.MustParse("-5.00")}, expectedError: true},
+		{name: "reason too long", request: RefundTransactionRequest{Reason: strings.Repeat("a", 256)}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestReloadGiftCardRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       ReloadGiftCardRequest
+		expectedError bool
+	}{
+		{name: "valid reload", request: ReloadGiftCardRequest{Amount: money.MustParse("20.00"), Currency: "EUR"}, expectedError: false},
+		{name: "missing amount", request: ReloadGiftCardRequest{}, expectedError: true},
+		{name: "invalid currency", request: ReloadGiftCardRequest{Amount: money.MustParse("20.00"), Currency: "EURO"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestChangeGiftCardStatusRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       ChangeGiftCardStatusRequest
+		expectedError bool
+	}{
+		{name: "suspend", request: ChangeGiftCardStatusRequest{Status: "suspended", Reason: "fraud review"}, expectedError: false},
+		{name: "cancel", request: ChangeGiftCardStatusRequest{Status: "cancelled"}, expectedError: false},
+		{name: "system status", request: ChangeGiftCardStatusRequest{Status: "used"}, expectedError: true},
+		{name: "missing status", request: ChangeGiftCardStatusRequest{}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestActivateGiftCardRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       ActivateGiftCardRequest
+		expectedError bool
+	}{
+		{name: "activation with load", request: ActivateGiftCardRequest{Amount: money.MustParse("50.00"), ActivatedBy: "register-12"}, expectedError: false},
+		{name: "activation of a preloaded card", request: ActivateGiftCardRequest{ActivatedBy: "register-12"}, expectedError: false},
+		{name: "missing operator", request: ActivateGiftCardRequest{Amount: money.MustParse("50.00")}, expectedError: true},
+		{name: "negative load", request: ActivateGiftCardRequest{Amount: money.MustParse("-1.00"), ActivatedBy: "register-12"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestResetGiftCardPinRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       ResetGiftCardPinRequest
+		expectedError bool
+	}{
+		{name: "reset", request: ResetGiftCardPinRequest{ResetBy: "admin-1"}, expectedError: false},
+		{name: "reissue", request: ResetGiftCardPinRequest{Reissue: true, ResetBy: "admin-1"}, expectedError: false},
+		{name: "missing admin", request: ResetGiftCardPinRequest{Reissue: true}, expectedError: true},
+		{name: "admin too long", request: ResetGiftCardPinRequest{ResetBy: strings.Repeat("a", 256)}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestIssueGiftCardsRequest_Validation(t *testing.T) {
+	valid := IssueGiftCardsRequest{
+		Count:          10000,
+		Denomination:   money.MustParse("25.00"),
+		Currency:       "USD",
+		Type:           "physical",
+		ExpirationDate: "2030-12-31",
+		InventoryID:    3,
+	}
+	tests := []struct {
+		name          string
+		mutate        func(*IssueGiftCardsRequest)
+		expectedError bool
+	}{
+		{name: "valid order", mutate: func(r *IssueGiftCardsRequest) {}, expectedError: false},
+		{name: "missing count", mutate: func(r *IssueGiftCardsRequest) { r.Count = 0 }, expectedError: true},
+		{name: "count too large", mutate: func(r *IssueGiftCardsRequest) { r.Count = 100001 }, expectedError: true},
+		{name: "zero denomination", mutate: func(r *IssueGiftCardsRequest) { r.Denomination = 0 }, expectedError: true},
+		{name: "malformed expiration date", mutate: func(r *IssueGiftCardsRequest) { r.ExpirationDate = "31/12/2030" }, expectedError: true},
+		{name: "unknown currency", mutate: func(r *IssueGiftCardsRequest) { r.Currency = "XXXX" }, expectedError: true},
+		{name: "physical cards without inventory", mutate: func(r *IssueGiftCardsRequest) { r.InventoryID = 0 }, expectedError: true},
+		{name: "virtual cards without inventory", mutate: func(r *IssueGiftCardsRequest) { r.Type = "virtual"; r.InventoryID = 0 }, expectedError: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.mutate(&req)
+			err := validate.Struct(req)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestGiftCardExportFilter_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        GiftCardExportFilter
+		expectedError bool
+	}{
+		{name: "no filter", filter: GiftCardExportFilter{}, expectedError: false},
+		{name: "all filters", filter: GiftCardExportFilter{Status: "active", Type: "virtual", Currency: "EUR", CampaignID: 3, ExpiresAfter: "2030-01-01", ExpiresBefore: "2030-12-31"}, expectedError: false},
+		{name: "unknown status", filter: GiftCardExportFilter{Status: "lost"}, expectedError: true},
+		{name: "malformed expiration bound", filter: GiftCardExportFilter{ExpiresBefore: "2030-13-01"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.filter)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestExtendGiftCardExpirationRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           ExtendGiftCardExpirationRequest
+		expectedError bool
+	}{
+		{name: "valid extension", req: ExtendGiftCardExpirationRequest{ExpirationDate: "2031-01-31", ExtendedBy: "agent-7"}, expectedError: false},
+		{name: "missing operator", req: ExtendGiftCardExpirationRequest{ExpirationDate: "2031-01-31"}, expectedError: true},
+		{name: "malformed date", req: ExtendGiftCardExpirationRequest{ExpirationDate: "31-01-2031", ExtendedBy: "agent-7"}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.req)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestBalanceInquiryRequest_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           BalanceInquiryRequest
+		expectedError bool
+	}{
+		{name: "empty inquiry", req: BalanceInquiryRequest{}, expectedError: false},
+		{name: "with PIN and terminal", req: BalanceInquiryRequest{Pin: "123456", TerminalID: "pos-12"}, expectedError: false},
+		{name: "short PIN", req: BalanceInquiryRequest{Pin: "1234"}, expectedError: true},
+		{name: "terminal too long", req: BalanceInquiryRequest{TerminalID: strings.Repeat("t", 101)}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.req)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestSplitTenderRequest_Validation(t *testing.T) {
+	card := func(number string) SplitTenderCard {
+		return SplitTenderCard{GiftCardNumber: number}
+	}
+	tests := []struct {
+		name          string
+		req           SplitTenderRequest
+		expectedError bool
+	}{
+		{name: "valid split tender", req: SplitTenderRequest{Cards: []SplitTenderCard{card("GC99999999999902"), card("GC12345678901237")}, Amount: money.MustParse("60.00")}, expectedError: false},
+		{name: "no cards", req: SplitTenderRequest{Amount: money.MustParse("60.00")}, expectedError: true},
+		{name: "same card twice", req: SplitTenderRequest{Cards: []SplitTenderCard{card("GC12345678901237"), card("GC12345678901237")}, Amount: money.MustParse("60.00")}, expectedError: true},
+		{name: "card without number", req: SplitTenderRequest{Cards: []SplitTenderCard{card("")}, Amount: money.MustParse("60.00")}, expectedError: true},
+		{name: "zero amount", req: SplitTenderRequest{Cards: []SplitTenderCard{card("GC12345678901237")}}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.req)
+			if tt.expectedError {
+				assert.Error(t, err, "Expected validation error for test: %s", tt.name)
+			} else {
+				assert.NoError(t, err, "Expected no validation error for test: %s", tt.name)
+			}
+		})
+	}
+}
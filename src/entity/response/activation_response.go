@@ -0,0 +1,13 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type ActivateGiftCardResponse struct {
+	GiftCardNumber string       `json:"gift_card_number"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	Status         string       `json:"status"`
+	ActivationDate string       `json:"activation_date"`
+	ActivatedBy    string       `json:"activated_by"`
+	TransactionID  uint         `json:"transaction_id,omitempty"` // Ledger entry of the amount loaded at activation
+}
@@ -0,0 +1,14 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type AuthorizationResponse struct {
+	Code             string       `json:"code"`
+	GiftCardNumber   string       `json:"gift_card_number"`
+	Amount           money.Amount `json:"amount"`
+	CapturedAmount   money.Amount `json:"captured_amount"`
+	Currency         string       `json:"currency"`
+	Status           string       `json:"status"`
+	ExpiresAt        string       `json:"expires_at"`
+	AvailableBalance money.Amount `json:"available_balance"`
+}
@@ -1,5 +1,7 @@
 package response
 
+import "GiftWize/src/shared/money"
+
 type CampaignResponse struct {
 	ID                 uint   `json:"id"`
 	Uuid               string `json:"uuid"`
@@ -8,6 +10,46 @@ type CampaignResponse struct {
 	StartDate          string `json:"start_date"`
 	EndDate            string `json:"end_date"`
 	IsEnabled          bool   `json:"is_enabled"`
+	Status             string `json:"status"`
 	DiscountPercentage string `json:"discount_percentage"`
 	CreatedAt          string `json:"created_at"`
+	// Remaining budget and cards are omitted when not limited
+	Budget              money.Amount  `json:"budget"`
+	BudgetConsumed      money.Amount  `json:"budget_consumed"`
+	BudgetRemaining     *money.Amount `json:"budget_remaining,omitempty"`
+	MaxCards            int           `json:"max_cards"`
+	CardsIssued         int           `json:"cards_issued"`
+	CardsRemaining      *int          `json:"cards_remaining,omitempty"`
+	MaxCardsPerCustomer int           `json:"max_cards_per_customer"`
+}
+
+// CampaignStatsResponse reports how the cards of a campaign performed. Money
+// totals are per currency, as a campaign can sell cards in several.
+type CampaignStatsResponse struct {
+	CampaignID         uint                       `json:"campaign_id"`
+	CardsIssued        int64                      `json:"cards_issued"`
+	CardsActivated     int64                      `json:"cards_activated"`
+	CardsFullyRedeemed int64                      `json:"cards_fully_redeemed"`
+	CardsExpired       int64                      `json:"cards_expired"`
+	Totals             []CampaignCurrencyTotals   `json:"totals"`
+	Interval           string                     `json:"interval"`
+	Redemptions        []CampaignRedemptionPeriod `json:"redemptions"`
+}
+
+type CampaignCurrencyTotals struct {
+	Currency  string       `json:"currency"`
+	Cards     int64        `json:"cards"`
+	FaceValue money.Amount `json:"face_value"`
+	// Redeemed is net of refunds
+	Redeemed money.Amount `json:"redeemed"`
+	// Breakage is the value forfeited when cards expired unredeemed
+	Breakage    money.Amount `json:"breakage"`
+	Outstanding money.Amount `json:"outstanding"`
+}
+
+type CampaignRedemptionPeriod struct {
+	Period      string       `json:"period"` // First day of the period
+	Currency    string       `json:"currency"`
+	Redemptions int64        `json:"redemptions"`
+	Amount      money.Amount `json:"amount"`
 }
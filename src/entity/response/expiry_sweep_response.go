@@ -0,0 +1,14 @@
+package response
+
+type ExpirySweepStatusResponse struct {
+	IntervalMinutes int                     `json:"interval_minutes"`
+	LastRun         *ExpirySweepRunResponse `json:"last_run"` // Null until the sweeper first ran
+}
+
+type ExpirySweepRunResponse struct {
+	StartedAt    string `json:"started_at"`
+	FinishedAt   string `json:"finished_at"`
+	ExpiredCount int    `json:"expired_count"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
@@ -1,18 +1,76 @@
 package response
 
+import "GiftWize/src/shared/money"
+
 type GetAllGiftCardResponse struct {
-	ID             uint    `json:"id"`
-	GiftCardNumber string  `json:"gift_card_number"`
-	Type           string  `json:"type"`
-	Balance        float64 `json:"balance"`
-	ExpirationDate string  `json:"expiration_date"`
-	Status         string  `json:"status"`
-	IsPromotional  bool    `json:"is_promotional"`
+	ID             uint         `json:"id"`
+	GiftCardNumber string       `json:"gift_card_number"`
+	Type           string       `json:"type"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	ExpirationDate string       `json:"expiration_date"`
+	Status         string       `json:"status"`
+	IsPromotional  bool         `json:"is_promotional"`
+}
+
+type CreateGiftCardResponse struct {
+	Code           string       `json:"code"`
+	GiftCardNumber string       `json:"gift_card_number"`
+	Pin            string       `json:"pin"` // Returned only once, the PIN is stored hashed
+	OrderID        uint         `json:"order_id"`
+	FaceValue      money.Amount `json:"face_value"`
+	PricePaid      money.Amount `json:"price_paid"` // Face value minus the campaign discount
+}
+
+type GiftCardBalanceResponse struct {
+	GiftCardNumber string       `json:"gift_card_number"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	Status         string       `json:"status"`
+}
+
+// SplitTenderResponse lists what each card paid towards Amount. Remaining,
+// in Currency, is left to charge to another tender.
+type SplitTenderResponse struct {
+	Amount    money.Amount      `json:"amount"`
+	Currency  string            `json:"currency"`
+	Remaining money.Amount      `json:"remaining"`
+	Draws     []SplitTenderDraw `json:"draws"`
+}
+
+// SplitTenderDraw is the redemption of one card of a split tender, in the
+// card currency.
+type SplitTenderDraw struct {
+	GiftCardNumber string       `json:"gift_card_number"`
+	Amount         money.Amount `json:"amount"`
+	Currency       string       `json:"currency"`
+	Balance        money.Amount `json:"balance"`
+	TransactionID  uint         `json:"transaction_id"`
+}
+
+// BalanceInquiryResponse is the compact answer to a point of sale balance
+// inquiry. Available is the balance minus the amount held by pending
+// authorizations.
+type BalanceInquiryResponse struct {
+	GiftCardNumber string       `json:"gift_card_number"`
+	Available      money.Amount `json:"available"`
+	Held           money.Amount `json:"held"`
+	Currency       string       `json:"currency"`
+	Status         string       `json:"status"`
+	ExpirationDate string       `json:"expiration_date"`
+}
+
+type GiftCardExpirationResponse struct {
+	GiftCardNumber         string `json:"gift_card_number"`
+	ExpirationDate         string `json:"expiration_date"`
+	OriginalExpirationDate string `json:"original_expiration_date"` // Expiration date the card was issued with
 }
 
 type UseGiftCardAmountResponse struct {
-	GiftCardNumber string  `json:"gift_card_number"`
-	Balance        float64 `json:"balance"`
-	IsUsed         bool    `json:"is_used"` // Indicates if the amount was successfully used
-	Message        string  `json:"message,omitempty"` // Optional message, e.g., for errors or status
+	GiftCardNumber string       `json:"gift_card_number"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	IsUsed         bool         `json:"is_used"`                  // Indicates if the amount was successfully used
+	TransactionID  uint         `json:"transaction_id,omitempty"` // Ledger entry of the redemption
+	Message        string       `json:"message,omitempty"`        // Optional message, e.g., for errors or status
 }
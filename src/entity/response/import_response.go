@@ -0,0 +1,20 @@
+package response
+
+// GiftCardImportResponse reports the outcome of a CSV import. Valid counts
+// the rows that passed every check; in commit mode Imported counts the cards
+// created.
+type GiftCardImportResponse struct {
+	DryRun   bool                     `json:"dry_run"`
+	Rows     int                      `json:"rows"`
+	Valid    int                      `json:"valid"`
+	Imported int                      `json:"imported"`
+	Failed   int                      `json:"failed"`
+	Errors   []GiftCardImportRowError `json:"errors"`
+}
+
+// GiftCardImportRowError lists the problems of one rejected row, keyed by field.
+type GiftCardImportRowError struct {
+	Line           int               `json:"line"` // Line of the row in the CSV file, the header being line 1
+	GiftCardNumber string            `json:"gift_card_number,omitempty"`
+	Errors         map[string]string `json:"errors"`
+}
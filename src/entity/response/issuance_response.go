@@ -0,0 +1,18 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type IssuanceJobResponse struct {
+	Code         string       `json:"code"`
+	Status       string       `json:"status"`
+	Count        int          `json:"count"`
+	IssuedCount  int          `json:"issued_count"`
+	Progress     float64      `json:"progress"` // Percentage of the cards issued so far
+	Denomination money.Amount `json:"denomination"`
+	UnitPrice    money.Amount `json:"unit_price"` // Denomination minus the campaign discount
+	Currency     string       `json:"currency"`
+	Error        string       `json:"error,omitempty"`
+	CreatedAt    string       `json:"created_at"`
+	CompletedAt  string       `json:"completed_at,omitempty"`
+	ExportedAt   string       `json:"exported_at,omitempty"` // Set once the cards and their PINs were exported
+}
@@ -0,0 +1,7 @@
+package response
+
+type GiftCardPinResponse struct {
+	GiftCardNumber string `json:"gift_card_number"`
+	// Pin is set only when a new PIN was issued
+	Pin string `json:"pin,omitempty"`
+}
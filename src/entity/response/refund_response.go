@@ -0,0 +1,14 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type RefundResponse struct {
+	TransactionID         uint         `json:"transaction_id"`
+	RefundedTransactionID uint         `json:"refunded_transaction_id"`
+	GiftCardNumber        string       `json:"gift_card_number"`
+	Amount                money.Amount `json:"amount"`
+	Balance               money.Amount `json:"balance"`
+	Currency              string       `json:"currency"`
+	Status                string       `json:"status"`
+	RefundableAmount      money.Amount `json:"refundable_amount"`
+}
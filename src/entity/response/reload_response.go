@@ -0,0 +1,12 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type ReloadGiftCardResponse struct {
+	GiftCardNumber string       `json:"gift_card_number"`
+	TransactionID  uint         `json:"transaction_id"`
+	Amount         money.Amount `json:"amount"`
+	Balance        money.Amount `json:"balance"`
+	Currency       string       `json:"currency"`
+	Status         string       `json:"status"`
+}
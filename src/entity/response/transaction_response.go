@@ -0,0 +1,20 @@
+package response
+
+import "GiftWize/src/shared/money"
+
+type TransactionResponse struct {
+	ID           uint         `json:"id"`
+	Type         string       `json:"type"`
+	Amount       money.Amount `json:"amount"`
+	BalanceAfter money.Amount `json:"balance_after"`
+	Currency     string       `json:"currency"`
+	Reference    string       `json:"reference"`
+	CreatedAt    string       `json:"created_at"`
+}
+
+type TransactionListResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Page         int                   `json:"page"`
+	PageSize     int                   `json:"page_size"`
+	Total        int64                 `json:"total"`
+}
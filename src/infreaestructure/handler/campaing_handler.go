@@ -94,7 +94,7 @@ func (h *CampaignHandler) UpdateCampaign(ctx *fiber.Ctx) error {
 	err = h.useCase.UpdateCampaign(ctx.Context(), id, &body)
 	if err != nil {
 		log.Errorf("Error updating campaign: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	log.Info("Campaign updated successfully")
@@ -114,7 +114,7 @@ func (h *CampaignHandler) DeleteCampaign(ctx *fiber.Ctx) error {
 	err = h.useCase.DeleteCampaign(ctx.Context(), id)
 	if err != nil {
 		log.Errorf("Error deleting campaign: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	log.Info("Campaign deleted successfully")
@@ -138,8 +138,19 @@ func (h *CampaignHandler) SearchCampaign(ctx *fiber.Ctx) error {
 	log := logrus.WithContext(ctx.Context())
 	log.Info("SearchCampaign handler")
 
-	param := ctx.Query("param")
-	campaigns, err := h.useCase.SearchCampaign(ctx.Context(), param)
+	var query request.SearchRequest
+	if err := ctx.QueryParser(&query); err != nil {
+		log.Errorf("Error parsing query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse query"})
+	}
+
+	// Validate the query parameters
+	if validationErrors := shared.ValidateStruct(query); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	campaigns, err := h.useCase.SearchCampaign(ctx.Context(), query.Query)
 	if err != nil {
 		log.Errorf("Error searching campaigns: %v", err)
 		return ctx.SendStatus(fiber.StatusInternalServerError)
@@ -147,3 +158,34 @@ func (h *CampaignHandler) SearchCampaign(ctx *fiber.Ctx) error {
 
 	return ctx.JSON(campaigns)
 }
+
+func (h *CampaignHandler) GetCampaignStats(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("GetCampaignStats handler")
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		log.Errorf("Error parsing id: %v", err)
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var query request.CampaignStatsRequest
+	if err := ctx.QueryParser(&query); err != nil {
+		log.Errorf("Error parsing query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse query"})
+	}
+
+	// Validate the query parameters
+	if validationErrors := shared.ValidateStruct(query); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	stats, err := h.useCase.GetCampaignStats(ctx.Context(), id, query.Interval)
+	if err != nil {
+		log.Errorf("Error getting campaign stats: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(stats)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"GiftWize/src/app"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorMapping associates a domain error with the HTTP status and the
+// machine-readable code returned to clients.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{app.ErrCampaignNotFound, fiber.StatusNotFound, "CAMPAIGN_NOT_FOUND"},
+	{app.ErrCampaignExhausted, fiber.StatusConflict, "CAMPAIGN_EXHAUSTED"},
+	{app.ErrCampaignCustomerCap, fiber.StatusConflict, "CAMPAIGN_CUSTOMER_CAP_REACHED"},
+	{app.ErrCampaignClosed, fiber.StatusConflict, "CAMPAIGN_CLOSED"},
+	{app.ErrGiftCardNotFound, fiber.StatusNotFound, "GIFT_CARD_NOT_FOUND"},
+	{app.ErrInvalidGiftCardNumber, fiber.StatusBadRequest, "INVALID_GIFT_CARD_NUMBER"},
+	{app.ErrGiftCardNotActive, fiber.StatusConflict, "GIFT_CARD_NOT_ACTIVE"},
+	{app.ErrGiftCardExpired, fiber.StatusGone, "GIFT_CARD_EXPIRED"},
+	{app.ErrMaxUsesReached, fiber.StatusUnprocessableEntity, "MAX_USES_REACHED"},
+	{app.ErrInsufficientBalance, fiber.StatusUnprocessableEntity, "INSUFFICIENT_BALANCE"},
+	{app.ErrCurrencyMismatch, fiber.StatusUnprocessableEntity, "CURRENCY_MISMATCH"},
+	{app.ErrInvalidStatusTransition, fiber.StatusConflict, "INVALID_STATUS_TRANSITION"},
+	{app.ErrGiftCardNotDistributed, fiber.StatusConflict, "GIFT_CARD_NOT_DISTRIBUTED"},
+	{app.ErrInventoryNotFound, fiber.StatusNotFound, "INVENTORY_NOT_FOUND"},
+	{app.ErrAuthorizationNotFound, fiber.StatusNotFound, "AUTHORIZATION_NOT_FOUND"},
+	{app.ErrAuthorizationNotPending, fiber.StatusConflict, "AUTHORIZATION_NOT_PENDING"},
+	{app.ErrAuthorizationExpired, fiber.StatusGone, "AUTHORIZATION_EXPIRED"},
+	{app.ErrCaptureExceedsAuthorization, fiber.StatusUnprocessableEntity, "CAPTURE_EXCEEDS_AUTHORIZATION"},
+	{app.ErrTransactionNotFound, fiber.StatusNotFound, "TRANSACTION_NOT_FOUND"},
+	{app.ErrTransactionNotRefundable, fiber.StatusUnprocessableEntity, "TRANSACTION_NOT_REFUNDABLE"},
+	{app.ErrRefundExceedsRedemption, fiber.StatusUnprocessableEntity, "REFUND_EXCEEDS_REDEMPTION"},
+	{app.ErrGiftCardNotReloadable, fiber.StatusUnprocessableEntity, "GIFT_CARD_NOT_RELOADABLE"},
+	{app.ErrMaxBalanceExceeded, fiber.StatusUnprocessableEntity, "MAX_BALANCE_EXCEEDED"},
+	{app.ErrReloadLimitExceeded, fiber.StatusUnprocessableEntity, "RELOAD_LIMIT_EXCEEDED"},
+	{app.ErrIssuanceJobNotFound, fiber.StatusNotFound, "ISSUANCE_JOB_NOT_FOUND"},
+	{app.ErrIssuanceJobNotCompleted, fiber.StatusConflict, "ISSUANCE_JOB_NOT_COMPLETED"},
+	{app.ErrIssuanceJobExported, fiber.StatusGone, "ISSUANCE_JOB_ALREADY_EXPORTED"},
+	{app.ErrIssuanceJobPinsExpired, fiber.StatusGone, "ISSUANCE_JOB_PINS_EXPIRED"},
+	{app.ErrInvalidImportFile, fiber.StatusBadRequest, "INVALID_IMPORT_FILE"},
+	{app.ErrExpirationNotExtended, fiber.StatusUnprocessableEntity, "EXPIRATION_NOT_EXTENDED"},
+	{app.ErrExtensionLimitExceeded, fiber.StatusUnprocessableEntity, "EXTENSION_LIMIT_EXCEEDED"},
+	{app.ErrInvalidPin, fiber.StatusUnauthorized, "INVALID_PIN"},
+	{app.ErrPinLocked, fiber.StatusLocked, "PIN_LOCKED"},
+	{app.ErrPinResetRequired, fiber.StatusForbidden, "PIN_RESET_REQUIRED"},
+}
+
+// errorResponse writes the status and error code matching err.
+// Errors without a mapping are answered with a 500.
+func errorResponse(ctx *fiber.Ctx, err error) error {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return ctx.Status(m.status).JSON(fiber.Map{"code": m.code, "error": m.err.Error()})
+		}
+	}
+	return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"code": "INTERNAL_ERROR", "error": "internal server error"})
+}
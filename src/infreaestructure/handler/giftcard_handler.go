@@ -4,6 +4,10 @@ import (
 	"GiftWize/src/app/usecase"
 	"GiftWize/src/entity/request"
 	"GiftWize/src/shared"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -35,14 +39,14 @@ func (g *GiftCardHandler) CreateGiftCard(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
 	}
 
-	err := g.giftCardUseCase.CreateGiftCard(ctx.Context(), body)
+	result, err := g.giftCardUseCase.CreateGiftCard(ctx.Context(), body)
 	if err != nil {
 		log.Errorf("Error creating gift card: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	log.Info("Gift card created successfully")
-	return ctx.SendStatus(fiber.StatusCreated)
+	return ctx.Status(fiber.StatusCreated).JSON(result)
 }
 
 func (g *GiftCardHandler) DeleteGiftCard(ctx *fiber.Ctx) error {
@@ -58,7 +62,7 @@ func (g *GiftCardHandler) DeleteGiftCard(ctx *fiber.Ctx) error {
 	err := g.giftCardUseCase.DeleteGiftCard(ctx.Context(), id)
 	if err != nil {
 		log.Errorf("Error deleting gift card: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	log.Info("Gift card deleted successfully")
@@ -90,7 +94,7 @@ func (g *GiftCardHandler) UpdateGiftCard(ctx *fiber.Ctx) error {
 	err := g.giftCardUseCase.UpdateGiftCard(ctx.Context(), id, body)
 	if err != nil {
 		log.Errorf("Error updating gift card: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	log.Info("Gift card updated successfully")
@@ -104,7 +108,7 @@ func (g *GiftCardHandler) GetAllGiftCards(ctx *fiber.Ctx) error {
 	list, err := g.giftCardUseCase.GetAllGiftCardList(ctx.Context())
 	if err != nil {
 		log.Errorf("Error getting gift card list: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	return ctx.JSON(list)
@@ -123,7 +127,7 @@ func (g *GiftCardHandler) GetGiftCardByID(ctx *fiber.Ctx) error {
 	card, err := g.giftCardUseCase.GetGiftCardByID(ctx.Context(), id)
 	if err != nil {
 		log.Errorf("Error getting gift card by ID: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	return ctx.JSON(card)
@@ -133,12 +137,614 @@ func (g *GiftCardHandler) FullTextSearchGiftCard(ctx *fiber.Ctx) error {
 	log := logrus.WithContext(ctx.Context())
 	log.Info("FullTextSearchGiftCard usecase")
 
-	query := ctx.Query("query")
-	results, err := g.giftCardUseCase.FullTextSearchGiftCard(ctx.Context(), query)
+	var query request.SearchRequest
+	if err := ctx.QueryParser(&query); err != nil {
+		log.Errorf("Error parsing query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse query"})
+	}
+
+	// Validate the query parameters
+	if validationErrors := shared.ValidateStruct(query); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	results, err := g.giftCardUseCase.FullTextSearchGiftCard(ctx.Context(), query.Query)
 	if err != nil {
 		log.Errorf("Error full text searching gift card: %v", err)
-		return ctx.SendStatus(fiber.StatusInternalServerError)
+		return errorResponse(ctx, err)
 	}
 
 	return ctx.JSON(results)
 }
+
+func (g *GiftCardHandler) RedeemGiftCard(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("RedeemGiftCard usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.UseGiftCardAmountRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.UseGiftCardAmount(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error redeeming gift card: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card redeemed successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) RedeemSplitTender(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("RedeemSplitTender usecase")
+
+	var body request.SplitTenderRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.RedeemSplitTender(ctx.Context(), body)
+	if err != nil {
+		log.Errorf("Error redeeming split tender: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Split tender redeemed successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) GetGiftCardTransactions(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("GetGiftCardTransactions usecase")
+
+	code := ctx.Params("code")
+	if code == "" {
+		log.Error("Gift card code is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	page := ctx.QueryInt("page", 1)
+	pageSize := ctx.QueryInt("page_size", 20)
+	if page < 1 || pageSize < 1 || pageSize > 100 {
+		log.Errorf("Invalid pagination: page=%d page_size=%d", page, pageSize)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "page must be >= 1 and page_size between 1 and 100"})
+	}
+
+	transactions, err := g.giftCardUseCase.GetGiftCardTransactions(ctx.Context(), code, page, pageSize)
+	if err != nil {
+		log.Errorf("Error getting gift card transactions: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(transactions)
+}
+
+func (g *GiftCardHandler) AuthorizeGiftCard(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("AuthorizeGiftCard usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.AuthorizeGiftCardRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.AuthorizeGiftCardAmount(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error authorizing gift card: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card authorized successfully")
+	return ctx.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (g *GiftCardHandler) CaptureAuthorization(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("CaptureAuthorization usecase")
+
+	code := ctx.Params("code")
+	if code == "" {
+		log.Error("Authorization code is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.CaptureAuthorizationRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&body); err != nil {
+			log.Errorf("Error parsing request: %v", err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+		}
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.CaptureAuthorization(ctx.Context(), code, body)
+	if err != nil {
+		log.Errorf("Error capturing authorization: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Authorization captured successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) VoidAuthorization(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("VoidAuthorization usecase")
+
+	code := ctx.Params("code")
+	if code == "" {
+		log.Error("Authorization code is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	result, err := g.giftCardUseCase.VoidAuthorization(ctx.Context(), code)
+	if err != nil {
+		log.Errorf("Error voiding authorization: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Authorization voided successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) RefundTransaction(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("RefundTransaction usecase")
+
+	id, err := ctx.ParamsInt("id")
+	if err != nil || id <= 0 {
+		log.Error("A valid transaction id is required")
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid transaction id"})
+	}
+
+	var body request.RefundTransactionRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&body); err != nil {
+			log.Errorf("Error parsing request: %v", err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+		}
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.RefundTransaction(ctx.Context(), uint(id), body)
+	if err != nil {
+		log.Errorf("Error refunding transaction: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Transaction refunded successfully")
+	return ctx.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (g *GiftCardHandler) ReloadGiftCard(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ReloadGiftCard usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.ReloadGiftCardRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.ReloadGiftCard(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error reloading gift card: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card reloaded successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ChangeGiftCardStatus(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ChangeGiftCardStatus usecase")
+
+	id := ctx.Params("id")
+	if id == "" {
+		log.Error("Gift card ID is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.ChangeGiftCardStatusRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.ChangeGiftCardStatus(ctx.Context(), id, body)
+	if err != nil {
+		log.Errorf("Error changing gift card status: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card status changed successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ActivateGiftCard(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ActivateGiftCard usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.ActivateGiftCardRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.ActivateGiftCard(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error activating gift card: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card activated successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) GetGiftCardBalance(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("GetGiftCardBalance usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.GiftCardBalanceRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.GetGiftCardBalance(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error retrieving gift card balance: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card balance retrieved successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) InquireGiftCardBalance(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("InquireGiftCardBalance usecase")
+
+	number := ctx.Params("number")
+	if number == "" {
+		log.Error("Gift card number is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// The body is optional, cards without a PIN need none
+	var body request.BalanceInquiryRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&body); err != nil {
+			log.Errorf("Error parsing request: %v", err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+		}
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.InquireGiftCardBalance(ctx.Context(), number, body)
+	if err != nil {
+		log.Errorf("Error answering balance inquiry: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Balance inquiry answered successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ResetGiftCardPin(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ResetGiftCardPin usecase")
+
+	id := ctx.Params("id")
+	if id == "" {
+		log.Error("Gift card ID is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.ResetGiftCardPinRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.ResetGiftCardPin(ctx.Context(), id, body)
+	if err != nil {
+		log.Errorf("Error resetting gift card PIN: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card PIN reset successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ExtendGiftCardExpiration(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ExtendGiftCardExpiration usecase")
+
+	id := ctx.Params("id")
+	if id == "" {
+		log.Error("Gift card ID is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var body request.ExtendGiftCardExpirationRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.ExtendGiftCardExpiration(ctx.Context(), id, body)
+	if err != nil {
+		log.Errorf("Error extending gift card expiration: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card expiration extended successfully")
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) IssueGiftCards(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("IssueGiftCards usecase")
+
+	var body request.IssueGiftCardsRequest
+	if err := ctx.BodyParser(&body); err != nil {
+		log.Errorf("Error parsing request: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse JSON"})
+	}
+
+	// Validate the request body
+	if validationErrors := shared.ValidateStruct(body); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	result, err := g.giftCardUseCase.IssueGiftCards(ctx.Context(), body)
+	if err != nil {
+		log.Errorf("Error starting gift card issuance: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Info("Gift card issuance started successfully")
+	return ctx.Status(fiber.StatusAccepted).JSON(result)
+}
+
+func (g *GiftCardHandler) GetIssuanceJob(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("GetIssuanceJob usecase")
+
+	code := ctx.Params("code")
+	if code == "" {
+		log.Error("Issuance job code is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	result, err := g.giftCardUseCase.GetIssuanceJob(ctx.Context(), code)
+	if err != nil {
+		log.Errorf("Error getting issuance job: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ExportIssuanceJobCSV(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ExportIssuanceJobCSV usecase")
+
+	code := ctx.Params("code")
+	if code == "" {
+		log.Error("Issuance job code is required")
+		return ctx.SendStatus(fiber.StatusBadRequest)
+	}
+
+	// Buffered so that a failure midway still gets an error response
+	var buf bytes.Buffer
+	if err := g.giftCardUseCase.ExportIssuanceJobCSV(ctx.Context(), code, &buf); err != nil {
+		log.Errorf("Error exporting issuance job: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	ctx.Attachment(fmt.Sprintf("giftcards-%s.csv", code))
+	return ctx.Send(buf.Bytes())
+}
+
+func (g *GiftCardHandler) ImportGiftCards(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ImportGiftCards usecase")
+
+	var query request.ImportGiftCardsRequest
+	if err := ctx.QueryParser(&query); err != nil {
+		log.Errorf("Error parsing query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse query"})
+	}
+
+	// Validate the query parameters
+	if validationErrors := shared.ValidateStruct(query); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	// The CSV file is the raw request body, read as it arrives
+	body := ctx.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.Body())
+	}
+
+	result, err := g.giftCardUseCase.ImportGiftCardsCSV(ctx.Context(), body, query.Mode != "commit")
+	if err != nil {
+		log.Errorf("Error importing gift cards: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	log.Infof("Gift card import finished: %d imported, %d failed", result.Imported, result.Failed)
+	return ctx.JSON(result)
+}
+
+func (g *GiftCardHandler) ExportGiftCards(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("ExportGiftCards usecase")
+
+	var filter request.GiftCardExportFilter
+	if err := ctx.QueryParser(&filter); err != nil {
+		log.Errorf("Error parsing query: %v", err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse query"})
+	}
+
+	// Validate the query parameters
+	if validationErrors := shared.ValidateStruct(filter); len(validationErrors) > 0 {
+		log.Error("Validation errors:", validationErrors)
+		return ctx.Status(fiber.StatusBadRequest).JSON(shared.FormatValidationErrors(validationErrors))
+	}
+
+	ctx.Attachment("giftcards.csv")
+	// The body is written after the handler returns, while the request
+	// context is still valid. A failed write, e.g. to a client that
+	// disconnected, cancels the export; a failure midway truncates the file
+	requestCtx := ctx.Context()
+	requestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		exportCtx, cancel := context.WithCancel(requestCtx)
+		defer cancel()
+		if err := g.giftCardUseCase.ExportGiftCardsCSV(exportCtx, filter, &streamWriter{w: w, cancel: cancel}); err != nil {
+			log.Errorf("Error exporting gift cards: %v", err)
+		}
+	})
+	return nil
+}
+
+// streamWriter sends each write of a streamed body to the client right away,
+// and cancels the request work once the client can no longer be written to.
+type streamWriter struct {
+	w      *bufio.Writer
+	cancel context.CancelFunc
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	if err == nil {
+		err = s.w.Flush()
+	}
+	if err != nil {
+		s.cancel()
+	}
+	return n, err
+}
+
+func (g *GiftCardHandler) GetExpirySweepStatus(ctx *fiber.Ctx) error {
+	log := logrus.WithContext(ctx.Context())
+	log.Info("GetExpirySweepStatus usecase")
+
+	status, err := g.giftCardUseCase.GetExpirySweepStatus(ctx.Context())
+	if err != nil {
+		log.Errorf("Error getting expiry sweep status: %v", err)
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(status)
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/infreaestructure/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyPurgeBatchSize is how many expired keys are deleted per
+	// statement.
+	idempotencyPurgeBatchSize = 1000
+)
+
+// Idempotency makes a write endpoint safe to retry. The first response for an
+// Idempotency-Key header is stored for ttl; a replay with the same method,
+// path and body returns that response, and a replay with a different request
+// is rejected with 409. Requests without the header are passed through.
+// Server errors are not stored, so the client can retry with the same key.
+func Idempotency(repo repository.IIdempotencyRepository, ttl time.Duration) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		log := logrus.WithContext(ctx.Context())
+		if len(key) > maxIdempotencyKeyLength {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"code": "IDEMPOTENCY_KEY_INVALID", "error": "idempotency key is too long"})
+		}
+
+		hash := requestHash(ctx)
+		now := time.Now()
+		if err := repo.DeleteExpiredIdempotencyKey(ctx.Context(), key, now); err != nil {
+			log.Errorf("Error purging expired idempotency key: %v", err)
+			return ctx.SendStatus(fiber.StatusInternalServerError)
+		}
+
+		created, err := repo.CreateIdempotencyKey(ctx.Context(), &models.IdempotencyKey{
+			Key:         key,
+			RequestHash: hash,
+			ExpiresAt:   now.Add(ttl),
+		})
+		if err != nil {
+			log.Errorf("Error reserving idempotency key: %v", err)
+			return ctx.SendStatus(fiber.StatusInternalServerError)
+		}
+		if !created {
+			return replay(ctx, repo, key, hash)
+		}
+
+		if err := ctx.Next(); err != nil {
+			release(ctx, repo, key)
+			return err
+		}
+
+		status := ctx.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			release(ctx, repo, key)
+			return nil
+		}
+
+		body := append([]byte(nil), ctx.Response().Body()...)
+		contentType := string(ctx.Response().Header.ContentType())
+		if err := repo.CompleteIdempotencyKey(ctx.Context(), key, status, contentType, body); err != nil {
+			log.Errorf("Error storing idempotent response: %v", err)
+		}
+		return nil
+	}
+}
+
+// StartIdempotencyPurge deletes expired idempotency keys in the background,
+// once right away and then every interval, until ctx is cancelled. Idempotency
+// only removes an expired key when it is reused, which most never are.
+func StartIdempotencyPurge(ctx context.Context, repo repository.IIdempotencyRepository, interval time.Duration) {
+	go func() {
+		log := logrus.WithContext(ctx)
+		for {
+			if _, err := purgeExpiredIdempotencyKeys(ctx, repo, time.Now()); err != nil {
+				log.Errorf("Idempotency key purge failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+// purgeExpiredIdempotencyKeys deletes the keys expired at now in batches and
+// returns how many were deleted. Replicas purging at the same time only
+// delete fewer keys each.
+func purgeExpiredIdempotencyKeys(ctx context.Context, repo repository.IIdempotencyRepository, now time.Time) (int64, error) {
+	var purged int64
+	for {
+		deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, now, idempotencyPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += deleted
+		if deleted < idempotencyPurgeBatchSize {
+			break
+		}
+	}
+
+	if purged > 0 {
+		logrus.WithContext(ctx).Infof("Purged %d expired idempotency keys", purged)
+	}
+	return purged, nil
+}
+
+// replay answers a request whose key was already used.
+func replay(ctx *fiber.Ctx, repo repository.IIdempotencyRepository, key string, hash string) error {
+	log := logrus.WithContext(ctx.Context())
+
+	stored, err := repo.GetIdempotencyKey(ctx.Context(), key)
+	if err != nil {
+		log.Errorf("Error reading idempotency key: %v", err)
+		return ctx.SendStatus(fiber.StatusInternalServerError)
+	}
+	if stored == nil || !stored.Completed {
+		log.Warn("Idempotency key is in use by a request in progress")
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{"code": "IDEMPOTENCY_REQUEST_IN_PROGRESS", "error": "a request with this idempotency key is in progress"})
+	}
+	if stored.RequestHash != hash {
+		log.Warn("Idempotency key reused with a different request")
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{"code": "IDEMPOTENCY_KEY_MISMATCH", "error": "idempotency key was used with a different request"})
+	}
+
+	log.Info("Replaying stored idempotent response")
+	ctx.Set(IdempotentReplayedHeader, "true")
+	if stored.ContentType != "" {
+		ctx.Set(fiber.HeaderContentType, stored.ContentType)
+	}
+	return ctx.Status(stored.StatusCode).Send(stored.ResponseBody)
+}
+
+// release frees the key after a failed request.
+func release(ctx *fiber.Ctx, repo repository.IIdempotencyRepository, key string) {
+	if err := repo.DeleteIdempotencyKey(ctx.Context(), key); err != nil {
+		logrus.WithContext(ctx.Context()).Errorf("Error releasing idempotency key: %v", err)
+	}
+}
+
+// requestHash fingerprints the method, path and body of the request.
+func requestHash(ctx *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(ctx.Path()))
+	h.Write([]byte{0})
+	h.Write(ctx.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
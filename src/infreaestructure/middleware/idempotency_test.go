@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"GiftWize/src/entity/models"
+	"GiftWize/src/infreaestructure/repository"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyRepository is an in-memory IIdempotencyRepository.
+type memoryIdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[string]models.IdempotencyKey
+}
+
+var _ repository.IIdempotencyRepository = (*memoryIdempotencyRepository)(nil)
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{keys: map[string]models.IdempotencyKey{}}
+}
+
+func (m *memoryIdempotencyRepository) CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[key.Key]; ok {
+		return false, nil
+	}
+	m.keys[key.Key] = *key
+	return true, nil
+}
+
+func (m *memoryIdempotencyRepository) GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.keys[key]
+	if !ok {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+func (m *memoryIdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.keys[key]
+	stored.Completed = true
+	stored.StatusCode = statusCode
+	stored.ContentType = contentType
+	stored.ResponseBody = body
+	m.keys[key] = stored
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) DeleteExpiredIdempotencyKey(ctx context.Context, key string, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.keys[key]; ok && stored.ExpiresAt.Before(now) {
+		delete(m.keys, key)
+	}
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for key, stored := range m.keys {
+		if int(deleted) == limit {
+			break
+		}
+		if stored.ExpiresAt.Before(now) {
+			delete(m.keys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newIdempotentApp(repo *memoryIdempotencyRepository, ttl time.Duration, status *int) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New()
+	app.Post("/redeem", Idempotency(repo, ttl), func(ctx *fiber.Ctx) error {
+		calls++
+		return ctx.Status(*status).JSON(fiber.Map{"call": calls})
+	})
+	return app, &calls
+}
+
+func send(t *testing.T, app *fiber.App, key string, body string) (int, string, string) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/redeem", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	payload, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(payload), resp.Header.Get(IdempotentReplayedHeader)
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replays the stored response for the same request", func(t *testing.T) {
+		status := fiber.StatusOK
+		app, calls := newIdempotentApp(newMemoryIdempotencyRepository(), time.Hour, &status)
+
+		code, body, replayed := send(t, app, "key-1", `{"amount":"10.00"}`)
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.JSONEq(t, `{"call":1}`, body)
+		assert.Empty(t, replayed)
+
+		code, body, replayed = send(t, app, "key-1", `{"amount":"10.00"}`)
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.JSONEq(t, `{"call":1}`, body)
+		assert.Equal(t, "true", replayed)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("rejects a different body with the same key", func(t *testing.T) {
+		status := fiber.StatusOK
+		app, calls := newIdempotentApp(newMemoryIdempotencyRepository(), time.Hour, &status)
+
+		send(t, app, "key-1", `{"amount":"10.00"}`)
+		code, body, _ := send(t, app, "key-1", `{"amount":"20.00"}`)
+		assert.Equal(t, fiber.StatusConflict, code)
+		assert.Contains(t, body, "IDEMPOTENCY_KEY_MISMATCH")
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		status := fiber.StatusOK
+		app, calls := newIdempotentApp(newMemoryIdempotencyRepository(), time.Hour, &status)
+
+		send(t, app, "", `{"amount":"10.00"}`)
+		send(t, app, "", `{"amount":"10.00"}`)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("server errors release the key", func(t *testing.T) {
+		status := fiber.StatusInternalServerError
+		app, calls := newIdempotentApp(newMemoryIdempotencyRepository(), time.Hour, &status)
+
+		send(t, app, "key-1", `{"amount":"10.00"}`)
+		status = fiber.StatusOK
+		code, _, replayed := send(t, app, "key-1", `{"amount":"10.00"}`)
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.Empty(t, replayed)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("expired keys can be reused", func(t *testing.T) {
+		status := fiber.StatusOK
+		app, calls := newIdempotentApp(newMemoryIdempotencyRepository(), -time.Second, &status)
+
+		send(t, app, "key-1", `{"amount":"10.00"}`)
+		code, body, _ := send(t, app, "key-1", `{"amount":"20.00"}`)
+		assert.Equal(t, fiber.StatusOK, code)
+		assert.JSONEq(t, `{"call":2}`, body)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("a key held by a request in progress is rejected", func(t *testing.T) {
+		repo := newMemoryIdempotencyRepository()
+		status := fiber.StatusOK
+		app, calls := newIdempotentApp(repo, time.Hour, &status)
+		repo.keys["key-1"] = models.IdempotencyKey{Key: "key-1", ExpiresAt: time.Now().Add(time.Hour)}
+
+		code, body, _ := send(t, app, "key-1", `{"amount":"10.00"}`)
+		assert.Equal(t, fiber.StatusConflict, code)
+		assert.Contains(t, body, "IDEMPOTENCY_REQUEST_IN_PROGRESS")
+		assert.Equal(t, 0, *calls)
+	})
+}
+
+func TestPurgeExpiredIdempotencyKeys(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	now := time.Now()
+	for i := 0; i < idempotencyPurgeBatchSize+5; i++ {
+		key := fmt.Sprintf("expired-%d", i)
+		repo.keys[key] = models.IdempotencyKey{Key: key, ExpiresAt: now.Add(-time.Minute)}
+	}
+	repo.keys["live"] = models.IdempotencyKey{Key: "live", ExpiresAt: now.Add(time.Hour)}
+
+	purged, err := purgeExpiredIdempotencyKeys(context.Background(), repo, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(idempotencyPurgeBatchSize+5), purged)
+	assert.Len(t, repo.keys, 1)
+	assert.Contains(t, repo.keys, "live")
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit lets each client IP make at most max requests per window. Further
+// requests are rejected with 429 until the window ends. Counters are kept in
+// memory, so each replica limits its own traffic.
+func RateLimit(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		LimitReached: func(ctx *fiber.Ctx) error {
+			logrus.WithContext(ctx.Context()).Warnf("Rate limit reached by %s on %s", ctx.IP(), ctx.Path())
+			return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"code": "RATE_LIMITED", "error": "too many requests"})
+		},
+	})
+}
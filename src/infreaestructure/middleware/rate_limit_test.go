@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	app := fiber.New()
+	app.Get("/balance", RateLimit(2, time.Minute), func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/balance", nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/balance", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+}
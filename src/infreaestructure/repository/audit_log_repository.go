@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"GiftWize/src/entity/models"
+	"context"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// IAuditLogRepository defines the interface for recording audited actions.
+type IAuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) error
+}
+
+type AuditLogRepository struct {
+	gorm *gorm.DB
+}
+
+// NewAuditLogRepository creates a new instance of AuditLogRepository.
+func NewAuditLogRepository(gorm *gorm.DB) IAuditLogRepository {
+	return &AuditLogRepository{gorm: gorm}
+}
+
+// Ensure AuditLogRepository implements IAuditLogRepository
+var _ IAuditLogRepository = (*AuditLogRepository)(nil)
+
+// CreateAuditLog stores an audit entry. Inside a transaction the entry is
+// committed or rolled back together with the audited change.
+func (a *AuditLogRepository) CreateAuditLog(ctx context.Context, auditLog *models.AuditLog) error {
+	log.WithContext(ctx).Infof("CreateAuditLog repository for action: %s", auditLog.Action)
+
+	res := conn(ctx, a.gorm).Create(auditLog)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating audit log: %v", res.Error)
+		return res.Error
+	}
+
+	log.WithContext(ctx).Info("Audit log created successfully")
+	return nil
+}
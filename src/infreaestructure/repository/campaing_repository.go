@@ -4,12 +4,15 @@ import (
 	"GiftWize/src/entity/models"
 	"GiftWize/src/entity/request"
 	"GiftWize/src/entity/response"
+	"GiftWize/src/shared/money"
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ICampaignRepository defines the interface for campaign repository operations.
@@ -21,6 +24,14 @@ type ICampaignRepository interface {
 	FullTextSearchCampaign(ctx context.Context, data *request.FullTextSearchCampaignRequest) (*response.CampaignResponse, error)
 	SearchCampaign(ctx context.Context, query string) ([]*models.Campaign, error)
 	ListCampaigns(ctx context.Context) ([]*models.Campaign, error)
+	GetCampaignCurrencyStats(ctx context.Context, id int) ([]models.CampaignCurrencyStats, error)
+	GetCampaignRedemptions(ctx context.Context, id int, interval string) ([]models.CampaignRedemptionPeriod, error)
+	GetCampaignByIDForUpdate(ctx context.Context, id uint) (*models.Campaign, error)
+	UpdateCampaignStatus(ctx context.Context, id uint, status string, endedAt *time.Time) error
+	SetCampaignCardsExpiryPending(ctx context.Context, id uint, pending bool) error
+	ReserveCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) (bool, error)
+	ReleaseCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) error
+	CountCustomerCampaignCards(ctx context.Context, campaignID uint, customerID uint) (int64, error)
 }
 
 type CampaignRepository struct {
@@ -39,13 +50,17 @@ var _ ICampaignRepository = (*CampaignRepository)(nil)
 func (c *CampaignRepository) CreateCampaign(ctx context.Context, data request.CreateCampaignRequest, uuid string) error {
 	log.WithContext(ctx).Info("CreateCampaign repository")
 
-	res := c.gorm.WithContext(ctx).Create(&models.Campaign{
-		CampaignUUID:       uuid,
-		Name:               data.Name,
-		Description:        data.Description,
-		StartDate:          data.StartDate,
-		EndDate:            data.EndDate,
-		DiscountPercentage: data.DiscountPercentage,
+	res := conn(ctx, c.gorm).Create(&models.Campaign{
+		CampaignUUID:        uuid,
+		Name:                data.Name,
+		Description:         data.Description,
+		StartDate:           data.StartDate,
+		EndDate:             data.EndDate,
+		IsEnabled:           data.IsEnabled,
+		DiscountPercentage:  data.DiscountPercentage,
+		Budget:              data.Budget,
+		MaxCards:            data.MaxCards,
+		MaxCardsPerCustomer: data.MaxCardsPerCustomer,
 	})
 
 	if res.Error != nil {
@@ -61,7 +76,7 @@ func (c *CampaignRepository) GetCampaign(ctx context.Context, id int) (*models.C
 	log.WithContext(ctx).Info("GetCampaign repository")
 
 	var campaign models.Campaign
-	res := c.gorm.WithContext(ctx).First(&campaign, id)
+	res := conn(ctx, c.gorm).First(&campaign, id)
 
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
@@ -87,8 +102,18 @@ func (c *CampaignRepository) UpdateCampaign(ctx context.Context, id int, data *r
 		"is_enabled":          data.IsEnabled,
 		"discount_percentage": data.DiscountPercentage,
 	}
+	// Limits are only changed when sent, so clients unaware of them keep them
+	if data.Budget != nil {
+		updateData["budget"] = *data.Budget
+	}
+	if data.MaxCards != nil {
+		updateData["max_cards"] = *data.MaxCards
+	}
+	if data.MaxCardsPerCustomer != nil {
+		updateData["max_cards_per_customer"] = *data.MaxCardsPerCustomer
+	}
 
-	res := c.gorm.WithContext(ctx).Model(&models.Campaign{}).Where("id = ?", id).Updates(updateData)
+	res := conn(ctx, c.gorm).Model(&models.Campaign{}).Where("id = ?", id).Updates(updateData)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error updating campaign: %v", res.Error)
 		return res.Error
@@ -107,7 +132,7 @@ func (c *CampaignRepository) FullTextSearchCampaign(ctx context.Context, data *r
 	log.WithContext(ctx).Info("FullTextSearchCampaign repository")
 
 	var campaign models.Campaign
-	res := c.gorm.WithContext(ctx).Where("id = ? OR name = ? OR description = ? OR start_date = ? OR end_date = ?",
+	res := conn(ctx, c.gorm).Where("id = ? OR name = ? OR description = ? OR start_date = ? OR end_date = ?",
 		data.ID, data.Name, data.Description, data.StartDate, data.EndDate).First(&campaign)
 
 	if res.Error != nil {
@@ -137,7 +162,7 @@ func (c *CampaignRepository) FullTextSearchCampaign(ctx context.Context, data *r
 func (c *CampaignRepository) DeleteCampaign(ctx context.Context, id int) error {
 	log.WithContext(ctx).Info("DeleteCampaign repository")
 
-	res := c.gorm.WithContext(ctx).Delete(&models.Campaign{}, id)
+	res := conn(ctx, c.gorm).Delete(&models.Campaign{}, id)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error deleting campaign: %v", res.Error)
 		return res.Error
@@ -147,11 +172,17 @@ func (c *CampaignRepository) DeleteCampaign(ctx context.Context, id int) error {
 	return nil
 }
 
+// SearchCampaign full-text searches the name and description of campaigns,
+// best matches first. query is read as in web search engines: words are
+// all required, "quoted phrases" match as a whole and -word excludes.
 func (c *CampaignRepository) SearchCampaign(ctx context.Context, query string) ([]*models.Campaign, error) {
 	log.WithContext(ctx).Info("SearchCampaign repository")
 
+	tsQuery := clause.Expr{SQL: "websearch_to_tsquery(?, ?)", Vars: []interface{}{searchConfig, query}}
 	var campaigns []*models.Campaign
-	res := c.gorm.WithContext(ctx).Where("MATCH(name, description, start_date, end_date, discount_percentage) AGAINST (?)", query).Find(&campaigns)
+	res := conn(ctx, c.gorm).Where("search_vector @@ ?", tsQuery).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "ts_rank(search_vector, ?) DESC, id", Vars: []interface{}{tsQuery}}}).
+		Limit(searchResultLimit).Find(&campaigns)
 
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error searching campaign: %v", res.Error)
@@ -171,7 +202,7 @@ func (c *CampaignRepository) ListCampaigns(ctx context.Context) ([]*models.Campa
 	log.WithContext(ctx).Info("ListCampaigns repository")
 
 	var campaigns []*models.Campaign
-	res := c.gorm.WithContext(ctx).Find(&campaigns)
+	res := conn(ctx, c.gorm).Order("id").Find(&campaigns)
 
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error listing campaigns: %v", res.Error)
@@ -186,3 +217,187 @@ func (c *CampaignRepository) ListCampaigns(ctx context.Context) ([]*models.Campa
 	log.WithContext(ctx).Info("Campaigns retrieved successfully")
 	return campaigns, nil
 }
+
+// campaignCurrencyStatsQuery totals the ledger of each card of a campaign,
+// then aggregates the cards per currency. Cards are found through the
+// campaign_id index and their entries through the (gift_card_id,
+// transaction_type) index, so the cost grows with the campaign, not with
+// the ledger.
+const campaignCurrencyStatsQuery = `
+WITH card_totals AS (
+	SELECT g.id, g.currency, g.status, g.type, g.activation_date, g.balance,
+		COALESCE(SUM(t.amount) FILTER (WHERE t.transaction_type IN @loads), 0) AS face_value,
+		COALESCE(-SUM(t.amount) FILTER (WHERE t.transaction_type IN @redemptions), 0) AS redeemed,
+		COALESCE(-SUM(t.amount) FILTER (WHERE t.transaction_type = @forfeit), 0) AS forfeited
+	FROM gift_cards g
+	LEFT JOIN transactions t ON t.gift_card_id = g.id AND t.transaction_type IN @types
+	WHERE g.campaign_id = @campaign
+	GROUP BY g.id
+)
+SELECT currency,
+	COUNT(*) AS cards,
+	COUNT(*) FILTER (WHERE status <> @issued AND (activation_date IS NOT NULL OR type <> @physical)) AS activated_cards,
+	COUNT(*) FILTER (WHERE balance = 0 AND redeemed > 0 AND forfeited = 0) AS fully_redeemed_cards,
+	COUNT(*) FILTER (WHERE status = @expired) AS expired_cards,
+	SUM(face_value) AS face_value,
+	SUM(redeemed) AS redeemed,
+	SUM(forfeited) AS breakage,
+	COALESCE(SUM(balance) FILTER (WHERE status NOT IN @closed), 0) AS outstanding
+FROM card_totals
+GROUP BY currency
+ORDER BY currency`
+
+// GetCampaignCurrencyStats aggregates the cards of a campaign and their
+// ledger per currency. A campaign without cards has no rows.
+func (c *CampaignRepository) GetCampaignCurrencyStats(ctx context.Context, id int) ([]models.CampaignCurrencyStats, error) {
+	log.WithContext(ctx).Infof("GetCampaignCurrencyStats repository for id: %d", id)
+
+	var stats []models.CampaignCurrencyStats
+	res := conn(ctx, c.gorm).Raw(campaignCurrencyStatsQuery, map[string]interface{}{
+		"campaign":    id,
+		"loads":       []string{models.TransactionTypeCreate, models.TransactionTypeActivation},
+		"redemptions": []string{models.TransactionTypeRedemption, models.TransactionTypeRefund},
+		"forfeit":     models.TransactionTypeExpiryForfeit,
+		"types": []string{
+			models.TransactionTypeCreate, models.TransactionTypeActivation,
+			models.TransactionTypeRedemption, models.TransactionTypeRefund,
+			models.TransactionTypeExpiryForfeit,
+		},
+		"issued":   models.GiftCardStatusIssued,
+		"physical": models.GiftCardTypePhysical,
+		"expired":  models.GiftCardStatusExpired,
+		"closed":   []models.GiftCardStatus{models.GiftCardStatusExpired, models.GiftCardStatusCancelled},
+	}).Scan(&stats)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error getting stats of campaign id %d: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return stats, nil
+}
+
+// GetCampaignRedemptions totals the redemptions of the cards of a campaign,
+// net of refunds, per currency and per interval (day, week or month).
+func (c *CampaignRepository) GetCampaignRedemptions(ctx context.Context, id int, interval string) ([]models.CampaignRedemptionPeriod, error) {
+	log.WithContext(ctx).Infof("GetCampaignRedemptions repository for id: %d by %s", id, interval)
+
+	var periods []models.CampaignRedemptionPeriod
+	res := conn(ctx, c.gorm).Table("transactions t").
+		Select("date_trunc(?, t.created_at) AS period, t.currency, COUNT(*) FILTER (WHERE t.transaction_type = ?) AS redemptions, -SUM(t.amount) AS amount",
+			interval, models.TransactionTypeRedemption).
+		Joins("JOIN gift_cards g ON g.id = t.gift_card_id").
+		Where("g.campaign_id = ? AND t.transaction_type IN ?", id, []string{models.TransactionTypeRedemption, models.TransactionTypeRefund}).
+		Group("period, t.currency").
+		Order("period, t.currency").
+		Scan(&periods)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error getting redemptions of campaign id %d: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return periods, nil
+}
+
+// ReserveCampaignBudget counts cards cards worth value against the budget and
+// card limit of a campaign. The check and the increment are one statement, so
+// concurrent reservations cannot overrun the limits; ok is false, and nothing
+// is reserved, when they would. The campaign row stays locked until the
+// transaction ends.
+func (c *CampaignRepository) ReserveCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) (bool, error) {
+	log.WithContext(ctx).Infof("ReserveCampaignBudget repository for campaign id: %d", campaignID)
+
+	res := conn(ctx, c.gorm).Model(&models.Campaign{}).
+		Where("id = ? AND (budget = 0 OR issued_value + ? <= budget) AND (max_cards = 0 OR issued_cards + ? <= max_cards)", campaignID, value, cards).
+		Updates(map[string]interface{}{
+			"issued_value": gorm.Expr("issued_value + ?", value),
+			"issued_cards": gorm.Expr("issued_cards + ?", cards),
+		})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error reserving budget of campaign id %d: %v", campaignID, res.Error)
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// ReleaseCampaignBudget gives back cards cards worth value to a campaign,
+// e.g. the cards a failed issuance job did not issue.
+func (c *CampaignRepository) ReleaseCampaignBudget(ctx context.Context, campaignID uint, cards int, value money.Amount) error {
+	log.WithContext(ctx).Infof("ReleaseCampaignBudget repository for campaign id: %d", campaignID)
+
+	res := conn(ctx, c.gorm).Model(&models.Campaign{}).Where("id = ?", campaignID).
+		Updates(map[string]interface{}{
+			"issued_value": gorm.Expr("GREATEST(issued_value - ?, 0)", value),
+			"issued_cards": gorm.Expr("GREATEST(issued_cards - ?, 0)", cards),
+		})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error releasing budget of campaign id %d: %v", campaignID, res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// CountCustomerCampaignCards counts the cards ordered by a customer under a campaign.
+func (c *CampaignRepository) CountCustomerCampaignCards(ctx context.Context, campaignID uint, customerID uint) (int64, error) {
+	log.WithContext(ctx).Infof("CountCustomerCampaignCards repository for campaign id: %d", campaignID)
+
+	var count int64
+	res := conn(ctx, c.gorm).Model(&models.Order{}).
+		Where("campaign_id = ? AND customer_id = ?", campaignID, customerID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&count)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error counting cards of customer id %d in campaign id %d: %v", customerID, campaignID, res.Error)
+		return 0, res.Error
+	}
+
+	return count, nil
+}
+
+// GetCampaignByIDForUpdate retrieves a campaign by its id and locks the row
+// until the surrounding transaction ends. Returns gorm.ErrRecordNotFound if not found.
+func (c *CampaignRepository) GetCampaignByIDForUpdate(ctx context.Context, id uint) (*models.Campaign, error) {
+	log.WithContext(ctx).Infof("GetCampaignByIDForUpdate repository for id: %d", id)
+
+	var campaign models.Campaign
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Campaign with id %d not found: %v", id, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting campaign with id %d for update: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return &campaign, nil
+}
+
+// UpdateCampaignStatus stores the lifecycle status of a campaign and when it
+// ended, nil while it has not.
+func (c *CampaignRepository) UpdateCampaignStatus(ctx context.Context, id uint, status string, endedAt *time.Time) error {
+	log.WithContext(ctx).Infof("UpdateCampaignStatus repository for id: %d", id)
+
+	res := conn(ctx, c.gorm).Model(&models.Campaign{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "ended_at": endedAt})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating status of campaign id %d: %v", id, res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// SetCampaignCardsExpiryPending records whether the promotional cards of an
+// ended campaign are still to be expired.
+func (c *CampaignRepository) SetCampaignCardsExpiryPending(ctx context.Context, id uint, pending bool) error {
+	log.WithContext(ctx).Infof("SetCampaignCardsExpiryPending repository for id: %d", id)
+
+	res := conn(ctx, c.gorm).Model(&models.Campaign{}).Where("id = ?", id).Update("cards_expiry_pending", pending)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating pending card expiry of campaign id %d: %v", id, res.Error)
+		return res.Error
+	}
+
+	return nil
+}
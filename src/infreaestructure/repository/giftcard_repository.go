@@ -3,25 +3,69 @@ package repository
 import (
 	"GiftWize/src/entity/models"
 	"GiftWize/src/entity/request"
+	"GiftWize/src/shared/money"
 	"context"
 	"errors" // Import the errors package
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // IGiftCardRepository defines the interface for gift card repository operations.
 type IGiftCardRepository interface {
 	GiftCardNumberExists(ctx context.Context, giftCardNumber string) (bool, error)
-	CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, uuid string, giftCardNumber string) error
+	ImportedGiftCardNumberExists(ctx context.Context, giftCardNumber string) (bool, error)
+	CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, uuid string, giftCardNumber string, pinHash string) (*models.GiftCard, error)
 	GetGiftCardByCode(ctx context.Context, code string) (*models.GiftCard, error)
 	GetByGiftCardNumber(ctx context.Context, giftCardNumber string) (*models.GiftCard, error)
+	GetByGiftCardNumberForUpdate(ctx context.Context, giftCardNumber string) (*models.GiftCard, error)
 	GetAllGiftCardList(ctx context.Context) ([]models.GiftCard, error)
 	UpdateGiftCard(ctx context.Context, code string, data request.UpdateGiftCardRequest) error
-	UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance float64, status string) error
+	UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance money.Amount, status models.GiftCardStatus) error
 	FullTextSearchGiftCard(ctx context.Context, query string) ([]models.GiftCard, error)
 	DeleteGiftCard(ctx context.Context, id string) error
+	CreateTransaction(ctx context.Context, transaction *models.Transaction) error
+	ListTransactions(ctx context.Context, giftCardID uint, limit int, offset int) ([]models.Transaction, int64, error)
+	GetGiftCardByIDForUpdate(ctx context.Context, id uint) (*models.GiftCard, error)
+	GetHeldAmount(ctx context.Context, giftCardID uint, now time.Time) (money.Amount, error)
+	CreateAuthorization(ctx context.Context, authorization *models.Authorization) error
+	GetAuthorizationByCode(ctx context.Context, code string) (*models.Authorization, error)
+	GetAuthorizationByCodeForUpdate(ctx context.Context, code string) (*models.Authorization, error)
+	UpdateAuthorization(ctx context.Context, authorization *models.Authorization) error
+	GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error)
+	GetRefundedAmount(ctx context.Context, transactionID uint) (money.Amount, error)
+	GetTransactionTotalSince(ctx context.Context, giftCardID uint, transactionType string, since time.Time) (money.Amount, error)
+	GetGiftCardByCodeForUpdate(ctx context.Context, code string) (*models.GiftCard, error)
+	CreateGiftCardStatusChange(ctx context.Context, change *models.GiftCardStatusChange) error
+	UpdateGiftCardActivation(ctx context.Context, code string, activationDate time.Time, activatedBy string, initialBalance money.Amount) error
+	GetInventoryByID(ctx context.Context, id uint) (*models.Inventory, error)
+	UpdateGiftCardPin(ctx context.Context, code string, pinHash string, failedAttempts int, lockedAt *time.Time) error
+	RecordGiftCardUse(ctx context.Context, code string, usedAt time.Time) error
+	CreateGiftCards(ctx context.Context, giftCards []models.GiftCard) (int64, error)
+	GetIssuedGiftCards(ctx context.Context, issuanceJobID uint, giftCardNumbers []string) ([]models.GiftCard, error)
+	ListIssuedGiftCards(ctx context.Context, issuanceJobID uint, limit int, offset int) ([]models.GiftCard, error)
+	CreateTransactions(ctx context.Context, transactions []models.Transaction) error
+	CreateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error
+	GetIssuanceJobByCode(ctx context.Context, code string) (*models.IssuanceJob, error)
+	UpdateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error
+	GetGiftCardsByNumbers(ctx context.Context, giftCardNumbers []string) ([]models.GiftCard, error)
+	ListGiftCards(ctx context.Context, filter request.GiftCardExportFilter, afterID uint, limit int) ([]models.GiftCard, error)
+	ListExpiredGiftCardsForUpdate(ctx context.Context, now time.Time, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error)
+	CreateExpirySweepRun(ctx context.Context, run *models.ExpirySweepRun) error
+	GetLastExpirySweepRun(ctx context.Context) (*models.ExpirySweepRun, error)
+	UpdateGiftCardExpiration(ctx context.Context, code string, expirationDate time.Time, originalExpirationDate time.Time) error
+	CreateBalanceInquiry(ctx context.Context, inquiry *models.BalanceInquiry) error
+	CreateOrder(ctx context.Context, order *models.Order) error
+	GetIssuanceJobForUpdate(ctx context.Context, id uint) (*models.IssuanceJob, error)
+	ListOpenIssuanceJobsForUpdate(ctx context.Context, campaignID uint) ([]models.IssuanceJob, error)
+	ListStaleIssuanceJobsForUpdate(ctx context.Context, before time.Time) ([]models.IssuanceJob, error)
+	CreateIssuanceJobPins(ctx context.Context, pins []models.IssuanceJobPin) error
+	ListIssuanceJobPins(ctx context.Context, giftCardIDs []uint) ([]models.IssuanceJobPin, error)
+	DeleteIssuanceJobPins(ctx context.Context, issuanceJobID uint) error
+	PurgeIssuanceJobPins(ctx context.Context, before time.Time) (int64, error)
+	ListPromotionalGiftCardsForUpdate(ctx context.Context, campaignID uint, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error)
 }
 
 type GiftCardRepository struct {
@@ -41,7 +85,7 @@ func (c *GiftCardRepository) GiftCardNumberExists(ctx context.Context, giftCardN
 	log.WithContext(ctx).Info("GiftCardNumberExists repository")
 
 	var count int64
-	res := c.gorm.WithContext(ctx).Model(&models.GiftCard{}).Where("gift_card_number = ?", giftCardNumber).Count(&count)
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("gift_card_number = ?", giftCardNumber).Count(&count)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error checking gift card number existence: %v", res.Error)
 		return false, res.Error
@@ -51,40 +95,60 @@ func (c *GiftCardRepository) GiftCardNumberExists(ctx context.Context, giftCardN
 	return count > 0, nil
 }
 
-func (c *GiftCardRepository) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, uuid string, giftCardNumber string) error {
+// ImportedGiftCardNumberExists reports whether giftCardNumber belongs to an
+// imported card.
+func (c *GiftCardRepository) ImportedGiftCardNumberExists(ctx context.Context, giftCardNumber string) (bool, error) {
+	log.WithContext(ctx).Info("ImportedGiftCardNumberExists repository")
+
+	var count int64
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("gift_card_number = ? AND imported", giftCardNumber).Count(&count)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error checking imported gift card number existence: %v", res.Error)
+		return false, res.Error
+	}
+
+	return count > 0, nil
+}
+
+func (c *GiftCardRepository) CreateGiftCard(ctx context.Context, data request.CreateGiftCardRequest, uuid string, giftCardNumber string, pinHash string) (*models.GiftCard, error) {
 	log.WithContext(ctx).Info("CreateGiftCard repository")
 
 	expirationDate, expirationErr := time.Parse("2006-01-02", data.ExpirationDate)
 	if expirationErr != nil {
 		log.WithContext(ctx).Error("create-giftcard-repository Error parsing ExpirationDate:", expirationErr)
-		return expirationErr
+		return nil, expirationErr
 	}
 
-	res := c.gorm.WithContext(ctx).Create(&models.GiftCard{
+	giftCard := &models.GiftCard{
 		Code:           uuid, // Assign the incoming uuid to the Code field
 		Type:           data.Type,
 		GiftCardNumber: giftCardNumber,
 		Balance:        data.Balance,
+		Currency:       data.Currency,
 		ExpirationDate: expirationDate,
-		Status:         data.Status,
+		Status:         models.GiftCardStatus(data.Status),
 		IsPromotional:  data.IsPromotional,
-		CampaignID:     data.CampaignID,
-	})
+		CampaignID:     optionalID(data.CampaignID),
+		InventoryID:    optionalID(data.InventoryID),
+		PinHash:        pinHash,
+		MaxUses:        data.MaxUses,
+	}
+	res := conn(ctx, c.gorm).Create(giftCard)
 
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error creating gift card: %v", res.Error)
-		return res.Error
+		return nil, res.Error
 	}
 
 	log.WithContext(ctx).Info("Gift card created successfully")
-	return nil
+	return giftCard, nil
 }
 
 func (c *GiftCardRepository) GetGiftCardByCode(ctx context.Context, code string) (*models.GiftCard, error) {
 	log.WithContext(ctx).Infof("GetGiftCardByCode repository for code: %s", code)
 
 	var giftCard models.GiftCard
-	res := c.gorm.WithContext(ctx).Where("code = ?", code).First(&giftCard)
+	res := conn(ctx, c.gorm).Where("code = ?", code).First(&giftCard)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			log.WithContext(ctx).Warnf("Gift card with code %s not found: %v", code, res.Error)
@@ -103,7 +167,7 @@ func (c *GiftCardRepository) GetGiftCardByCode(ctx context.Context, code string)
 func (c *GiftCardRepository) GetByGiftCardNumber(ctx context.Context, giftCardNumber string) (*models.GiftCard, error) {
 	log.WithContext(ctx).Infof("GetByGiftCardNumber repository for number: %s", giftCardNumber)
 	var giftCard models.GiftCard
-	res := c.gorm.WithContext(ctx).Where("gift_card_number = ?", giftCardNumber).First(&giftCard)
+	res := conn(ctx, c.gorm).Where("gift_card_number = ?", giftCardNumber).First(&giftCard)
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
 			log.WithContext(ctx).Warnf("Gift card with number %s not found: %v", giftCardNumber, res.Error)
@@ -116,11 +180,31 @@ func (c *GiftCardRepository) GetByGiftCardNumber(ctx context.Context, giftCardNu
 	return &giftCard, nil
 }
 
+// GetByGiftCardNumberForUpdate retrieves a gift card by its number and locks the row
+// with SELECT ... FOR UPDATE until the surrounding transaction ends.
+// It must be called inside ITransactor.WithTransaction.
+// Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetByGiftCardNumberForUpdate(ctx context.Context, giftCardNumber string) (*models.GiftCard, error) {
+	log.WithContext(ctx).Infof("GetByGiftCardNumberForUpdate repository for number: %s", giftCardNumber)
+	var giftCard models.GiftCard
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).Where("gift_card_number = ?", giftCardNumber).First(&giftCard)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Gift card with number %s not found: %v", giftCardNumber, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error locking gift card by number %s: %v", giftCardNumber, res.Error)
+		return nil, res.Error
+	}
+	log.WithContext(ctx).Infof("Gift card with number %s locked successfully", giftCardNumber)
+	return &giftCard, nil
+}
+
 func (c *GiftCardRepository) GetAllGiftCardList(ctx context.Context) ([]models.GiftCard, error) {
 	log.WithContext(ctx).Info("GetAllGiftCardList repository")
 
 	var giftCards []models.GiftCard
-	res := c.gorm.WithContext(ctx).Find(&giftCards)
+	res := conn(ctx, c.gorm).Find(&giftCards)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error getting gift card list: %v", res.Error)
 		return []models.GiftCard{}, res.Error
@@ -139,22 +223,6 @@ func (c *GiftCardRepository) UpdateGiftCard(ctx context.Context, code string, da
 	if data.Type != "" {
 		updateFields["type"] = data.Type
 	}
-	// Assuming Balance can be 0, so we update it if it's part of the request.
-	// If Balance is a pointer in UpdateGiftCardRequest, we can check for nil.
-	// For now, always including it if the request means to set it.
-	updateFields["balance"] = data.Balance
-
-	if data.ExpirationDate != "" {
-		expirationDate, err := time.Parse("2006-01-02", data.ExpirationDate)
-		if err != nil {
-			log.WithContext(ctx).Errorf("Error parsing ExpirationDate for code %s: %v", code, err) // Ensure 'code' is used here
-			return err
-		}
-		updateFields["expiration_date"] = expirationDate
-	}
-	if data.Status != "" {
-		updateFields["status"] = data.Status
-	}
 	// IsPromotional is a bool, so it will always have a value.
 	// This means it will always be included in the update if present in the struct.
 	// If partial update is needed for bools, use a pointer or specific logic.
@@ -166,7 +234,7 @@ func (c *GiftCardRepository) UpdateGiftCard(ctx context.Context, code string, da
         return nil
     }
 
-	res := c.gorm.WithContext(ctx).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error updating gift card code %s: %v", code, res.Error)
 		return res.Error
@@ -184,7 +252,7 @@ func (c *GiftCardRepository) UpdateGiftCard(ctx context.Context, code string, da
 }
 
 // UpdateGiftCardBalanceAndStatus updates the balance and status of a gift card.
-func (c *GiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance float64, status string) error {
+func (c *GiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context, code string, balance money.Amount, status models.GiftCardStatus) error {
 	log.WithContext(ctx).Infof("UpdateGiftCardBalanceAndStatus repository for code: %s", code)
 
 	updateFields := map[string]interface{}{
@@ -192,7 +260,7 @@ func (c *GiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context,
 		"status":  status,
 	}
 
-	res := c.gorm.WithContext(ctx).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error updating balance/status for gift card code %s: %v", code, res.Error) // Ensure 'code' is used here
 		return res.Error
@@ -206,11 +274,17 @@ func (c *GiftCardRepository) UpdateGiftCardBalanceAndStatus(ctx context.Context,
 }
 
 
+// FullTextSearchGiftCard finds the cards whose number contains query, or is
+// similar enough to it to survive a typo, most similar first. The trigram
+// index on the number serves both conditions.
 func (c *GiftCardRepository) FullTextSearchGiftCard(ctx context.Context, query string) ([]models.GiftCard, error) {
 	log.WithContext(ctx).Info("FullTextSearchGiftCard repository")
 
 	var giftCards []models.GiftCard
-	res := c.gorm.WithContext(ctx).Where("MATCH(type, status) AGAINST(? IN BOOLEAN MODE)", query).Find(&giftCards)
+	res := conn(ctx, c.gorm).
+		Where("gift_card_number ILIKE ? OR gift_card_number % ?", "%"+likeEscaper.Replace(query)+"%", query).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: "similarity(gift_card_number, ?) DESC, id", Vars: []interface{}{query}}}).
+		Limit(searchResultLimit).Find(&giftCards)
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error full text searching gift card: %v", res.Error)
 		return []models.GiftCard{}, res.Error
@@ -223,7 +297,7 @@ func (c *GiftCardRepository) FullTextSearchGiftCard(ctx context.Context, query s
 func (c *GiftCardRepository) DeleteGiftCard(ctx context.Context, id string) error {
 	log.WithContext(ctx).Info("DeleteGiftCard repository")
 
-	res := c.gorm.WithContext(ctx).Delete(&models.GiftCard{}, "code = ?", id) // id is code here
+	res := conn(ctx, c.gorm).Delete(&models.GiftCard{}, "code = ?", id) // id is code here
 	if res.Error != nil {
 		log.WithContext(ctx).Errorf("Error deleting gift card code %s: %v", id, res.Error)
 		return res.Error
@@ -237,3 +311,688 @@ func (c *GiftCardRepository) DeleteGiftCard(ctx context.Context, id string) erro
 	log.WithContext(ctx).Infof("Gift card with code %s deleted successfully", id)
 	return nil
 }
+
+// CreateTransaction appends an entry to the gift card ledger.
+func (c *GiftCardRepository) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
+	log.WithContext(ctx).Infof("CreateTransaction repository for gift card id: %d", transaction.GiftCardID)
+
+	res := conn(ctx, c.gorm).Create(transaction)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating transaction for gift card id %d: %v", transaction.GiftCardID, res.Error)
+		return res.Error
+	}
+
+	log.WithContext(ctx).Info("Transaction created successfully")
+	return nil
+}
+
+// ListTransactions returns one page of a gift card ledger, newest first, and the total number of entries.
+func (c *GiftCardRepository) ListTransactions(ctx context.Context, giftCardID uint, limit int, offset int) ([]models.Transaction, int64, error) {
+	log.WithContext(ctx).Infof("ListTransactions repository for gift card id: %d", giftCardID)
+
+	var total int64
+	res := conn(ctx, c.gorm).Model(&models.Transaction{}).Where("gift_card_id = ?", giftCardID).Count(&total)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error counting transactions for gift card id %d: %v", giftCardID, res.Error)
+		return nil, 0, res.Error
+	}
+
+	var transactions []models.Transaction
+	res = conn(ctx, c.gorm).Where("gift_card_id = ?", giftCardID).Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&transactions)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing transactions for gift card id %d: %v", giftCardID, res.Error)
+		return nil, 0, res.Error
+	}
+
+	log.WithContext(ctx).Info("Transactions retrieved successfully")
+	return transactions, total, nil
+}
+
+// GetGiftCardByIDForUpdate retrieves a gift card by its id and locks the row until
+// the surrounding transaction ends. Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetGiftCardByIDForUpdate(ctx context.Context, id uint) (*models.GiftCard, error) {
+	log.WithContext(ctx).Infof("GetGiftCardByIDForUpdate repository for id: %d", id)
+
+	var giftCard models.GiftCard
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).First(&giftCard, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Gift card with id %d not found: %v", id, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error locking gift card with id %d: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return &giftCard, nil
+}
+
+// GetHeldAmount sums the pending authorizations of a gift card that have not expired at now.
+func (c *GiftCardRepository) GetHeldAmount(ctx context.Context, giftCardID uint, now time.Time) (money.Amount, error) {
+	log.WithContext(ctx).Infof("GetHeldAmount repository for gift card id: %d", giftCardID)
+
+	var held money.Amount
+	res := conn(ctx, c.gorm).Model(&models.Authorization{}).
+		Where("gift_card_id = ? AND status = ? AND expires_at > ?", giftCardID, models.AuthorizationStatusPending, now).
+		Select("COALESCE(SUM(amount), 0)").Scan(&held)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error summing holds for gift card id %d: %v", giftCardID, res.Error)
+		return 0, res.Error
+	}
+
+	return held, nil
+}
+
+func (c *GiftCardRepository) CreateAuthorization(ctx context.Context, authorization *models.Authorization) error {
+	log.WithContext(ctx).Infof("CreateAuthorization repository for gift card id: %d", authorization.GiftCardID)
+
+	res := conn(ctx, c.gorm).Create(authorization)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating authorization: %v", res.Error)
+		return res.Error
+	}
+
+	log.WithContext(ctx).Info("Authorization created successfully")
+	return nil
+}
+
+// GetAuthorizationByCode retrieves an authorization by its code.
+// Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetAuthorizationByCode(ctx context.Context, code string) (*models.Authorization, error) {
+	return c.getAuthorizationByCode(ctx, conn(ctx, c.gorm), code)
+}
+
+// GetAuthorizationByCodeForUpdate retrieves an authorization by its code and locks the row
+// until the surrounding transaction ends. Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetAuthorizationByCodeForUpdate(ctx context.Context, code string) (*models.Authorization, error) {
+	return c.getAuthorizationByCode(ctx, conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}), code)
+}
+
+func (c *GiftCardRepository) getAuthorizationByCode(ctx context.Context, db *gorm.DB, code string) (*models.Authorization, error) {
+	log.WithContext(ctx).Infof("GetAuthorizationByCode repository for code: %s", code)
+
+	var authorization models.Authorization
+	res := db.Where("code = ?", code).First(&authorization)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Authorization with code %s not found: %v", code, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting authorization by code %s: %v", code, res.Error)
+		return nil, res.Error
+	}
+
+	return &authorization, nil
+}
+
+// UpdateAuthorization persists the status and capture details of an authorization.
+func (c *GiftCardRepository) UpdateAuthorization(ctx context.Context, authorization *models.Authorization) error {
+	log.WithContext(ctx).Infof("UpdateAuthorization repository for code: %s", authorization.Code)
+
+	res := conn(ctx, c.gorm).Model(authorization).Select("status", "captured_amount", "captured_at", "voided_at").Updates(authorization)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating authorization %s: %v", authorization.Code, res.Error)
+		return res.Error
+	}
+
+	log.WithContext(ctx).Info("Authorization updated successfully")
+	return nil
+}
+
+// GetTransactionByID retrieves a ledger entry by its id.
+// Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetTransactionByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	log.WithContext(ctx).Infof("GetTransactionByID repository for id: %d", id)
+
+	var transaction models.Transaction
+	res := conn(ctx, c.gorm).First(&transaction, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Transaction with id %d not found: %v", id, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting transaction with id %d: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return &transaction, nil
+}
+
+// GetRefundedAmount sums the refunds already issued against a transaction.
+func (c *GiftCardRepository) GetRefundedAmount(ctx context.Context, transactionID uint) (money.Amount, error) {
+	log.WithContext(ctx).Infof("GetRefundedAmount repository for transaction id: %d", transactionID)
+
+	var refunded money.Amount
+	res := conn(ctx, c.gorm).Model(&models.Transaction{}).
+		Where("refunded_transaction_id = ? AND transaction_type = ?", transactionID, models.TransactionTypeRefund).
+		Select("COALESCE(SUM(amount), 0)").Scan(&refunded)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error summing refunds for transaction id %d: %v", transactionID, res.Error)
+		return 0, res.Error
+	}
+
+	return refunded, nil
+}
+
+// GetTransactionTotalSince sums the ledger entries of one type recorded for a gift card since the given time.
+func (c *GiftCardRepository) GetTransactionTotalSince(ctx context.Context, giftCardID uint, transactionType string, since time.Time) (money.Amount, error) {
+	log.WithContext(ctx).Infof("GetTransactionTotalSince repository for gift card id: %d, type: %s", giftCardID, transactionType)
+
+	var total money.Amount
+	res := conn(ctx, c.gorm).Model(&models.Transaction{}).
+		Where("gift_card_id = ? AND transaction_type = ? AND created_at >= ?", giftCardID, transactionType, since).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error summing %s transactions for gift card id %d: %v", transactionType, giftCardID, res.Error)
+		return 0, res.Error
+	}
+
+	return total, nil
+}
+
+// GetGiftCardByCodeForUpdate retrieves a gift card by its code and locks the row until
+// the surrounding transaction ends. Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetGiftCardByCodeForUpdate(ctx context.Context, code string) (*models.GiftCard, error) {
+	log.WithContext(ctx).Infof("GetGiftCardByCodeForUpdate repository for code: %s", code)
+
+	var giftCard models.GiftCard
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&giftCard)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Gift card with code %s not found: %v", code, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error locking gift card with code %s: %v", code, res.Error)
+		return nil, res.Error
+	}
+
+	return &giftCard, nil
+}
+
+// CreateGiftCardStatusChange records a lifecycle transition of a gift card.
+func (c *GiftCardRepository) CreateGiftCardStatusChange(ctx context.Context, change *models.GiftCardStatusChange) error {
+	log.WithContext(ctx).Infof("CreateGiftCardStatusChange repository for gift card id: %d (%s -> %s)", change.GiftCardID, change.FromStatus, change.ToStatus)
+
+	res := conn(ctx, c.gorm).Create(change)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error recording status change: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// UpdateGiftCardActivation records when and by whom a gift card was activated,
+// along with the balance it was activated with.
+func (c *GiftCardRepository) UpdateGiftCardActivation(ctx context.Context, code string, activationDate time.Time, activatedBy string, initialBalance money.Amount) error {
+	log.WithContext(ctx).Infof("UpdateGiftCardActivation repository for code: %s", code)
+
+	updateFields := map[string]interface{}{
+		"activation_date": activationDate,
+		"activated_by":    activatedBy,
+		"initial_balance": initialBalance,
+	}
+
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error recording activation for gift card code %s: %v", code, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		log.WithContext(ctx).Warnf("No gift card found with code %s to activate", code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateGiftCardExpiration sets a new expiration date on a gift card,
+// keeping the date it was issued with in original_expiration_date.
+func (c *GiftCardRepository) UpdateGiftCardExpiration(ctx context.Context, code string, expirationDate time.Time, originalExpirationDate time.Time) error {
+	log.WithContext(ctx).Infof("UpdateGiftCardExpiration repository for code: %s", code)
+
+	updateFields := map[string]interface{}{
+		"expiration_date":          expirationDate,
+		"original_expiration_date": originalExpirationDate,
+	}
+
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating expiration for gift card code %s: %v", code, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		log.WithContext(ctx).Warnf("No gift card found with code %s to extend", code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetInventoryByID retrieves an inventory batch by its id.
+// Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetInventoryByID(ctx context.Context, id uint) (*models.Inventory, error) {
+	log.WithContext(ctx).Infof("GetInventoryByID repository for id: %d", id)
+
+	var inventory models.Inventory
+	res := conn(ctx, c.gorm).First(&inventory, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Inventory with id %d not found: %v", id, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting inventory with id %d: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return &inventory, nil
+}
+
+// CreateOrder records the sale of gift cards.
+func (c *GiftCardRepository) CreateOrder(ctx context.Context, order *models.Order) error {
+	log.WithContext(ctx).Info("CreateOrder repository")
+
+	res := conn(ctx, c.gorm).Create(order)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating order: %v", res.Error)
+		return res.Error
+	}
+
+	log.WithContext(ctx).Info("Order created successfully")
+	return nil
+}
+
+// UpdateGiftCardPin stores the PIN hash, the failed attempt counter and the
+// lockout time of a gift card. A nil lockedAt unlocks the PIN.
+func (c *GiftCardRepository) UpdateGiftCardPin(ctx context.Context, code string, pinHash string, failedAttempts int, lockedAt *time.Time) error {
+	log.WithContext(ctx).Infof("UpdateGiftCardPin repository for code: %s", code)
+
+	updateFields := map[string]interface{}{
+		"pin_hash":            pinHash,
+		"failed_pin_attempts": failedAttempts,
+		"pin_locked_at":       lockedAt,
+	}
+
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating PIN for gift card code %s: %v", code, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		log.WithContext(ctx).Warnf("No gift card found with code %s to update PIN", code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RecordGiftCardUse increments the use counter of a gift card and stamps
+// its last use.
+func (c *GiftCardRepository) RecordGiftCardUse(ctx context.Context, code string, usedAt time.Time) error {
+	log.WithContext(ctx).Infof("RecordGiftCardUse repository for code: %s", code)
+
+	updateFields := map[string]interface{}{
+		"current_uses":   gorm.Expr("current_uses + 1"),
+		"last_used_date": usedAt,
+	}
+
+	res := conn(ctx, c.gorm).Model(&models.GiftCard{}).Where("code = ?", code).Updates(updateFields)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error recording use of gift card code %s: %v", code, res.Error)
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		log.WithContext(ctx).Warnf("No gift card found with code %s to record a use", code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateGiftCards inserts giftCards in one statement, skipping the cards
+// whose number is already taken. It returns how many cards were inserted;
+// the inserted cards must be read back with GetIssuedGiftCards.
+func (c *GiftCardRepository) CreateGiftCards(ctx context.Context, giftCards []models.GiftCard) (int64, error) {
+	log.WithContext(ctx).Infof("CreateGiftCards repository for %d gift cards", len(giftCards))
+
+	res := conn(ctx, c.gorm).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "gift_card_number"}}, DoNothing: true}).Create(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating gift cards: %v", res.Error)
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+// GetIssuedGiftCards retrieves the cards of an issuance job among giftCardNumbers.
+func (c *GiftCardRepository) GetIssuedGiftCards(ctx context.Context, issuanceJobID uint, giftCardNumbers []string) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("GetIssuedGiftCards repository for issuance job id: %d", issuanceJobID)
+
+	var giftCards []models.GiftCard
+	res := conn(ctx, c.gorm).Where("issuance_job_id = ? AND gift_card_number IN ?", issuanceJobID, giftCardNumbers).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error getting gift cards of issuance job id %d: %v", issuanceJobID, res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// ListIssuedGiftCards pages through the cards of an issuance job in creation order.
+func (c *GiftCardRepository) ListIssuedGiftCards(ctx context.Context, issuanceJobID uint, limit int, offset int) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("ListIssuedGiftCards repository for issuance job id: %d", issuanceJobID)
+
+	var giftCards []models.GiftCard
+	res := conn(ctx, c.gorm).Where("issuance_job_id = ?", issuanceJobID).Order("id").Limit(limit).Offset(offset).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing gift cards of issuance job id %d: %v", issuanceJobID, res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// CreateTransactions inserts ledger entries in one statement.
+func (c *GiftCardRepository) CreateTransactions(ctx context.Context, transactions []models.Transaction) error {
+	log.WithContext(ctx).Infof("CreateTransactions repository for %d transactions", len(transactions))
+
+	res := conn(ctx, c.gorm).Create(&transactions)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating transactions: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// CreateIssuanceJob records a new bulk issuance job.
+func (c *GiftCardRepository) CreateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error {
+	log.WithContext(ctx).Infof("CreateIssuanceJob repository for %d gift cards", job.Count)
+
+	res := conn(ctx, c.gorm).Create(job)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating issuance job: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// GetIssuanceJobByCode retrieves an issuance job by its code.
+// Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetIssuanceJobByCode(ctx context.Context, code string) (*models.IssuanceJob, error) {
+	log.WithContext(ctx).Infof("GetIssuanceJobByCode repository for code: %s", code)
+
+	var job models.IssuanceJob
+	res := conn(ctx, c.gorm).Where("code = ?", code).First(&job)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Issuance job with code %s not found: %v", code, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting issuance job with code %s: %v", code, res.Error)
+		return nil, res.Error
+	}
+
+	return &job, nil
+}
+
+// UpdateIssuanceJob persists the status, progress and error of an issuance job.
+func (c *GiftCardRepository) UpdateIssuanceJob(ctx context.Context, job *models.IssuanceJob) error {
+	log.WithContext(ctx).Infof("UpdateIssuanceJob repository for code: %s", job.Code)
+
+	res := conn(ctx, c.gorm).Model(job).Select("status", "issued_count", "error", "completed_at", "exported_at").Updates(job)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error updating issuance job %s: %v", job.Code, res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// GetIssuanceJobForUpdate retrieves an issuance job by its id and locks the
+// row until the surrounding transaction ends. Returns gorm.ErrRecordNotFound if not found.
+func (c *GiftCardRepository) GetIssuanceJobForUpdate(ctx context.Context, id uint) (*models.IssuanceJob, error) {
+	log.WithContext(ctx).Infof("GetIssuanceJobForUpdate repository for id: %d", id)
+
+	var job models.IssuanceJob
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Warnf("Issuance job with id %d not found: %v", id, res.Error)
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting issuance job with id %d for update: %v", id, res.Error)
+		return nil, res.Error
+	}
+
+	return &job, nil
+}
+
+// ListOpenIssuanceJobsForUpdate locks the pending and running issuance jobs
+// of a campaign.
+func (c *GiftCardRepository) ListOpenIssuanceJobsForUpdate(ctx context.Context, campaignID uint) ([]models.IssuanceJob, error) {
+	log.WithContext(ctx).Infof("ListOpenIssuanceJobsForUpdate repository for campaign id: %d", campaignID)
+
+	var jobs []models.IssuanceJob
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("campaign_id = ? AND status IN ?", campaignID, []string{models.IssuanceJobStatusPending, models.IssuanceJobStatusRunning}).
+		Order("id").Find(&jobs)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing open issuance jobs of campaign id %d: %v", campaignID, res.Error)
+		return nil, res.Error
+	}
+
+	return jobs, nil
+}
+
+// ListStaleIssuanceJobsForUpdate locks the pending and running issuance jobs
+// last updated before before. Jobs locked elsewhere, e.g. by a batch being
+// issued, are skipped.
+func (c *GiftCardRepository) ListStaleIssuanceJobsForUpdate(ctx context.Context, before time.Time) ([]models.IssuanceJob, error) {
+	log.WithContext(ctx).Infof("ListStaleIssuanceJobsForUpdate repository before %s", before.Format(time.RFC3339))
+
+	var jobs []models.IssuanceJob
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status IN ? AND updated_at < ?", []string{models.IssuanceJobStatusPending, models.IssuanceJobStatusRunning}, before).
+		Order("id").Find(&jobs)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing stale issuance jobs: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return jobs, nil
+}
+
+// CreateIssuanceJobPins stores the PINs of issued cards in one statement.
+func (c *GiftCardRepository) CreateIssuanceJobPins(ctx context.Context, pins []models.IssuanceJobPin) error {
+	log.WithContext(ctx).Infof("CreateIssuanceJobPins repository for %d PINs", len(pins))
+
+	res := conn(ctx, c.gorm).Create(&pins)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating issuance job PINs: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// ListIssuanceJobPins retrieves the stored PINs of the cards among giftCardIDs.
+func (c *GiftCardRepository) ListIssuanceJobPins(ctx context.Context, giftCardIDs []uint) ([]models.IssuanceJobPin, error) {
+	log.WithContext(ctx).Infof("ListIssuanceJobPins repository for %d gift cards", len(giftCardIDs))
+
+	var pins []models.IssuanceJobPin
+	res := conn(ctx, c.gorm).Where("gift_card_id IN ?", giftCardIDs).Find(&pins)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing issuance job PINs: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return pins, nil
+}
+
+// DeleteIssuanceJobPins deletes the stored PINs of the cards of an issuance job.
+func (c *GiftCardRepository) DeleteIssuanceJobPins(ctx context.Context, issuanceJobID uint) error {
+	log.WithContext(ctx).Infof("DeleteIssuanceJobPins repository for issuance job id: %d", issuanceJobID)
+
+	res := conn(ctx, c.gorm).Where("issuance_job_id = ?", issuanceJobID).Delete(&models.IssuanceJobPin{})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error deleting PINs of issuance job id %d: %v", issuanceJobID, res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// PurgeIssuanceJobPins deletes the stored PINs of the issuance jobs that
+// finished before before, and returns how many it deleted.
+func (c *GiftCardRepository) PurgeIssuanceJobPins(ctx context.Context, before time.Time) (int64, error) {
+	log.WithContext(ctx).Infof("PurgeIssuanceJobPins repository before %s", before.Format(time.RFC3339))
+
+	res := conn(ctx, c.gorm).Where("issuance_job_id IN (SELECT id FROM issuance_jobs WHERE completed_at < ?)", before).Delete(&models.IssuanceJobPin{})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error purging issuance job PINs: %v", res.Error)
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
+
+// GetGiftCardsByNumbers retrieves the cards among giftCardNumbers that exist.
+func (c *GiftCardRepository) GetGiftCardsByNumbers(ctx context.Context, giftCardNumbers []string) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("GetGiftCardsByNumbers repository for %d gift card numbers", len(giftCardNumbers))
+
+	var giftCards []models.GiftCard
+	res := conn(ctx, c.gorm).Where("gift_card_number IN ?", giftCardNumbers).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error getting gift cards by number: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// ListGiftCards pages through the cards matching filter in id order, starting
+// after the card with id afterID.
+func (c *GiftCardRepository) ListGiftCards(ctx context.Context, filter request.GiftCardExportFilter, afterID uint, limit int) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("ListGiftCards repository after id: %d", afterID)
+
+	query := conn(ctx, c.gorm).Where("id > ?", afterID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.CampaignID != 0 {
+		query = query.Where("campaign_id = ?", filter.CampaignID)
+	}
+	if filter.ExpiresAfter != "" {
+		query = query.Where("expiration_date >= ?", filter.ExpiresAfter)
+	}
+	if filter.ExpiresBefore != "" {
+		query = query.Where("expiration_date <= ?", filter.ExpiresBefore)
+	}
+
+	var giftCards []models.GiftCard
+	res := query.Order("id").Limit(limit).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing gift cards: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// ListExpiredGiftCardsForUpdate locks up to limit cards in one of statuses
+// whose expiration date is before now, in id order starting after the card
+// with id afterID. Cards locked by other transactions are skipped rather
+// than waited for.
+func (c *GiftCardRepository) ListExpiredGiftCardsForUpdate(ctx context.Context, now time.Time, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("ListExpiredGiftCardsForUpdate repository before: %s", now.Format("2006-01-02 15:04:05"))
+
+	var giftCards []models.GiftCard
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id > ? AND expiration_date < ? AND status IN ?", afterID, now, statuses).
+		Order("id").Limit(limit).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing expired gift cards: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// ListPromotionalGiftCardsForUpdate locks up to limit promotional cards of a
+// campaign in one of statuses, in id order starting after the card with id
+// afterID.
+func (c *GiftCardRepository) ListPromotionalGiftCardsForUpdate(ctx context.Context, campaignID uint, statuses []models.GiftCardStatus, afterID uint, limit int) ([]models.GiftCard, error) {
+	log.WithContext(ctx).Infof("ListPromotionalGiftCardsForUpdate repository for campaign id: %d", campaignID)
+
+	var giftCards []models.GiftCard
+	res := conn(ctx, c.gorm).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id > ? AND campaign_id = ? AND is_promotional = ? AND status IN ?", afterID, campaignID, true, statuses).
+		Order("id").Limit(limit).Find(&giftCards)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error listing promotional gift cards of campaign id %d: %v", campaignID, res.Error)
+		return nil, res.Error
+	}
+
+	return giftCards, nil
+}
+
+// CreateExpirySweepRun records a finished run of the expiry sweeper.
+func (c *GiftCardRepository) CreateExpirySweepRun(ctx context.Context, run *models.ExpirySweepRun) error {
+	log.WithContext(ctx).Info("CreateExpirySweepRun repository")
+
+	res := conn(ctx, c.gorm).Create(run)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating expiry sweep run: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// GetLastExpirySweepRun retrieves the most recently started expiry sweep run.
+// Returns gorm.ErrRecordNotFound if the sweeper never ran.
+func (c *GiftCardRepository) GetLastExpirySweepRun(ctx context.Context) (*models.ExpirySweepRun, error) {
+	log.WithContext(ctx).Info("GetLastExpirySweepRun repository")
+
+	var run models.ExpirySweepRun
+	res := conn(ctx, c.gorm).Order("started_at DESC").First(&run)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Info("No expiry sweep run recorded yet")
+			return nil, gorm.ErrRecordNotFound
+		}
+		log.WithContext(ctx).Errorf("Error getting last expiry sweep run: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return &run, nil
+}
+
+// CreateBalanceInquiry records a point of sale balance inquiry.
+func (c *GiftCardRepository) CreateBalanceInquiry(ctx context.Context, inquiry *models.BalanceInquiry) error {
+	log.WithContext(ctx).Infof("CreateBalanceInquiry repository for number: %s", inquiry.GiftCardNumber)
+
+	res := conn(ctx, c.gorm).Create(inquiry)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating balance inquiry: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// optionalID maps the zero id used by requests to a NULL foreign key.
+func optionalID(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"GiftWize/src/entity/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IIdempotencyRepository defines the interface for idempotency key storage.
+type IIdempotencyRepository interface {
+	CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	DeleteExpiredIdempotencyKey(ctx context.Context, key string, now time.Time) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time, limit int) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	gorm *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository.
+func NewIdempotencyRepository(gorm *gorm.DB) IIdempotencyRepository {
+	return &IdempotencyRepository{gorm: gorm}
+}
+
+// Ensure IdempotencyRepository implements IIdempotencyRepository
+var _ IIdempotencyRepository = (*IdempotencyRepository)(nil)
+
+// CreateIdempotencyKey reserves a key. It returns false without error when the
+// key is already taken, relying on the unique index so that concurrent
+// instances cannot both reserve it.
+func (r *IdempotencyRepository) CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	log.WithContext(ctx).Info("CreateIdempotencyKey repository")
+
+	res := conn(ctx, r.gorm).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key"}}, DoNothing: true}).Create(key)
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error creating idempotency key: %v", res.Error)
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// GetIdempotencyKey returns the stored key, or nil when it does not exist.
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error) {
+	log.WithContext(ctx).Info("GetIdempotencyKey repository")
+
+	var idempotencyKey models.IdempotencyKey
+	res := conn(ctx, r.gorm).Where("key = ?", key).First(&idempotencyKey)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		log.WithContext(ctx).Errorf("Error getting idempotency key: %v", res.Error)
+		return nil, res.Error
+	}
+
+	return &idempotencyKey, nil
+}
+
+// CompleteIdempotencyKey stores the response to replay for the key.
+func (r *IdempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	log.WithContext(ctx).Info("CompleteIdempotencyKey repository")
+
+	res := conn(ctx, r.gorm).Model(&models.IdempotencyKey{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"completed":     true,
+		"status_code":   statusCode,
+		"content_type":  contentType,
+		"response_body": body,
+	})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error completing idempotency key: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey releases a key so that the request can be retried.
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	log.WithContext(ctx).Info("DeleteIdempotencyKey repository")
+
+	res := conn(ctx, r.gorm).Where("key = ?", key).Delete(&models.IdempotencyKey{})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error deleting idempotency key: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKey removes the key if its TTL has elapsed.
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKey(ctx context.Context, key string, now time.Time) error {
+	res := conn(ctx, r.gorm).Where("key = ? AND expires_at < ?", key, now).Delete(&models.IdempotencyKey{})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error deleting expired idempotency key: %v", res.Error)
+		return res.Error
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys removes up to limit keys whose TTL has elapsed
+// and returns how many were removed.
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time, limit int) (int64, error) {
+	log.WithContext(ctx).Info("DeleteExpiredIdempotencyKeys repository")
+
+	expired := conn(ctx, r.gorm).Model(&models.IdempotencyKey{}).Select("id").Where("expires_at < ?", now).Limit(limit)
+	res := conn(ctx, r.gorm).Where("id IN (?)", expired).Delete(&models.IdempotencyKey{})
+	if res.Error != nil {
+		log.WithContext(ctx).Errorf("Error deleting expired idempotency keys: %v", res.Error)
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// searchResultLimit bounds the results of campaign and gift card searches.
+	searchResultLimit = 50
+	// searchConfig is the text search configuration of campaign search. The
+	// simple configuration does not stem, so names in any language match as
+	// written.
+	searchConfig = "simple"
+)
+
+// searchMigrations are run in order by MigrateSearch. Each is idempotent.
+var searchMigrations = []string{
+	// Trigram indexes let gift card numbers be found by any part of them.
+	// Creating the extension needs a role allowed to, e.g. the database owner.
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	// Names weigh more than descriptions in the ranking
+	`ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('` + searchConfig + `', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('` + searchConfig + `', coalesce(description, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_campaigns_search_vector ON campaigns USING gin (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_gift_cards_number_trgm ON gift_cards USING gin (gift_card_number gin_trgm_ops)`,
+}
+
+// MigrateSearch creates what campaign and gift card search rely on and
+// AutoMigrate cannot express. It must run after the campaigns and gift_cards
+// tables are migrated, i.e. after shared.Init.
+func MigrateSearch(db *gorm.DB) error {
+	for _, statement := range searchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// likeEscaper escapes the LIKE wildcards of user input, so it is matched
+// literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"GiftWize/src/entity/models"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// ISettingRepository defines the interface for reading runtime settings.
+type ISettingRepository interface {
+	GetSetting(ctx context.Context, name string) (*models.Setting, error)
+}
+
+type SettingRepository struct {
+	gorm *gorm.DB
+}
+
+// NewSettingRepository creates a new instance of SettingRepository.
+func NewSettingRepository(gorm *gorm.DB) ISettingRepository {
+	return &SettingRepository{gorm: gorm}
+}
+
+// Ensure SettingRepository implements ISettingRepository
+var _ ISettingRepository = (*SettingRepository)(nil)
+
+// GetSetting returns the setting with the given name, or nil when it is not configured.
+func (s *SettingRepository) GetSetting(ctx context.Context, name string) (*models.Setting, error) {
+	log.WithContext(ctx).Infof("GetSetting repository for name: %s", name)
+
+	var setting models.Setting
+	res := conn(ctx, s.gorm).Where("name = ?", name).First(&setting)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			log.WithContext(ctx).Infof("Setting %s is not configured", name)
+			return nil, nil
+		}
+		log.WithContext(ctx).Errorf("Error getting setting %s: %v", name, res.Error)
+		return nil, res.Error
+	}
+
+	log.WithContext(ctx).Info("Setting retrieved successfully")
+	return &setting, nil
+}
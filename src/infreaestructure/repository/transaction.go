@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// ITransactor runs a unit of work inside a single database transaction.
+// Repository calls made with the ctx passed to fn join that transaction.
+type ITransactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	TryAdvisoryLock(ctx context.Context, key int64) (bool, error)
+}
+
+type Transactor struct {
+	gorm *gorm.DB
+}
+
+// NewTransactor creates a new instance of Transactor.
+func NewTransactor(gorm *gorm.DB) ITransactor {
+	return &Transactor{gorm: gorm}
+}
+
+// Ensure Transactor implements ITransactor
+var _ ITransactor = (*Transactor)(nil)
+
+// WithTransaction commits when fn returns nil and rolls back otherwise.
+// A call made while a transaction is already open reuses it.
+func (t *Transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.gorm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// TryAdvisoryLock takes the Postgres advisory lock key for the rest of the
+// transaction bound to ctx, without waiting. It reports false when another
+// session holds the lock. It must be called inside WithTransaction.
+func (t *Transactor) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok {
+		return false, errors.New("advisory lock requested outside of a transaction")
+	}
+
+	var locked bool
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", key).Scan(&locked).Error; err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+// conn returns the transaction bound to ctx, or db when there is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
@@ -8,11 +8,12 @@ import (
 	"gorm.io/gorm"
 )
 
-var Env = GetEnvs()
-
 func Init() *gorm.DB {
+	// Read here rather than at package load, so importing the package (e.g.
+	// for ValidateStruct) does not require a .env file
+	env := GetEnvs()
 	var db *gorm.DB
-	dsn := "host=" + Env["DB_HOST"] + " user=" + Env["DB_USER"] + " password=" + Env["DB_PASSWORD"] + " dbname=" + Env["DB_NAME"] + " port=" + Env["DB_PORT"] + " sslmode=disable"
+	dsn := "host=" + env["DB_HOST"] + " user=" + env["DB_USER"] + " password=" + env["DB_PASSWORD"] + " dbname=" + env["DB_NAME"] + " port=" + env["DB_PORT"] + " sslmode=disable"
 	var err error
 	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
@@ -37,6 +38,13 @@ func migration(db *gorm.DB) {
 		&models.Report{},
 		&models.Setting{},
 		&models.Transaction{},
+		&models.IdempotencyKey{},
+		&models.Authorization{},
+		&models.GiftCardStatusChange{},
+		&models.IssuanceJob{},
+		&models.IssuanceJobPin{},
+		&models.ExpirySweepRun{},
+		&models.BalanceInquiry{},
 	)
 	if err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
@@ -1,25 +1,116 @@
 package generators
 
 import (
-	"math/rand"
+	"crypto/rand"
+	"errors"
+	"math/big"
 	"strconv" // Import strconv
+	"strings"
 )
 
-func GenerateGiftcardNumber(length int, prefix string) string {
+const (
+	// DefaultPrefix is used for cards without a configured prefix.
+	DefaultPrefix = "GC"
+	// NumberLength is the length of a generated number, prefix and check digit included.
+	NumberLength = 16
+
+	minRandomDigits = 6
+	minDigits       = 8
+	maxNumberLength = 50
+)
+
+var ErrInvalidPrefix = errors.New("invalid gift card number prefix")
+
+// GenerateGiftcardNumber builds a number of the given length from prefix,
+// random digits and a Luhn check digit. The prefix is made of optional
+// uppercase letters followed by optional BIN-style digits, e.g. "GC" or "GC6035".
+func GenerateGiftcardNumber(length int, prefix string) (string, error) {
+	if !ValidPrefix(prefix, length) {
+		return "", ErrInvalidPrefix
+	}
+
 	baseLength := length - len(prefix) - 1 // -1 para el dígito de verificación
 	base := make([]byte, baseLength)
 
 	for i := 0; i < baseLength; i++ {
-		base[i] = byte('0' + rand.Intn(10))
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		base[i] = byte('0' + n.Int64())
 	}
 
 	number := prefix + string(base)
 	checkDigit := GenerateCheckDigit(number)
 
-	return number + strconv.Itoa(checkDigit)
+	return number + strconv.Itoa(checkDigit), nil
 }
 
+// ValidPrefix reports whether prefix can be used for numbers of the given
+// length while leaving room for enough random digits.
+func ValidPrefix(prefix string, length int) bool {
+	_, digits := split(prefix)
+	if !isDigits(digits) {
+		return false
+	}
+	return len(prefix)+minRandomDigits+1 <= length && length <= maxNumberLength
+}
+
+// GenerateCheckDigit returns the Luhn check digit of the numeric part of
+// number, that is number without its leading letters.
 func GenerateCheckDigit(number string) int {
+	_, digits := split(number)
+
+	sum := 0
+	// The rightmost digit is doubled, as it sits left of the check digit
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		val := int(digits[i] - '0')
+		if double {
+			val *= 2
+			if val > 9 {
+				val -= 9
+			}
+		}
+		sum += val
+		double = !double
+	}
+	return (10 - (sum % 10)) % 10
+}
+
+// ValidGiftcardNumber reports whether number is well formed: optional
+// uppercase letters followed by digits ending in a valid check digit.
+// Numbers issued before check digits were computed on the numeric part
+// only are still accepted.
+func ValidGiftcardNumber(number string) bool {
+	_, digits := split(number)
+	if len(number) > maxNumberLength || len(digits) < minDigits || !isDigits(digits) {
+		return false
+	}
+
+	payload, checkDigit := number[:len(number)-1], int(number[len(number)-1]-'0')
+	return GenerateCheckDigit(payload) == checkDigit || legacyCheckDigit(payload) == checkDigit
+}
+
+// WellFormedGiftcardNumber reports whether number could be a gift card
+// number of any issuer: between minDigits and maxNumberLength uppercase
+// letters and digits. Unlike ValidGiftcardNumber, it does not require a
+// check digit, as numbers issued elsewhere need not carry one.
+func WellFormedGiftcardNumber(number string) bool {
+	if len(number) < minDigits || len(number) > maxNumberLength {
+		return false
+	}
+	for _, r := range number {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// legacyCheckDigit is the check digit of numbers issued before the Luhn fix,
+// which also summed the letters of the prefix.
+func legacyCheckDigit(number string) int {
 	sum := 0
 	for i, digit := range number {
 		val := int(digit - '0')
@@ -31,3 +122,21 @@ func GenerateCheckDigit(number string) int {
 	}
 	return (10 - (sum % 10)) % 10
 }
+
+// split separates the leading letters of s from the rest.
+func split(s string) (letters string, rest string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
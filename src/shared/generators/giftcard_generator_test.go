@@ -0,0 +1,94 @@
+package generators
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCheckDigit(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected int
+	}{
+		{number: "7992739871", expected: 3},
+		{number: "GC7992739871", expected: 3}, // letters are not part of the checksum
+		{number: "453201511283036", expected: 6},
+		{number: "0", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GenerateCheckDigit(tt.number))
+		})
+	}
+}
+
+func TestGenerateGiftcardNumber(t *testing.T) {
+	for _, prefix := range []string{"GC", "GC6035", "6035", ""} {
+		t.Run(prefix, func(t *testing.T) {
+			number, err := GenerateGiftcardNumber(NumberLength, prefix)
+			require.NoError(t, err)
+			assert.Len(t, number, NumberLength)
+			assert.True(t, strings.HasPrefix(number, prefix))
+			assert.True(t, ValidGiftcardNumber(number), number)
+		})
+	}
+
+	_, err := GenerateGiftcardNumber(NumberLength, "gc")
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+	_, err = GenerateGiftcardNumber(NumberLength, "GC12345678901")
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+}
+
+func TestValidGiftcardNumber(t *testing.T) {
+	legacy := "GC123456789012"
+	legacy += strconv.Itoa(legacyCheckDigit(legacy))
+
+	tests := []struct {
+		number   string
+		expected bool
+	}{
+		{number: "GC79927398713", expected: true},
+		{number: "79927398713", expected: true},
+		{number: "GC79927398710", expected: false},
+		{number: legacy, expected: true},
+		{number: "GC7992-398713", expected: false},
+		{number: "gc79927398713", expected: false},
+		{number: "GC1234", expected: false},
+		{number: "GCUNKNOWN", expected: false},
+		{number: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidGiftcardNumber(tt.number))
+		})
+	}
+}
+
+func TestWellFormedGiftcardNumber(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected bool
+	}{
+		{number: "GC79927398713", expected: true},
+		{number: "GC79927398710", expected: true},
+		{number: "AB12CD34EF56", expected: true},
+		{number: "12345678", expected: true},
+		{number: "GC7992-398713", expected: false},
+		{number: "gc79927398713", expected: false},
+		{number: "GC1234", expected: false},
+		{number: strings.Repeat("9", 51), expected: false},
+		{number: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WellFormedGiftcardNumber(tt.number))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places kept for every amount. Only
+// currencies whose minor unit is a hundredth can be held, see
+// SupportsCurrency.
+const Scale = 2
+
+const unit = 100 // 10^Scale
+
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// otherScaleCurrencies are the ISO-4217 codes whose minor unit is not a
+// hundredth: currencies with no decimals such as JPY and CLP, with three such
+// as KWD and BHD, with four, and the metals and funds without a minor unit.
+var otherScaleCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true,
+	"KMF": true, "KRW": true, "PYG": true, "RWF": true, "UGX": true, "UYI": true,
+	"VND": true, "VUV": true, "XAF": true, "XOF": true, "XPF": true,
+	"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+	"CLF": true, "UYW": true,
+	"XAG": true, "XAU": true, "XBA": true, "XBB": true, "XBC": true, "XBD": true,
+	"XDR": true, "XPD": true, "XPT": true, "XSU": true, "XTS": true, "XUA": true, "XXX": true,
+}
+
+// SupportsCurrency reports whether amounts in the ISO-4217 currency code are
+// held exactly by Amount, that is whether the currency has two decimals.
+func SupportsCurrency(code string) bool {
+	return !otherScaleCurrencies[code]
+}
+
+// Amount is an exact money amount stored as an integer number of minor units
+// (hundredths). It is serialized to JSON as a decimal string such as "12.50"
+// and stored in decimal(10,2) columns.
+type Amount int64
+
+// FromMinorUnits builds an Amount from a number of hundredths.
+func FromMinorUnits(minor int64) Amount {
+	return Amount(minor)
+}
+
+// Parse reads a decimal string such as "12", "-3.5" or "0.125".
+// Digits beyond Scale are rounded with Round.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || s == "" || strings.ContainsAny(s, "/eE") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return Round(r)
+}
+
+// MustParse is like Parse but panics on invalid input. Intended for constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Round converts an exact rational value to an Amount, rounding half away
+// from zero to Scale decimal places. Every conversion into Amount goes
+// through here so there is a single rounding rule.
+func Round(r *big.Rat) (Amount, error) {
+	scaled := new(big.Rat).Mul(r, big.NewRat(unit, 1))
+	num := new(big.Int).Abs(scaled.Num())
+	quo, rem := new(big.Int).QuoRem(num, scaled.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("%w: %s out of range", ErrInvalidAmount, r.FloatString(Scale))
+	}
+	if scaled.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return Amount(quo.Int64()), nil
+}
+
+// Mul multiplies the amount by an exact factor such as an exchange rate,
+// rounding the result with Round.
+func (a Amount) Mul(factor *big.Rat) (Amount, error) {
+	return Round(new(big.Rat).Mul(a.Rat(), factor))
+}
+
+// MinorUnits returns the amount as a number of hundredths.
+func (a Amount) MinorUnits() int64 {
+	return int64(a)
+}
+
+// Rat returns the exact value of the amount.
+func (a Amount) Rat() *big.Rat {
+	return big.NewRat(int64(a), unit)
+}
+
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/unit, v%unit)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts both a decimal string ("12.50") and a JSON number (12.5).
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan implements sql.Scanner for numeric columns.
+func (a *Amount) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*a = Amount(v * unit)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
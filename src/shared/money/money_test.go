@@ -0,0 +1,95 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Amount
+		expectedErr bool
+	}{
+		{input: "12", expected: 1200},
+		{input: "12.5", expected: 1250},
+		{input: "0.1", expected: 10},
+		{input: "-3.25", expected: -325},
+		{input: "0.125", expected: 13},
+		{input: "-0.125", expected: -13},
+		{input: "0.124", expected: 12},
+		{input: "", expectedErr: true},
+		{input: "abc", expectedErr: true},
+		{input: "1/2", expectedErr: true},
+		{input: "1e3", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.expectedErr {
+				assert.ErrorIs(t, err, ErrInvalidAmount)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestAmount_ExactArithmetic(t *testing.T) {
+	sum := MustParse("0.1") + MustParse("0.2")
+	assert.Equal(t, MustParse("0.3"), sum)
+	assert.True(t, MustParse("0.3")-sum == 0)
+}
+
+func TestAmount_String(t *testing.T) {
+	assert.Equal(t, "0.00", Amount(0).String())
+	assert.Equal(t, "12.05", Amount(1205).String())
+	assert.Equal(t, "-0.05", Amount(-5).String())
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var payload struct {
+		Amount Amount `json:"amount"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":"10.10"}`), &payload))
+	assert.Equal(t, Amount(1010), payload.Amount)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":10.1}`), &payload))
+	assert.Equal(t, Amount(1010), payload.Amount)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"ten"}`), &payload))
+
+	out, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"10.10"}`, string(out))
+}
+
+func TestAmount_Scan(t *testing.T) {
+	var a Amount
+	assert.NoError(t, a.Scan([]byte("99.99")))
+	assert.Equal(t, Amount(9999), a)
+	assert.NoError(t, a.Scan("5.5"))
+	assert.Equal(t, Amount(550), a)
+	assert.NoError(t, a.Scan(int64(3)))
+	assert.Equal(t, Amount(300), a)
+	assert.NoError(t, a.Scan(nil))
+	assert.Equal(t, Amount(0), a)
+	assert.Error(t, a.Scan(true))
+
+	value, err := Amount(-1234).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "-12.34", value)
+}
+
+func TestSupportsCurrency(t *testing.T) {
+	assert.True(t, SupportsCurrency("USD"))
+	assert.True(t, SupportsCurrency("MXN"))
+	assert.False(t, SupportsCurrency("CLP"))
+	assert.False(t, SupportsCurrency("JPY"))
+	assert.False(t, SupportsCurrency("KWD"))
+}
@@ -0,0 +1,47 @@
+package pin
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Length is the number of digits of a gift card PIN.
+const Length = 6
+
+// Generate returns a random numeric PIN of Length digits.
+func Generate() (string, error) {
+	digits := make([]byte, Length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// Hash returns the bcrypt hash stored in place of the PIN.
+func Hash(pin string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether pin matches hash. A malformed hash is an error,
+// a wrong PIN is not.
+func Verify(hash string, pin string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(pin))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,36 @@
+package pin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pin, err := Generate()
+		require.NoError(t, err)
+		assert.Len(t, pin, Length)
+		for _, digit := range pin {
+			assert.True(t, digit >= '0' && digit <= '9', "unexpected character %q in %s", digit, pin)
+		}
+	}
+}
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("123456")
+	require.NoError(t, err)
+	assert.NotContains(t, hash, "123456")
+
+	ok, err := Verify(hash, "123456")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = Verify(hash, "654321")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = Verify("not-a-hash", "123456")
+	assert.Error(t, err)
+}
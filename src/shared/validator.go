@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"GiftWize/src/shared/money"
 	"fmt"
 	"strings"
 
@@ -12,9 +13,19 @@ type ValidationError struct {
 	Error string `json:"error"`
 }
 
+// NewValidator returns a validator that also knows the tags of this API:
+// money_currency accepts the currencies money.Amount can hold.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	_ = validate.RegisterValidation("money_currency", func(fl validator.FieldLevel) bool {
+		return money.SupportsCurrency(fl.Field().String())
+	})
+	return validate
+}
+
 func ValidateStruct(s interface{}) []*ValidationError {
 	var errors []*ValidationError
-	validate := validator.New()
+	validate := NewValidator()
 	err := validate.Struct(s)
 	if err != nil {
 		for _, err := range err.(validator.ValidationErrors) {
@@ -31,6 +42,10 @@ func formatErrorMessage(err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
 		return "This field is required"
+	case "required_if":
+		return fmt.Sprintf("This field is required when %s", strings.Replace(err.Param(), " ", " is ", 1))
+	case "required_unless":
+		return fmt.Sprintf("This field is required unless %s", strings.Replace(err.Param(), " ", " is ", 1))
 	case "min":
 		return fmt.Sprintf("This field must be at least %s characters long", err.Param())
 	case "max":
@@ -41,6 +56,10 @@ func formatErrorMessage(err validator.FieldError) string {
 		return fmt.Sprintf("This field must be greater than %s", err.Param())
 	case "lt":
 		return fmt.Sprintf("This field must be less than %s", err.Param())
+	case "numeric":
+		return "This field must contain only digits"
+	case "money_currency":
+		return "Only currencies with two decimal places are supported"
 		// Add more custom messages for other tags as needed
 	default:
 		return fmt.Sprintf("Validation failed on field '%s' with tag '%s'", err.Field(), err.Tag())